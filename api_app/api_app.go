@@ -4,54 +4,288 @@ package api_app
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"todo-app/auth"
 	"todo-app/httpapi"
+	"todo-app/idempotency"
+	"todo-app/lists"
+	"todo-app/ratelimit"
 	"todo-app/service"
+	"todo-app/webhook"
 )
 
 // Server is now a thin bootstrapper (intentionally small).
 // All HTTP concerns (routing + handlers) live in package httpapi.
 type Server struct {
-	store service.Store
-	mux   *http.ServeMux
+	stores *service.ActorStoreFactory
+	lists  *service.ListStore
+	hooks  *webhook.Dispatcher
+	mux    *http.ServeMux
+
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+	draining        atomic.Bool
+}
+
+// Config holds everything needed to build a Server.
+type Config struct {
+	// OutPath is the default user's JSON data file; other users' files live
+	// under <dir(OutPath)>/users/<id>/.
+	OutPath string
+	// Keys enables API key authentication when non-nil.
+	Keys *auth.Keyring
+	// IdempotencyTTL is how long Idempotency-Key responses are remembered
+	// (idempotency.DefaultTTL when zero).
+	IdempotencyTTL time.Duration
+	// DrainDelay is how long /readyz reports "draining" before the server
+	// stops accepting connections, so load balancers can move traffic away.
+	DrainDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may take to finish
+	// once shutdown starts (DefaultShutdownTimeout when zero).
+	ShutdownTimeout time.Duration
+	// WebhookAllowPrivate lets webhooks target loopback, private and
+	// link-local addresses (webhook.Config.AllowPrivate).
+	WebhookAllowPrivate bool
+	// Limits holds the rate limits and body size cap; the zero value disables
+	// rate limiting (FromEnv applies DefaultRateLimitIP/DefaultRateLimitKey).
+	Limits httpapi.Limits
 }
 
+// Default rate limits used by FromEnv, as "requests per second:burst".
+const (
+	DefaultRateLimitIP  = "50:100"
+	DefaultRateLimitKey = "20:40"
+)
+
+// DefaultShutdownTimeout is used when Config.ShutdownTimeout is zero.
+const DefaultShutdownTimeout = 10 * time.Second
+
 // New constructs a server using a JSON file at outPath.
 func New(outPath string) *Server {
-	st := service.NewActorStore(outPath)
-	mux := http.NewServeMux()
-	httpapi.Register(mux, st)
-	return &Server{store: st, mux: mux}
+	return NewWithConfig(Config{OutPath: outPath})
+}
+
+// NewWithConfig constructs a server from cfg.
+func NewWithConfig(cfg Config) *Server {
+	stores := service.NewActorStoreFactory(cfg.OutPath)
+	// Shared lists and their audit trail sit next to the data file.
+	dir := filepath.Dir(cfg.OutPath)
+	ls := service.NewListStore(filepath.Join(dir, "lists.json"))
+	audit := lists.NewAuditLog(filepath.Join(dir, "audit.log"))
+	hooks := webhook.NewDispatcher(webhook.Config{
+		HooksPath:    filepath.Join(dir, "webhooks.json"),
+		QueuePath:    filepath.Join(dir, "webhook_queue.json"),
+		AllowPrivate: cfg.WebhookAllowPrivate,
+	})
+
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	s := &Server{
+		stores: stores, lists: ls, hooks: hooks, mux: http.NewServeMux(),
+		drainDelay: cfg.DrainDelay, shutdownTimeout: cfg.ShutdownTimeout,
+	}
+	httpapi.RegisterWith(s.mux, stores, httpapi.Options{
+		Keys: cfg.Keys, Lists: ls, Audit: audit, Webhooks: hooks,
+		Idempotency: idempotency.New(cfg.IdempotencyTTL),
+		Limits:      cfg.Limits,
+		Readiness: []httpapi.ReadinessCheck{
+			{Name: "draining", Check: s.checkNotDraining},
+			{Name: "store", Check: stores.Ping},
+			{Name: "data_file", Check: stores.CheckWritable},
+		},
+	})
+	return s
 }
 
 // Handler returns the fully wired HTTP handler.
 func (s *Server) Handler() http.Handler { return s.mux }
 
-// Run starts the HTTP server at addr and shuts down on ctx.Done().
+// Run listens on addr and serves until ctx is done, then shuts down (see Serve).
 func (s *Server) Run(ctx context.Context, addr string) error {
-	srv := &http.Server{Addr: addr, Handler: s.mux}
-	go func() {
-		<-ctx.Done()
-		slog.Info("shutting down server")
-		_ = srv.Shutdown(context.Background())
-	}()
-	slog.Info("listening", "addr", addr)
-	return srv.ListenAndServe()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Join(err, s.closeStores())
+	}
+	slog.Info("listening", "addr", ln.Addr().String())
+	return s.Serve(ctx, ln)
+}
+
+// Serve handles connections on ln until ctx is done, then drains:
+//  1. /readyz starts failing and, after DrainDelay, the listener closes;
+//  2. event streams end and in-flight handlers get up to ShutdownTimeout
+//     to finish (stragglers are then cut off);
+//  3. webhook delivery stops and the stores are closed, which confirms the
+//     last Save reached disk.
+//
+// It returns nil only when every step completed cleanly.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Handler: s.mux}
+	hooksCtx, stopHooks := context.WithCancel(context.Background())
+	s.hooks.Start(hooksCtx, s.stores)
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	var serveErr error
+	select {
+	case err := <-served:
+		serveErr = err // listener failed before shutdown was requested
+	case <-ctx.Done():
+		serveErr = s.drain(srv)
+	}
+
+	stopHooks()
+	s.hooks.Wait()
+	storeErr := s.closeStores()
+	slog.Info("shutdown complete", "flushed", storeErr == nil)
+	return errors.Join(serveErr, storeErr)
+}
+
+// drain stops srv, waiting up to the shutdown timeout for in-flight requests.
+func (s *Server) drain(srv *http.Server) error {
+	s.draining.Store(true)
+	slog.Info("shutting down server", "drain_delay", s.drainDelay, "timeout", s.shutdownTimeout)
+	time.Sleep(s.drainDelay) // /readyz now fails; give load balancers time to notice
+
+	// End event streams first; Shutdown waits for every handler to return.
+	s.stores.CloseSubscribers()
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Warn("in-flight requests did not finish in time; closing connections", "error", err)
+		_ = srv.Close()
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	return nil
+}
+
+// closeStores closes the data stores, returning any final flush error.
+func (s *Server) closeStores() error {
+	return errors.Join(s.stores.Close(), s.lists.Close())
+}
+
+// checkNotDraining fails once shutdown has begun.
+func (s *Server) checkNotDraining(ctx context.Context) error {
+	if s.draining.Load() {
+		return errors.New("server is shutting down")
+	}
+	return nil
 }
 
 // FromEnv constructs a Server and derives the address from PORT, like Heroku.
-func FromEnv() (*Server, string) {
+// Every route needs an API key from the keys file (TODO_KEYS, default
+// out/keys.json; create it with `todo keys create`), and without one FromEnv
+// fails rather than serve anyone who can reach the port. TODO_AUTH=off opts
+// in to dev mode: no keys, the user picked by the X-User-ID header.
+func FromEnv() (*Server, string, error) {
 	addr := ":8080"
 	if v := os.Getenv("PORT"); strings.TrimSpace(v) != "" {
 		addr = ":" + strings.TrimPrefix(v, ":")
 	}
-	outPath := "out/todos.json"
+	cfg := Config{OutPath: "out/todos.json"}
 	if v := os.Getenv("TODO_OUT"); strings.TrimSpace(v) != "" {
-		outPath = v
+		cfg.OutPath = v
+	}
+
+	if v := os.Getenv("TODO_IDEMPOTENCY_TTL"); strings.TrimSpace(v) != "" {
+		ttl, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || ttl <= 0 {
+			return nil, "", fmt.Errorf("TODO_IDEMPOTENCY_TTL: want a positive duration like 24h, got %q", v)
+		}
+		cfg.IdempotencyTTL = ttl
+	}
+
+	if v := os.Getenv("TODO_DRAIN_DELAY"); strings.TrimSpace(v) != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d < 0 {
+			return nil, "", fmt.Errorf("TODO_DRAIN_DELAY: want a duration like 5s, got %q", v)
+		}
+		cfg.DrainDelay = d
+	}
+
+	if v := os.Getenv("TODO_SHUTDOWN_TIMEOUT"); strings.TrimSpace(v) != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || d <= 0 {
+			return nil, "", fmt.Errorf("TODO_SHUTDOWN_TIMEOUT: want a positive duration like 10s, got %q", v)
+		}
+		cfg.ShutdownTimeout = d
+	}
+
+	for _, rl := range []struct {
+		env, def string
+		dst      **ratelimit.Limiter
+	}{
+		{"TODO_RATE_LIMIT_IP", DefaultRateLimitIP, &cfg.Limits.PerIP},
+		{"TODO_RATE_LIMIT_KEY", DefaultRateLimitKey, &cfg.Limits.PerKey},
+	} {
+		spec := rl.def
+		if v := os.Getenv(rl.env); strings.TrimSpace(v) != "" {
+			spec = v
+		}
+		l, err := ratelimit.Parse(spec)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", rl.env, err)
+		}
+		*rl.dst = l
+	}
+
+	if v := os.Getenv("TODO_MAX_BODY_BYTES"); strings.TrimSpace(v) != "" {
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil || n <= 0 {
+			return nil, "", fmt.Errorf("TODO_MAX_BODY_BYTES: want a positive byte count, got %q", v)
+		}
+		cfg.Limits.MaxBodyBytes = n
+	}
+
+	if v := os.Getenv("TODO_MAX_DESCRIPTION"); strings.TrimSpace(v) != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || n <= 0 {
+			return nil, "", fmt.Errorf("TODO_MAX_DESCRIPTION: want a positive character count, got %q", v)
+		}
+		cfg.Limits.MaxDescription = n
+	}
+
+	if v := os.Getenv("TODO_WEBHOOK_ALLOW_PRIVATE"); strings.TrimSpace(v) != "" {
+		allow, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return nil, "", fmt.Errorf("TODO_WEBHOOK_ALLOW_PRIVATE: want true or false, got %q", v)
+		}
+		cfg.WebhookAllowPrivate = allow
+		if allow {
+			slog.Warn("webhooks may target loopback, private and link-local addresses (TODO_WEBHOOK_ALLOW_PRIVATE)")
+		}
+	}
+
+	keysPath := auth.DefaultPath
+	if v := os.Getenv("TODO_KEYS"); strings.TrimSpace(v) != "" {
+		keysPath = v
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv("TODO_AUTH"))); mode {
+	case "off":
+		slog.Warn("api key authentication disabled by TODO_AUTH=off; anyone who can reach the server can read and write")
+	case "", "keys":
+		if _, err := os.Stat(keysPath); err != nil {
+			return nil, "", fmt.Errorf("no API keys file at %s (%w); create a key with `todo keys create`, or set TODO_AUTH=off for an unauthenticated dev server", keysPath, err)
+		}
+		keys, err := auth.Open(keysPath)
+		if err != nil {
+			return nil, "", err
+		}
+		cfg.Keys = keys
+		slog.Info("api key authentication enabled", "path", keysPath)
+	default:
+		return nil, "", fmt.Errorf("TODO_AUTH: want keys or off, got %q", mode)
 	}
-	return New(outPath), addr
+	return NewWithConfig(cfg), addr, nil
 }
@@ -42,7 +42,9 @@ func TestAPI_ParallelSuite(t *testing.T) {
 	// Server writing to ./todos_test.json
 	s := New("todos_test.json")
 	ts := httptest.NewServer(s.Handler())
-	defer ts.Close()
+	// Parallel subtests only start once this function returns, so the server
+	// must outlive it; t.Cleanup runs after they finish.
+	t.Cleanup(ts.Close)
 
 	// Seed one item we will repeatedly read/update but never delete.
 	var seed item
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,6 +13,11 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"todo-app/auth"
+	"todo-app/service"
+	"todo-app/todo"
 )
 
 type item struct {
@@ -19,6 +25,7 @@ type item struct {
 	Description string `json:"description"`
 	Status      string `json:"status"`
 	CreatedAt   string `json:"created_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
 }
 
 // --- test helpers ---
@@ -353,3 +360,190 @@ func TestAPI_AboutServesStatic(t *testing.T) {
 		t.Fatalf("/about content-type = %q, want to contain %q", ct, "text/html")
 	}
 }
+
+// TestAPI_HealthReadyVersion checks the probe endpoints, and that /readyz
+// fails once the server starts draining.
+func TestAPI_HealthReadyVersion(t *testing.T) {
+	tmp := t.TempDir()
+	s := New(filepath.Join(tmp, "todos.json"))
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+
+	if resp := do(t, ts, http.MethodGet, "/healthz", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("/healthz status=%d", resp.StatusCode)
+	}
+
+	var ready struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	resp := do(t, ts, http.MethodGet, "/readyz", nil)
+	decodeJSON(t, resp.Body, &ready)
+	if resp.StatusCode != http.StatusOK || ready.Checks["store"] != "ok" || ready.Checks["data_file"] != "ok" {
+		t.Fatalf("/readyz status=%d body=%+v", resp.StatusCode, ready)
+	}
+
+	var version map[string]any
+	resp = do(t, ts, http.MethodGet, "/version", nil)
+	decodeJSON(t, resp.Body, &version)
+	if resp.StatusCode != http.StatusOK || version["go_version"] == "" || version["go_version"] == nil {
+		t.Fatalf("/version status=%d body=%v", resp.StatusCode, version)
+	}
+
+	s.draining.Store(true)
+	resp = do(t, ts, http.MethodGet, "/readyz", nil)
+	decodeJSON(t, resp.Body, &ready)
+	if resp.StatusCode != http.StatusServiceUnavailable || ready.Checks["draining"] == "ok" {
+		t.Fatalf("draining /readyz status=%d body=%+v", resp.StatusCode, ready)
+	}
+	if resp := do(t, ts, http.MethodGet, "/healthz", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("/healthz should stay ok while draining, status=%d", resp.StatusCode)
+	}
+}
+
+// startServe runs s.Serve on a loopback listener and returns its base URL
+// and a channel with Serve's result.
+func startServe(t *testing.T, ctx context.Context, s *Server) (string, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, ln) }()
+	return "http://" + ln.Addr().String(), done
+}
+
+// TestAPI_Serve_DrainsInFlightAndFlushes cancels the server while a request
+// is still running and checks the request completes, its write is on disk and
+// Serve reports a clean shutdown.
+func TestAPI_Serve_DrainsInFlightAndFlushes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	s := NewWithConfig(Config{OutPath: path, ShutdownTimeout: 5 * time.Second})
+	started, release := make(chan struct{}), make(chan struct{})
+	s.mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		store, _ := s.stores.For(r.Context(), service.DefaultUser)
+		list, _, _ := todo.Add(nil, "written during drain", todo.StatusNotStarted)
+		if err := store.Save(r.Context(), list); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	url, done := startServe(t, ctx, s)
+	resc := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			resc <- 0
+			return
+		}
+		resp.Body.Close()
+		resc <- resp.StatusCode
+	}()
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		t.Fatalf("Serve returned (%v) before the in-flight request finished", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	if code := <-resc; code != http.StatusCreated {
+		t.Fatalf("in-flight request status=%d", code)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	got, err := todo.Load(context.Background(), path)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("data after shutdown: %v, err=%v", got, err)
+	}
+}
+
+// TestAPI_Serve_ShutdownTimeout checks that a handler outliving the
+// shutdown deadline is cut off and reported instead of hanging shutdown.
+func TestAPI_Serve_ShutdownTimeout(t *testing.T) {
+	s := NewWithConfig(Config{OutPath: filepath.Join(t.TempDir(), "todos.json"), ShutdownTimeout: 100 * time.Millisecond})
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	s.mux.HandleFunc("/stuck", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	url, done := startServe(t, ctx, s)
+	go func() {
+		if resp, err := http.Get(url + "/stuck"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "deadline") {
+			t.Fatalf("Serve err = %v, want a shutdown deadline error", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("shutdown did not honour its deadline")
+	}
+}
+
+// TestAPI_FromEnv_RequiresKeys checks the server fails closed: no keys file
+// is an error unless TODO_AUTH=off asks for dev mode, and with keys an
+// unauthenticated write is refused.
+func TestAPI_FromEnv_RequiresKeys(t *testing.T) {
+	dir := t.TempDir()
+	keysPath := filepath.Join(dir, "keys.json")
+	t.Setenv("TODO_OUT", filepath.Join(dir, "todos.json"))
+	t.Setenv("TODO_KEYS", keysPath)
+	add := func(s *Server) int {
+		t.Helper()
+		w := httptest.NewRecorder()
+		s.mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(`{"description":"x"}`)))
+		return w.Code
+	}
+
+	t.Setenv("TODO_AUTH", "")
+	if _, _, err := FromEnv(); err == nil || !strings.Contains(err.Error(), "TODO_AUTH=off") {
+		t.Fatalf("no keys file: err = %v, want a refusal naming TODO_AUTH=off", err)
+	}
+	t.Setenv("TODO_AUTH", "maybe")
+	if _, _, err := FromEnv(); err == nil {
+		t.Fatal("TODO_AUTH=maybe accepted")
+	}
+
+	t.Setenv("TODO_AUTH", "off")
+	s, _, err := FromEnv()
+	if err != nil {
+		t.Fatalf("TODO_AUTH=off: %v", err)
+	}
+	if code := add(s); code == http.StatusUnauthorized {
+		t.Fatalf("dev mode refused a write: %d", code)
+	}
+	_ = s.closeStores()
+
+	keys, err := auth.Open(keysPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := keys.Create("laptop", "alice", auth.ScopeWrite); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TODO_AUTH", "")
+	s, _, err = FromEnv()
+	if err != nil {
+		t.Fatalf("with keys: %v", err)
+	}
+	defer s.closeStores()
+	if code := add(s); code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated write with keys configured: %d, want 401", code)
+	}
+}
@@ -0,0 +1,342 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//
+// auth/auth.go (package auth)
+// ---------------------------
+// API keys for the HTTP server. Keys live in a local JSON file; only a salted
+// SHA-256 hash of each secret is stored. A token handed to clients looks like
+// "todo_<id>.<secret>" so the key can be found without scanning every hash.
+//
+
+// Scope limits what a key may do.
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write" // implies read
+)
+
+// Validate ensures the scope is one of the allowed values.
+func (s Scope) Validate() error {
+	switch s {
+	case ScopeRead, ScopeWrite:
+		return nil
+	default:
+		return fmt.Errorf("invalid scope: %q (allowed: %q, %q)", s, ScopeRead, ScopeWrite)
+	}
+}
+
+// DefaultPath is where the API server and `todo keys` look for API keys.
+const DefaultPath = "out/keys.json"
+
+// tokenPrefix marks strings that look like our API tokens.
+const tokenPrefix = "todo_"
+
+var (
+	// ErrInvalidToken is returned for malformed, unknown or mismatched tokens.
+	ErrInvalidToken = errors.New("invalid API key")
+	// ErrRevoked is returned when the key exists but has been revoked.
+	ErrRevoked = errors.New("API key revoked")
+	// ErrNotFound is returned by Revoke for an unknown key id.
+	ErrNotFound = errors.New("no API key with that id")
+)
+
+// Key is a stored API key. The secret itself is never persisted.
+type Key struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	User      string     `json:"user"`
+	Scope     Scope      `json:"scope"`
+	Salt      string     `json:"salt"`
+	Hash      string     `json:"hash"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Allows reports whether the key grants the requested scope.
+func (k Key) Allows(want Scope) bool {
+	if k.Scope == ScopeWrite {
+		return true
+	}
+	return k.Scope == want
+}
+
+// Revoked reports whether the key has been revoked.
+func (k Key) Revoked() bool { return k.RevokedAt != nil }
+
+// Keyring is a file-backed set of API keys, safe for concurrent use.
+// It reloads the file when its modification time changes so keys created or
+// revoked by the admin CLI take effect in a running server.
+type Keyring struct {
+	path string
+
+	mu      sync.Mutex
+	keys    map[string]Key
+	modTime time.Time
+	size    int64
+}
+
+// Open loads the keyring stored at path. A missing file is an empty keyring.
+func Open(path string) (*Keyring, error) {
+	k := &Keyring{path: path, keys: map[string]Key{}}
+	if err := k.reload(true); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Path returns the backing file path.
+func (k *Keyring) Path() string { return k.path }
+
+// reload re-reads the file if it changed since the last read (or always when force).
+// Callers must hold k.mu, except during Open.
+func (k *Keyring) reload(force bool) error {
+	st, err := os.Stat(k.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		k.keys = map[string]Key{}
+		k.modTime, k.size = time.Time{}, 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !force && st.ModTime().Equal(k.modTime) && st.Size() == k.size {
+		return nil
+	}
+	b, err := os.ReadFile(k.path)
+	if err != nil {
+		return err
+	}
+	var list []Key
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &list); err != nil {
+			return fmt.Errorf("parse %s: %w", k.path, err)
+		}
+	}
+	keys := make(map[string]Key, len(list))
+	for _, key := range list {
+		keys[key.ID] = key
+	}
+	k.keys = keys
+	k.modTime, k.size = st.ModTime(), st.Size()
+	return nil
+}
+
+// save writes the keyring back to disk with owner-only permissions.
+// Callers must hold k.mu.
+func (k *Keyring) save() error {
+	if err := os.MkdirAll(filepath.Dir(k.path), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(k.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(k.path, b, 0o600); err != nil {
+		return err
+	}
+	if st, err := os.Stat(k.path); err == nil {
+		k.modTime, k.size = st.ModTime(), st.Size()
+	}
+	return nil
+}
+
+// sorted returns the keys ordered by creation time. Callers must hold k.mu.
+func (k *Keyring) sorted() []Key {
+	list := make([]Key, 0, len(k.keys))
+	for _, key := range k.keys {
+		list = append(list, key)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Create generates a new key acting as user and persists it. The returned
+// token is the only time the secret is available; it cannot be recovered later.
+func (k *Keyring) Create(name, user string, scope Scope) (string, Key, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", Key{}, errors.New("key name cannot be empty")
+	}
+	user = strings.TrimSpace(user)
+	if user == "" {
+		return "", Key{}, errors.New("key user cannot be empty")
+	}
+	if err := scope.Validate(); err != nil {
+		return "", Key{}, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.reload(false); err != nil {
+		return "", Key{}, err
+	}
+
+	id := randomHex(6)
+	for _, exists := k.keys[id]; exists; _, exists = k.keys[id] {
+		id = randomHex(6)
+	}
+	secret := randomHex(24)
+	salt := randomHex(16)
+	key := Key{
+		ID:        id,
+		Name:      name,
+		User:      user,
+		Scope:     scope,
+		Salt:      salt,
+		Hash:      hashSecret(salt, secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	k.keys[id] = key
+	if err := k.save(); err != nil {
+		delete(k.keys, id)
+		return "", Key{}, err
+	}
+	return tokenPrefix + id + "." + secret, key, nil
+}
+
+// List returns all keys (including revoked ones) ordered by creation time.
+func (k *Keyring) List() ([]Key, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.reload(false); err != nil {
+		return nil, err
+	}
+	return k.sorted(), nil
+}
+
+// Revoke marks the key as revoked. Revoking twice is not an error.
+func (k *Keyring) Revoke(id string) (Key, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.reload(false); err != nil {
+		return Key{}, err
+	}
+	key, ok := k.keys[id]
+	if !ok {
+		return Key{}, ErrNotFound
+	}
+	if key.RevokedAt == nil {
+		now := time.Now().UTC()
+		key.RevokedAt = &now
+		k.keys[id] = key
+		if err := k.save(); err != nil {
+			return Key{}, err
+		}
+	}
+	return key, nil
+}
+
+// Authenticate resolves a bearer token to its key.
+func (k *Keyring) Authenticate(token string) (Key, error) {
+	id, secret, ok := parseToken(token)
+	if !ok {
+		return Key{}, ErrInvalidToken
+	}
+
+	k.mu.Lock()
+	if err := k.reload(false); err != nil {
+		// Keep serving with the last good copy; a half-written file should
+		// not lock every client out.
+		slog.Warn("auth: keyring reload failed", "error", err, "path", k.path)
+	}
+	key, found := k.keys[id]
+	k.mu.Unlock()
+
+	if !found {
+		return Key{}, ErrInvalidToken
+	}
+	want := []byte(key.Hash)
+	got := []byte(hashSecret(key.Salt, secret))
+	if subtle.ConstantTimeCompare(want, got) != 1 {
+		return Key{}, ErrInvalidToken
+	}
+	if key.Revoked() {
+		return Key{}, ErrRevoked
+	}
+	return key, nil
+}
+
+// parseToken splits "todo_<id>.<secret>".
+func parseToken(token string) (id, secret string, ok bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(token), tokenPrefix)
+	if !found {
+		return "", "", false
+	}
+	id, secret, found = strings.Cut(rest, ".")
+	if !found || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// hashSecret returns hex(sha256(salt || secret)).
+func hashSecret(salt, secret string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes hex-encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// --- context helpers ---
+
+// keyCtxType is an unexported type to prevent collisions in context values.
+type keyCtxType struct{}
+
+var keyCtx keyCtxType
+
+// NewContext returns a copy of ctx carrying the authenticated key.
+func NewContext(ctx context.Context, k Key) context.Context {
+	return context.WithValue(ctx, keyCtx, k)
+}
+
+// FromContext returns the authenticated key stored in ctx, if any.
+func FromContext(ctx context.Context) (Key, bool) {
+	k, ok := ctx.Value(keyCtx).(Key)
+	return k, ok
+}
+
+// userCtxType is an unexported type to prevent collisions in context values.
+type userCtxType struct{}
+
+var userCtx userCtxType
+
+// WithUser returns a copy of ctx identifying the calling user.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userCtx, user)
+}
+
+// UserFrom returns the calling user stored in ctx, if any.
+func UserFrom(ctx context.Context) (string, bool) {
+	u, ok := ctx.Value(userCtx).(string)
+	return u, ok && u != ""
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAuth_CreateAuthenticateRevoke covers the full key lifecycle.
+func TestAuth_CreateAuthenticateRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	kr, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	token, key, err := kr.Create("ci", "alice", ScopeWrite)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(token, "todo_"+key.ID+".") {
+		t.Fatalf("token %q does not embed id %q", token, key.ID)
+	}
+
+	got, err := kr.Authenticate(token)
+	if err != nil || got.ID != key.ID || got.User != "alice" {
+		t.Fatalf("Authenticate = %+v, %v", got, err)
+	}
+
+	if _, err := kr.Revoke(key.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := kr.Authenticate(token); !errors.Is(err, ErrRevoked) {
+		t.Fatalf("Authenticate after revoke err=%v want ErrRevoked", err)
+	}
+	if _, err := kr.Revoke("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Revoke(missing) err=%v want ErrNotFound", err)
+	}
+}
+
+// TestAuth_SecretNeverStored ensures only the hash reaches disk.
+func TestAuth_SecretNeverStored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	kr, _ := Open(path)
+	token, _, err := kr.Create("laptop", "alice", ScopeRead)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	_, secret, _ := parseToken(token)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(b), secret) {
+		t.Fatalf("keys file contains the raw secret")
+	}
+}
+
+// TestAuth_RejectsBadTokens checks malformed and wrong-secret tokens.
+func TestAuth_RejectsBadTokens(t *testing.T) {
+	kr, _ := Open(filepath.Join(t.TempDir(), "keys.json"))
+	token, key, _ := kr.Create("x", "alice", ScopeRead)
+
+	for _, bad := range []string{"", "nope", "todo_", "todo_" + key.ID, "todo_" + key.ID + ".wrong", token + "x"} {
+		if _, err := kr.Authenticate(bad); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Authenticate(%q) err=%v want ErrInvalidToken", bad, err)
+		}
+	}
+}
+
+// TestAuth_ReloadsWhenFileChanges verifies that a second Keyring (as used by
+// the admin CLI) can create keys that the first one (the server) accepts.
+func TestAuth_ReloadsWhenFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	server, _ := Open(path)
+	admin, _ := Open(path)
+
+	token, _, err := admin.Create("late", "alice", ScopeRead)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := server.Authenticate(token); err != nil {
+		t.Fatalf("server did not pick up new key: %v", err)
+	}
+}
+
+// TestAuth_ScopeAllows checks that write implies read but not vice versa.
+func TestAuth_ScopeAllows(t *testing.T) {
+	r := Key{Scope: ScopeRead}
+	w := Key{Scope: ScopeWrite}
+	if !r.Allows(ScopeRead) || r.Allows(ScopeWrite) {
+		t.Fatalf("read key scopes wrong")
+	}
+	if !w.Allows(ScopeRead) || !w.Allows(ScopeWrite) {
+		t.Fatalf("write key scopes wrong")
+	}
+	if err := Scope("admin").Validate(); err == nil {
+		t.Fatalf("Validate(admin) should fail")
+	}
+}
@@ -0,0 +1,171 @@
+package cli_app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"todo-app/client"
+	"todo-app/todo"
+)
+
+//
+// cli_app/batch.go (package cli_app)
+// ----------------------------------
+// Bulk mode: `todo batch [-atomic] [-in <project>]` reads operations from stdin,
+// either as a JSON array or one JSON object per line, e.g.
+//   {"op":"create","description":"Buy milk"}
+//   {"op":"update","id":3,"status":"completed"}
+//   {"op":"delete","id":4}
+// and applies them with a single write of the data file.
+//
+
+// stdin is where batch reads operations from (swapped in tests).
+var stdin io.Reader = os.Stdin
+
+// batchUsage prints help for the batch subcommand.
+func batchUsage() {
+	fmt.Fprintf(os.Stderr, `Apply many operations at once, read from stdin.
+
+Usage:
+  go run ./cmd/cli batch [-atomic] [-in <project>] [-out out/todos.json] < ops.ndjson
+
+Input is a JSON array of operations or one operation per line:
+  {"op":"create","description":"Buy milk","status":"started","list":"home"}
+  {"op":"update","id":3,"description":"Buy oat milk","status":"completed"}
+  {"op":"delete","id":4}
+
+Create ops without a "list" go to the current project (or -in). With -atomic
+nothing is written unless every operation succeeds.
+`)
+}
+
+// readOps decodes operations from r: JSON arrays and single objects may be mixed.
+func readOps(r io.Reader) ([]todo.Op, error) {
+	dec := json.NewDecoder(r)
+	var ops []todo.Op
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ops, nil
+			}
+			return nil, fmt.Errorf("reading operations: %w", err)
+		}
+		if t := strings.TrimSpace(string(raw)); strings.HasPrefix(t, "[") {
+			var many []todo.Op
+			if err := json.Unmarshal(raw, &many); err != nil {
+				return nil, fmt.Errorf("reading operations: %w", err)
+			}
+			ops = append(ops, many...)
+			continue
+		}
+		var op todo.Op
+		if err := json.Unmarshal(raw, &op); err != nil {
+			return nil, fmt.Errorf("reading operations: %w", err)
+		}
+		ops = append(ops, op)
+	}
+}
+
+// runBatch applies operations from stdin to the data file, or sends them to
+// the server when remote is non-nil.
+func (a *CLI_App) runBatch(ctx context.Context, remote *client.Client, args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = batchUsage
+	out := fs.String("out", "out/todos.json", "path to the JSON file to read/write (forced under ./out)")
+	in := fs.String("in", "", "project for create operations that do not name one")
+	atomic := fs.Bool("atomic", false, "apply all operations or none")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	ops, err := readOps(stdin)
+	if err != nil {
+		return err
+	}
+	outPath := normalizeOutPath(*out)
+	if remote != nil {
+		target, err := remoteTarget(ctx, outPath, strings.TrimSpace(*in))
+		if err != nil {
+			return err
+		}
+		return runRemoteBatch(ctx, remote, ops, target, *atomic)
+	}
+	list, err := todo.Load(ctx, outPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load todos", "error", err, "path", outPath)
+		return err
+	}
+	projects, err := todo.LoadProjects(ctx, todo.ProjectsPath(outPath))
+	if err != nil {
+		return err
+	}
+	target := projects.Current
+	if strings.TrimSpace(*in) != "" {
+		target = strings.TrimSpace(*in)
+	}
+	for i := range ops {
+		if ops[i].Op != todo.OpCreate {
+			continue
+		}
+		if ops[i].List == "" {
+			ops[i].List = target
+		}
+		if err := projects.CheckWritable(ops[i].List); err != nil {
+			return fmt.Errorf("op %d: %w", i, err)
+		}
+	}
+
+	next, results, batchErr := todo.ApplyBatch(list, ops, *atomic)
+	if results == nil {
+		return batchErr
+	}
+	printBatchResults(results)
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if batchErr != nil {
+		slog.ErrorContext(ctx, "atomic batch failed; nothing written", "error", batchErr)
+		return batchErr
+	}
+	if err := todo.Save(ctx, next, outPath); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "batch applied", "ops", len(ops), "failed", failed, "path", outPath)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d operations failed", failed, len(ops))
+	}
+	return nil
+}
+
+// printBatchResults prints one row per operation.
+func printBatchResults(results []todo.OpResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tOP\tID\tRESULT")
+	for _, r := range results {
+		id, res := "-", "ok"
+		if r.Item != nil {
+			id = fmt.Sprint(r.Item.ID)
+		}
+		if r.Err != nil {
+			res = r.Err.Error()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", r.Index, r.Op, id, res)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,64 @@
+package cli_app
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"todo-app/todo"
+)
+
+// TestCLI_Batch_FromStdin feeds NDJSON and array input to `batch` and checks
+// the resulting file, including that -atomic writes nothing on failure.
+func TestCLI_Batch_FromStdin(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	origStdin := stdin
+	t.Cleanup(func() { stdin = origStdin })
+
+	app := New()
+	ctx := context.Background()
+	run := func(input string, args ...string) (string, error) {
+		t.Helper()
+		stdin = strings.NewReader(input)
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, append([]string{"batch"}, args...))
+		return getOutput(), err
+	}
+
+	out, err := run(`{"op":"create","description":"Buy milk"}
+{"op":"create","description":"Walk dog","status":"started"}
+[{"op":"create","description":"Pay rent"},{"op":"update","id":1,"status":"completed"}]`)
+	if err != nil {
+		t.Fatalf("batch: %v\n%s", err, out)
+	}
+	list := readTodos(t, "todos.json")
+	if len(list) != 3 || list[0].Status != todo.StatusCompleted || list[1].Status != todo.StatusStarted {
+		t.Fatalf("after batch: %+v", list)
+	}
+
+	if _, err := run(`{"op":"delete","id":2}
+{"op":"delete","id":99}`, "-atomic"); err == nil {
+		t.Fatalf("atomic batch with a missing id should fail")
+	}
+	if n := len(readTodos(t, "todos.json")); n != 3 {
+		t.Fatalf("atomic failure wrote changes: %d items", n)
+	}
+
+	out, err = run(`{"op":"delete","id":2}
+{"op":"delete","id":99}`)
+	if err == nil || !strings.Contains(out, "no to-do with id 99") {
+		t.Fatalf("partial batch: err=%v out=\n%s", err, out)
+	}
+	if n := len(readTodos(t, "todos.json")); n != 2 {
+		t.Fatalf("partial batch: %d items, want 2", n)
+	}
+}
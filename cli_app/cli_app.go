@@ -2,15 +2,11 @@ package cli_app
 
 import (
 	"context"
-	"errors"
-	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
-	"text/tabwriter"
-	"time"
 
 	// Domain / persistence package
 	"todo-app/todo"
@@ -22,7 +18,8 @@ import (
 // This package owns user-facing command/flag handling. It DOES NOT do direct
 // business logic or I/O; instead it coordinates with the `todo` package.
 // Key behaviors:
-//  - Accepts flags (-list, -add, -status, -update, -newdesc, -delete, -out).
+//  - Git-style commands (add, list, edit, status, rm, import, export, ...);
+//    see commands.go for parsing and the deprecated flat flags.
 //  - Forces all file I/O to live under ./out by normalizing -out.
 //  - Uses context-aware logging and returns errors up to main().
 //
@@ -35,46 +32,53 @@ func New() *CLI_App { return &CLI_App{} }
 
 // usage prints human-readable help and includes documentation for global flags.
 func usage() {
-	fmt.Fprintf(os.Stderr, `Todo-App
+	fmt.Fprintf(stderr, `Todo-App
 
-Manage to-do items: list, add, update descriptions, or delete by ID.
+Manage to-do items: list, add, edit, change status, delete, import, export
+and sync with a Markdown checklist.
 
 Usage:
-  go run . -list [-out out/todos.json]
-  go run . -add "<description>" [-status <not started|started|completed>] [-out out/todos.json]
-  go run . -update <id> -newdesc "<new description>" [-out out/todos.json]
-  go run . -delete <id> [-out out/todos.json]
+  todo [global flags] <command> [flags] [arguments]
+  (go run ./cmd/cli <command> ... when running from source)
 
-Notes:
-  * All output is written under ./out/.
-    If you pass a different -out value, it will be normalized to ./out/<basename>.
-  * The process exits only on Ctrl+C (SIGINT).
+Commands:
+`)
+	for _, c := range commands {
+		fmt.Fprintf(stderr, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(stderr, `  help     Show help for a command
 
-Global flags (parsed before others in main):
+Global flags (before the command):
   -logtext              Use plain text logs instead of JSON
   -traceid <value>      Provide an external TraceID (overrides auto-generated)
-  --traceid=<value>     Alternate form
-`)
+  -server <url>         Send item commands to the API server instead of ./out
+                        (also TODO_SERVER; TODO_TOKEN and TODO_USER identify you)
+  -out <file>           Data file (default %s); commands accept -out too
+  -wait                 After the command, keep running until Ctrl+C
+  -o <format>           How items are printed: table (default), json, ndjson,
+                        csv, tsv, markdown or template; commands accept -o too
+  -template <text>      Go text/template run for each item (implies -o template)
+
+Run "todo help <command>" or "todo <command> -h" for a command's flags.
+
+Notes:
+  * All output is written under ./out/.
+    If you pass a different -out value, it will be normalized to ./out/<basename>.
+  * The old flag syntax (-list, -add, -update/-newdesc, -delete) still works
+    but is deprecated and prints the equivalent command.
+  * Commands exit when done; watch and sync -watch run until Ctrl+C.
+
+Exit codes:
+  %d ok   %d other error   %d bad command line   %d item not found
+  %d invalid input (description, status, list)   %d file or server error
+  %d sync conflicts left to settle   %d interrupted by Ctrl+C
+`, defaultOut, ExitOK, ExitError, ExitUsage, ExitNotFound, ExitInvalid, ExitIO, ExitConflict, ExitInterrupted)
 }
 
-// printList prints a simple fixed table to stdout.
-// We rely on tabwriter to align columns regardless of content width.
+// printList prints the default table to stdout.
 // NOTE: stdout is for user-facing output; logs go to stderr via slog.
 func printList(list []todo.Item) {
-	// Create a writer that aligns columns based on tab stops.
-	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-
-	// Header line (columns are separated by tabs; tabwriter turns tabs into padding).
-	fmt.Fprintln(w, "ID\tDESCRIPTION\tSTATUS\tCREATED")
-
-	// Body rows
-	for _, t := range list {
-		// Time is formatted as RFC3339 for easy machine readability and consistency.
-		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", t.ID, t.Description, t.Status, t.CreatedAt.Format(time.RFC3339))
-	}
-
-	// Flush to ensure content is rendered even if buffers are not full.
-	_ = w.Flush()
+	_ = writeTable(os.Stdout, list)
 }
 
 // normalizeOutPath ensures the data file path is always under ./out/.
@@ -98,117 +102,76 @@ func normalizeOutPath(p string) string {
 	return filepath.Join("out", filepath.Base(clean))
 }
 
-// Run executes the CLI command flow using the provided context and args.
-// Returns an error for any failure (parsing, I/O, validation), which main() logs.
+// Run parses args and executes the command. Returns an error for any
+// failure (parsing, I/O, validation), which main() logs.
 func (a *CLI_App) Run(ctx context.Context, args []string) error {
-	// Define the CLI flagset
-	fs := flag.NewFlagSet("todo-app", flag.ContinueOnError)
-	fs.SetOutput(os.Stderr)
-
-	listOnly := fs.Bool("list", false, "display current list and exit")
-	desc := fs.String("add", "", "description for the to-do item to add")
-	status := fs.String("status", string(todo.StatusNotStarted), "status for the new to-do (not started|started|completed)")
-	updateID := fs.Int("update", 0, "ID of the to-do to update (description only)")
-	newDesc := fs.String("newdesc", "", "new description for the to-do when using -update")
-	out := fs.String("out", "out/todos.json", "path to the JSON file to read/write (forced under ./out)")
-	deleteID := fs.Int("delete", 0, "ID of the to-do to delete")
-
-	// Override default usage printer
-	fs.Usage = usage
-
-	// Parse args
-	if err := fs.Parse(args); err != nil {
-		if errors.Is(err, flag.ErrHelp) {
-			return nil
-		}
-		slog.ErrorContext(ctx, "flag parsing failed", "error", err)
+	inv, err := Parse(args)
+	if err != nil {
+		slog.ErrorContext(ctx, "command line parsing failed", "error", err)
 		return err
 	}
+	return a.Exec(ctx, inv)
+}
 
-	// Safely read flag values once to avoid repeated pointer dereferencing.
-	listMode := false
-	if listOnly != nil {
-		listMode = *listOnly
-	}
-	descVal := ""
-	if desc != nil {
-		descVal = strings.TrimSpace(*desc)
-	}
-	statusVal := todo.StatusNotStarted
-	if status != nil {
-		statusVal = todo.Status(*status)
-	}
-	updateIDVal := 0
-	if updateID != nil {
-		updateIDVal = *updateID
-	}
-	newDescVal := ""
-	if newDesc != nil {
-		newDescVal = strings.TrimSpace(*newDesc)
+// Exec runs a parsed command line. With -server (or TODO_SERVER) item
+// commands and batch go through the API server.
+func (a *CLI_App) Exec(ctx context.Context, inv *Invocation) error {
+	if inv.warning != "" {
+		fmt.Fprintln(stderr, inv.warning)
 	}
-	deleteIDVal := 0
-	if deleteID != nil {
-		deleteIDVal = *deleteID
-	}
-	outVal := "out/todos.json"
-	if out != nil {
-		outVal = *out
+	switch {
+	case inv.helpFor == "todo":
+		usage()
+		return nil
+	case inv.helpFor != "":
+		commandHelp(inv.helpFor)
+		return nil
+	case inv.cmd == nil:
+		printUsageExamples()
+		return nil
 	}
 
-	// Map the chosen output file to live under ./out/
-	outPath := normalizeOutPath(outVal)
-
-	// Load existing items before applying any mutations.
-	list, err := todo.Load(ctx, outPath)
-	if err != nil {
-		slog.ErrorContext(ctx, "failed to load todos", "error", err, "path", outPath)
-		return err
+	server := strings.TrimSpace(inv.Server)
+	if server == "" {
+		server = strings.TrimSpace(os.Getenv("TODO_SERVER"))
 	}
-
-	// Command routing
-
-	// Command routing — mutually exclusive modes for simplicity.
-	switch {
-	case listMode:
-		printList(list)
-		return nil
-	case descVal != "":
-		var it todo.Item
-		var err error
-		list, it, err = todo.Add(list, descVal, statusVal)
-		if err != nil {
-			slog.ErrorContext(ctx, "add failed", "error", err)
-			return err
-		}
-		_ = it
-		printList(list)
-		return todo.Save(ctx, list, outPath)
-	case updateIDVal > 0 && newDescVal != "":
-		var err error
-		list, err = todo.UpdateDescription(list, updateIDVal, newDescVal)
+	if server != "" && inv.cmd.name != "keys" {
+		remote, err := newRemote(server)
 		if err != nil {
-			slog.ErrorContext(ctx, "update failed", "error", err)
 			return err
 		}
-		printList(list)
-		return todo.Save(ctx, list, outPath)
-	case deleteIDVal > 0:
-		var err error
-		list, err = todo.Delete(list, deleteIDVal)
-		if err != nil {
-			slog.ErrorContext(ctx, "delete failed", "error", err)
-			return err
+		inv.remote = remote
+	}
+	if inv.cmd.admin != nil {
+		// A global -out applies unless the command is given its own. It goes
+		// after a projects subcommand name and before the user's flags.
+		if inv.Out != defaultOut && (inv.cmd.name == "batch" || inv.cmd.name == "projects") {
+			at := 0
+			if inv.cmd.name == "projects" && len(inv.args) > 0 && !strings.HasPrefix(inv.args[0], "-") {
+				at = 1
+			}
+			args := append([]string{}, inv.args[:at]...)
+			args = append(args, "-out", inv.Out)
+			inv.args = append(args, inv.args[at:]...)
 		}
-		printList(list)
-		return todo.Save(ctx, list, outPath)
-	default:
-		usage()
-		fmt.Println("\nExamples:")
-		fmt.Println("  go run . -list")
-		fmt.Println("  go run . -add \"Buy milk\" -status started")
-		fmt.Println("  go run . -update 3 -newdesc \"Buy oat milk\"")
-		fmt.Println("  go run . -delete 2")
-		return nil
+		return inv.cmd.admin(a, ctx, inv)
 	}
+	return inv.cmd.run(a, ctx, inv)
+}
 
+// printUsageExamples shows usage plus a few examples when no mode was chosen.
+func printUsageExamples() {
+	usage()
+	fmt.Println("\nExamples:")
+	fmt.Println("  todo list")
+	fmt.Println("  todo add \"Buy milk\" -status started")
+	fmt.Println("  todo edit 3 \"Buy oat milk\"")
+	fmt.Println("  todo status 3 completed")
+	fmt.Println("  todo rm 2")
+	fmt.Println("  todo export -all > backup.json")
+	fmt.Println("  todo import -file TODO.md -in work")
+	fmt.Println("  todo import -format trello -file board.json -in launch -dry-run")
+	fmt.Println("  todo watch -all")
+	fmt.Println("  todo list -o json | jq '.[] | select(.status == \"started\")'")
+	fmt.Println("  todo list -template '{{.ID}}: {{.Description}}'")
 }
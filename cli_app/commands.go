@@ -0,0 +1,628 @@
+package cli_app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-app/client"
+	"todo-app/todo"
+)
+
+//
+// cli_app/commands.go (package cli_app)
+// -------------------------------------
+// Git-style command line: `todo [global flags] <command> [flags] [args]`.
+// Parse turns os.Args into an Invocation before anything runs, so main can
+// set up logging and the trace id from the global flags; Exec then runs it.
+// Item commands accept their flags before, between or after the arguments.
+// The old flat flags (-list, -add, -update/-newdesc, -delete) are still
+// accepted: they are mapped onto the same commands with a deprecation
+// warning, and combining two of them is an error instead of silently
+// running one.
+//
+
+// stderr receives help, warnings and usage errors (swapped in tests).
+var stderr io.Writer = os.Stderr
+
+// defaultOut is the data file used when -out is not given.
+const defaultOut = "out/todos.json"
+
+// Globals are the flags accepted before the command.
+type Globals struct {
+	LogText  bool   // text logs instead of JSON
+	TraceID  string // external trace id; empty means generate one
+	Server   string // API server URL; empty falls back to TODO_SERVER
+	Out      string // data file, forced under ./out; commands may override it
+	Wait     bool   // after the command, keep running until Ctrl+C (the old behaviour)
+	Output   string // item output format (-o); commands may override it
+	Template string // text/template for -o template
+}
+
+// register adds the global flags to fs.
+func (g *Globals) register(fs *flag.FlagSet) {
+	fs.BoolVar(&g.LogText, "logtext", false, "use plain text logs instead of JSON")
+	fs.StringVar(&g.TraceID, "traceid", "", "use this trace id instead of a generated one")
+	fs.StringVar(&g.Server, "server", "", "send item commands to this API server (default $TODO_SERVER)")
+	fs.StringVar(&g.Out, "out", defaultOut, "data file (forced under ./out)")
+	fs.BoolVar(&g.Wait, "wait", false, "after the command, keep running until Ctrl+C")
+	fs.StringVar(&g.Output, "o", "", "output format: "+strings.Join(outputFormats, "|"))
+	fs.StringVar(&g.Template, "template", "", "text/template for each item (implies -o template)")
+}
+
+// args returns the global flags named in set as command-line arguments, so a
+// suggested command keeps them.
+func (g *Globals) args(set map[string]bool) []string {
+	var out []string
+	for _, f := range []struct {
+		name, value string
+	}{
+		{"logtext", "true"}, {"traceid", g.TraceID}, {"server", g.Server}, {"out", g.Out},
+		{"wait", "true"}, {"o", g.Output}, {"template", g.Template},
+	} {
+		switch {
+		case !set[f.name]:
+		case f.value == "true":
+			out = append(out, "-"+f.name)
+		default:
+			out = append(out, "-"+f.name, f.value)
+		}
+	}
+	return out
+}
+
+// usageError is a bad command line: an unknown command, wrong arguments or
+// conflicting flags.
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// opts holds the flags of the item commands; each command registers the
+// ones it uses.
+type opts struct {
+	out      string
+	in       string
+	all      bool
+	status   string
+	file     string
+	interval time.Duration
+	output   string
+	template string
+	format   string
+	prefer   string
+	watch    bool
+	dryRun   bool
+}
+
+// command is one subcommand.
+type command struct {
+	name    string
+	args    string // argument synopsis for help
+	summary string
+	help    string // paragraph shown by `todo help <name>`
+	flags   func(fs *flag.FlagSet, o *opts)
+	// minArgs/maxArgs bound the positional arguments (maxArgs < 0: no limit).
+	minArgs, maxArgs int
+	// run executes the parsed command.
+	run func(a *CLI_App, ctx context.Context, inv *Invocation) error
+	// admin commands (batch, projects, keys) parse their own arguments and
+	// print their own help.
+	admin func(a *CLI_App, ctx context.Context, inv *Invocation) error
+	usage func()
+}
+
+// Flag helpers shared by the item commands, so a flag means the same thing
+// everywhere.
+func outFlag(fs *flag.FlagSet, o *opts) {
+	fs.StringVar(&o.out, "out", o.out, "data file (forced under ./out)")
+}
+
+func inFlag(fs *flag.FlagSet, o *opts, what string) {
+	fs.StringVar(&o.in, "in", "", what)
+}
+
+// outputFlags selects how the items a command prints are written.
+func outputFlags(fs *flag.FlagSet, o *opts) {
+	fs.StringVar(&o.output, "o", o.output, "output format: "+strings.Join(outputFormats, "|"))
+	fs.StringVar(&o.template, "template", o.template, "text/template for each item, e.g. '{{.ID}}: {{.Description}}'")
+}
+
+// itemFlags are the flags of commands that change one item by id.
+func itemFlags(fs *flag.FlagSet, o *opts) {
+	outFlag(fs, o)
+	outputFlags(fs, o)
+}
+
+// formatFlag selects the file format of import and export.
+func formatFlag(fs *flag.FlagSet, o *opts) {
+	fs.StringVar(&o.format, "format", "", "file format: "+strings.Join(todo.CodecNames(), "|")+" (default: from the -file extension, else json)")
+}
+
+// importFormatFlag is formatFlag plus the other tools import reads.
+func importFormatFlag(fs *flag.FlagSet, o *opts) {
+	names := append(todo.CodecNames(), todo.SourceNames()...)
+	fs.StringVar(&o.format, "format", "", "file format: "+strings.Join(names, "|")+" (default: from the -file extension, else json)")
+}
+
+func allFlag(fs *flag.FlagSet, o *opts) {
+	fs.BoolVar(&o.all, "all", false, "use items from every project")
+}
+
+// commands lists the subcommands in help order.
+var commands = []*command{
+	{
+		name: "add", args: "<description>", summary: "Add an item",
+		help: "Adds an item to the current project (or -in <project>). The description\nis every argument joined by spaces, so quoting is optional.",
+		flags: func(fs *flag.FlagSet, o *opts) {
+			outFlag(fs, o)
+			outputFlags(fs, o)
+			inFlag(fs, o, "project to add to instead of the current one")
+			fs.StringVar(&o.status, "status", string(todo.StatusNotStarted), "status: not started|started|completed")
+		},
+		minArgs: 1, maxArgs: -1,
+		run: func(a *CLI_App, ctx context.Context, inv *Invocation) error {
+			desc := strings.TrimSpace(strings.Join(inv.args, " "))
+			if desc == "" {
+				return usagef("add: the description cannot be empty")
+			}
+			status, err := parseStatus(inv.opts.status)
+			if err != nil {
+				return err
+			}
+			return a.runItem(ctx, inv, itemCmd{desc: desc, status: status, in: strings.TrimSpace(inv.opts.in)})
+		},
+	},
+	{
+		name: "list", summary: "Show items",
+		help: "Shows the items of the current project, -in <project>, or every project\nwith -all.",
+		flags: func(fs *flag.FlagSet, o *opts) {
+			outFlag(fs, o)
+			outputFlags(fs, o)
+			inFlag(fs, o, "project to show instead of the current one")
+			allFlag(fs, o)
+			fs.StringVar(&o.status, "status", "", "only show items with this status")
+		},
+		run: func(a *CLI_App, ctx context.Context, inv *Invocation) error {
+			cmd := itemCmd{list: true, in: listTarget(inv.opts)}
+			if inv.opts.status != "" {
+				var err error
+				if cmd.filter, err = parseStatus(inv.opts.status); err != nil {
+					return err
+				}
+			}
+			return a.runItem(ctx, inv, cmd)
+		},
+	},
+	{
+		name: "watch", summary: "Show items and reprint them when they change",
+		help: "Like list, but keeps running: the items are checked every -interval and\nprinted again whenever they change (from another shell, the board or the\nAPI). Stops on Ctrl+C.",
+		flags: func(fs *flag.FlagSet, o *opts) {
+			outFlag(fs, o)
+			outputFlags(fs, o)
+			inFlag(fs, o, "project to show instead of the current one")
+			allFlag(fs, o)
+			fs.StringVar(&o.status, "status", "", "only show items with this status")
+			fs.DurationVar(&o.interval, "interval", time.Second, "how often to check for changes")
+		},
+		run: func(a *CLI_App, ctx context.Context, inv *Invocation) error {
+			if inv.opts.interval <= 0 {
+				return usagef("watch: -interval must be positive")
+			}
+			cmd := itemCmd{list: true, in: listTarget(inv.opts)}
+			if inv.opts.status != "" {
+				var err error
+				if cmd.filter, err = parseStatus(inv.opts.status); err != nil {
+					return err
+				}
+			}
+			return a.runWatch(ctx, inv, cmd)
+		},
+	},
+	{
+		name: "edit", args: "<id> <description>", summary: "Change an item's description",
+		help:    "Replaces the description of item <id>.",
+		flags:   itemFlags,
+		minArgs: 2, maxArgs: -1,
+		run: func(a *CLI_App, ctx context.Context, inv *Invocation) error {
+			id, err := parseID(inv.args[0])
+			if err != nil {
+				return err
+			}
+			return a.runItem(ctx, inv, itemCmd{updateID: id, newDesc: strings.Join(inv.args[1:], " ")})
+		},
+	},
+	{
+		name: "status", args: "<id> <status>", summary: "Change an item's status",
+		help:    "Sets the status of item <id> to not started, started or completed\n(not-started works without quotes).",
+		flags:   itemFlags,
+		minArgs: 2, maxArgs: -1,
+		run: func(a *CLI_App, ctx context.Context, inv *Invocation) error {
+			id, err := parseID(inv.args[0])
+			if err != nil {
+				return err
+			}
+			status, err := parseStatus(strings.Join(inv.args[1:], " "))
+			if err != nil {
+				return err
+			}
+			return a.runItem(ctx, inv, itemCmd{statusID: id, newStatus: status})
+		},
+	},
+	{
+		name: "rm", args: "<id>...", summary: "Delete items",
+		help:    "Deletes the given items. Nothing is deleted if any id does not exist.",
+		flags:   itemFlags,
+		minArgs: 1, maxArgs: -1,
+		run: func(a *CLI_App, ctx context.Context, inv *Invocation) error {
+			var ids []int
+			for _, s := range inv.args {
+				id, err := parseID(s)
+				if err != nil {
+					return err
+				}
+				ids = append(ids, id)
+			}
+			return a.runItem(ctx, inv, itemCmd{deleteIDs: ids})
+		},
+	},
+	{
+		name: "import", summary: "Add items from a file, or from a Trello, Todoist or GitHub export",
+		help: "Reads items from -file or stdin and adds them with new ids, keeping their\nstatus, priority and dates. The format is -format, else the file's\nextension (.json, .csv, .tsv, .md, .txt for todo.txt, .ics), else JSON as\nwritten by export. Items go to -in <project>, else the project they name, else\nthe current project. Nothing is added if any item is invalid.\n\n-format trello (a board's JSON export), todoist (a project's CSV export)\nor github (gh issue list --json output) reads another tool's tasks into\nthe current project or -in. Which tasks were imported is remembered, so\nimporting a newer export again only adds the new ones. -dry-run prints\nthe items that would be added and saves nothing.",
+		flags: func(fs *flag.FlagSet, o *opts) {
+			outFlag(fs, o)
+			outputFlags(fs, o)
+			inFlag(fs, o, "project to import into")
+			fs.StringVar(&o.file, "file", "-", "file to read; - for stdin")
+			importFormatFlag(fs, o)
+			fs.BoolVar(&o.dryRun, "dry-run", false, "print the items that would be added; save nothing")
+		},
+		run: func(a *CLI_App, ctx context.Context, inv *Invocation) error { return a.runImport(ctx, inv) },
+	},
+	{
+		name: "export", summary: "Write items as JSON, CSV, Markdown, todo.txt or iCalendar",
+		help: "Writes the items of the current project, -in <project>, or every project\nwith -all to -file or stdout. The format is -format, else the file's extension,\nelse JSON (which keeps every field and is what import reads by default).\n-format ical (or a .ics file) writes VTODOs for calendar apps; for a feed\nthey keep polling, see /calendar.ics in the README.",
+		flags: func(fs *flag.FlagSet, o *opts) {
+			outFlag(fs, o)
+			inFlag(fs, o, "project to export instead of the current one")
+			allFlag(fs, o)
+			fs.StringVar(&o.file, "file", "-", "file to write (forced under ./out); - for stdout")
+			formatFlag(fs, o)
+		},
+		run: func(a *CLI_App, ctx context.Context, inv *Invocation) error { return a.runExport(ctx, inv) },
+	},
+	{
+		name: "sync", args: "<file.md>", summary: "Two-way sync a project with a Markdown checklist",
+		help: "Reconciles a Markdown checklist (such as a repository's TODO.md) with the\ncurrent project, or -in <project>. Checkboxes map to statuses ([ ] not started,\n[/] started, [x] completed) and each line keeps its item's id in a hidden\n<!-- id:N --> marker. Changes made on either side since the last sync are\napplied to the other; items changed on both sides are reported as\nconflicts and left alone unless -prefer picks a side. Other lines of the\nfile are kept as they are. With -watch it keeps running and syncs again\nwhenever the file or the project changes.",
+		flags: func(fs *flag.FlagSet, o *opts) {
+			outFlag(fs, o)
+			inFlag(fs, o, "project to sync instead of the current one")
+			fs.StringVar(&o.prefer, "prefer", "", "settle conflicts with this side's version: file|store")
+			fs.BoolVar(&o.watch, "watch", false, "keep running and sync again when either side changes")
+			fs.DurationVar(&o.interval, "interval", time.Second, "how often -watch checks for changes")
+		},
+		minArgs: 1, maxArgs: 1,
+		run: func(a *CLI_App, ctx context.Context, inv *Invocation) error { return a.runSync(ctx, inv) },
+	},
+	{
+		name: "batch", args: "[-atomic] [-in <project>] < ops", summary: "Apply many operations from stdin",
+		admin: func(a *CLI_App, ctx context.Context, inv *Invocation) error {
+			return a.runBatch(ctx, inv.remote, inv.args)
+		},
+		usage: batchUsage,
+	},
+	{
+		name: "projects", args: "[create|rename|archive|unarchive|switch|migrate]", summary: "Manage projects (named lists of items)",
+		admin: func(a *CLI_App, ctx context.Context, inv *Invocation) error {
+			if inv.remote != nil {
+				return errProjectsRemote
+			}
+			return a.runProjects(ctx, inv.args)
+		},
+		usage: projectsUsage,
+	},
+	{
+		name: "keys", args: "<create|list|revoke>", summary: "Manage API server keys",
+		// keys always edits the local keys file: it administers the server.
+		admin: func(a *CLI_App, ctx context.Context, inv *Invocation) error { return a.runKeys(ctx, inv.args) },
+		usage: keysUsage,
+	},
+}
+
+// findCommand returns the command called name.
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// renamedCommands maps old command names to their new ones. "lists" became
+// "projects" so it is not confused with the API's shared /lists.
+var renamedCommands = map[string]string{"lists": "projects"}
+
+// renamed returns the current name for an old command name.
+func renamed(name string) (string, bool) {
+	if to, ok := renamedCommands[name]; ok {
+		return to, true
+	}
+	return name, false
+}
+
+// Invocation is a parsed command line, ready for Exec.
+type Invocation struct {
+	Globals
+
+	cmd     *command // nil: show the top-level usage
+	helpFor string   // show help for this command ("" with cmd nil: top level)
+	args    []string // positional arguments (admin commands: everything after the name)
+	opts    opts
+	printer printer // how item commands print items
+	warning string  // deprecation notice for the old flag syntax
+
+	remote *client.Client // set by Exec in remote mode
+}
+
+// Parse reads a command line (without the program name).
+func Parse(args []string) (*Invocation, error) {
+	inv := &Invocation{}
+	fs := flag.NewFlagSet("todo", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	inv.Globals.register(fs)
+	legacy := registerLegacy(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			inv.helpFor = "todo"
+			return inv, nil
+		}
+		return nil, usagef("%v (run 'todo help')", err)
+	}
+	rest := fs.Args()
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if legacy.used(set) {
+		if len(rest) > 0 {
+			return nil, usagef("unexpected argument %q after the old-style flags", rest[0])
+		}
+		return legacy.translate(inv, set)
+	}
+	if len(rest) == 0 {
+		return inv, nil
+	}
+
+	name, rest := rest[0], rest[1:]
+	if name == "help" {
+		inv.helpFor = "todo"
+		if len(rest) > 0 {
+			topic, _ := renamed(rest[0])
+			if findCommand(topic) == nil {
+				return nil, usagef("unknown command %q (run 'todo help')", rest[0])
+			}
+			inv.helpFor = topic
+		}
+		return inv, nil
+	}
+	if to, ok := renamed(name); ok {
+		suggest := append(inv.Globals.args(set), to)
+		inv.warning = fmt.Sprintf("warning: %s is deprecated; use: todo %s", name, shellQuote(append(suggest, rest...)))
+		name = to
+	}
+	cmd := findCommand(name)
+	if cmd == nil {
+		return nil, usagef("unknown command %q (run 'todo help')", name)
+	}
+	inv.cmd = cmd
+	if cmd.admin != nil {
+		inv.args = rest
+		return inv, nil
+	}
+	return inv, inv.parseCommand(rest)
+}
+
+// parseCommand parses an item command's flags and arguments.
+func (inv *Invocation) parseCommand(args []string) error {
+	cmd := inv.cmd
+	inv.opts.out = inv.Out
+	inv.opts.output, inv.opts.template = inv.Output, inv.Template
+	fs := flag.NewFlagSet("todo "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if cmd.flags != nil {
+		cmd.flags(fs, &inv.opts)
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			inv.helpFor, inv.cmd = cmd.name, nil
+			return nil
+		}
+		return usagef("%s: %v (run 'todo help %s')", cmd.name, err, cmd.name)
+	}
+	switch {
+	case len(positional) < cmd.minArgs:
+		return usagef("%s: expected %s (run 'todo help %s')", cmd.name, cmd.args, cmd.name)
+	case cmd.maxArgs >= 0 && len(positional) > cmd.maxArgs:
+		return usagef("%s: unexpected argument %q", cmd.name, positional[cmd.maxArgs])
+	}
+	if inv.opts.all && inv.opts.in != "" {
+		return usagef("%s: -in and -all cannot be used together", cmd.name)
+	}
+	if inv.printer, err = newPrinter(inv.opts.output, inv.opts.template); err != nil {
+		return usagef("%s: %v", cmd.name, err)
+	}
+	inv.args = positional
+	return nil
+}
+
+// legacyFlags are the pre-subcommand flags.
+type legacyFlags struct {
+	list     *bool
+	add      *string
+	status   *string
+	update   *int
+	newdesc  *string
+	deleteID *int
+	in       *string
+}
+
+// legacyModes are the old flags that each picked a mode.
+var legacyModes = []string{"list", "add", "update", "delete"}
+
+func registerLegacy(fs *flag.FlagSet) *legacyFlags {
+	return &legacyFlags{
+		list:     fs.Bool("list", false, "deprecated: use `todo list`"),
+		add:      fs.String("add", "", "deprecated: use `todo add`"),
+		status:   fs.String("status", string(todo.StatusNotStarted), "deprecated: use `todo add -status`"),
+		update:   fs.Int("update", 0, "deprecated: use `todo edit`"),
+		newdesc:  fs.String("newdesc", "", "deprecated: use `todo edit`"),
+		deleteID: fs.Int("delete", 0, "deprecated: use `todo rm`"),
+		in:       fs.String("in", "", "deprecated: use -in on the command"),
+	}
+}
+
+// used reports whether any old-style flag was given.
+func (l *legacyFlags) used(set map[string]bool) bool {
+	for _, n := range []string{"list", "add", "status", "update", "newdesc", "delete", "in"} {
+		if set[n] {
+			return true
+		}
+	}
+	return false
+}
+
+// translate maps the old flags onto a command, rejecting combinations the
+// old parser silently resolved by running only one of them.
+func (l *legacyFlags) translate(inv *Invocation, set map[string]bool) (*Invocation, error) {
+	var modes []string
+	for _, m := range legacyModes {
+		if set[m] {
+			modes = append(modes, "-"+m)
+		}
+	}
+	switch {
+	case len(modes) == 0:
+		return nil, usagef("-status, -newdesc and -in need -list, -add, -update or -delete")
+	case len(modes) > 1:
+		return nil, usagef("%s cannot be used together; run one command at a time", strings.Join(modes, " and "))
+	case set["status"] && !set["add"]:
+		return nil, usagef("-status only applies to -add (use: todo status <id> <status>)")
+	case set["newdesc"] != set["update"]:
+		return nil, usagef("-update and -newdesc must be used together")
+	case set["in"] && !set["list"] && !set["add"]:
+		return nil, usagef("-in only applies to -list and -add")
+	}
+
+	var name string
+	var flags, positional []string
+	switch modes[0] {
+	case "-list":
+		name = "list"
+	case "-add":
+		name, positional = "add", []string{*l.add}
+		if set["status"] {
+			flags = append(flags, "-status", *l.status)
+		}
+	case "-update":
+		name, positional = "edit", []string{strconv.Itoa(*l.update), *l.newdesc}
+	case "-delete":
+		name, positional = "rm", []string{strconv.Itoa(*l.deleteID)}
+	}
+	if set["in"] {
+		if *l.in == todo.AllLists {
+			flags = append(flags, "-all")
+		} else {
+			flags = append(flags, "-in", *l.in)
+		}
+	}
+	args := append([]string{name}, positional...)
+	args = append(args, flags...)
+	for _, p := range positional {
+		if strings.HasPrefix(p, "-") {
+			args = append(append([]string{name}, flags...), append([]string{"--"}, positional...)...)
+			break
+		}
+	}
+	inv.cmd = findCommand(args[0])
+	inv.warning = fmt.Sprintf("warning: %s is deprecated; use: todo %s", modes[0], shellQuote(append(inv.Globals.args(set), args...)))
+	if err := inv.parseCommand(args[1:]); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// shellQuote joins args for display, quoting the ones that need it.
+func shellQuote(args []string) string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\"'*$\\") {
+			a = strconv.Quote(a)
+		}
+		out[i] = a
+	}
+	return strings.Join(out, " ")
+}
+
+// parseID reads an item id argument.
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, usagef("invalid id %q: want a positive number", s)
+	}
+	return id, nil
+}
+
+// parseStatus reads a status argument; "not-started" and "not_started" are
+// accepted so the status needs no quotes.
+func parseStatus(s string) (todo.Status, error) {
+	st := todo.Status(strings.ToLower(strings.NewReplacer("-", " ", "_", " ").Replace(strings.TrimSpace(s))))
+	if err := st.Validate(); err != nil {
+		return "", usagef("%v", err)
+	}
+	return st, nil
+}
+
+// listTarget resolves -in/-all into the list name for itemCmd.in.
+func listTarget(o opts) string {
+	if o.all {
+		return todo.AllLists
+	}
+	return strings.TrimSpace(o.in)
+}
+
+// commandHelp prints help for one command.
+func commandHelp(name string) {
+	cmd := findCommand(name)
+	if cmd == nil {
+		usage()
+		return
+	}
+	if cmd.usage != nil {
+		cmd.usage()
+		return
+	}
+	synopsis := "todo " + cmd.name
+	if cmd.args != "" {
+		synopsis += " " + cmd.args
+	}
+	fmt.Fprintf(stderr, "%s.\n\nUsage:\n  %s [flags]\n\n%s\n", cmd.summary, synopsis, cmd.help)
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	var o opts
+	o.out = defaultOut
+	if cmd.flags != nil {
+		cmd.flags(fs, &o)
+	}
+	fmt.Fprintln(stderr, "\nFlags:")
+	fs.SetOutput(stderr)
+	fs.PrintDefaults()
+}
@@ -0,0 +1,173 @@
+package cli_app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"todo-app/todo"
+)
+
+// inTempDir runs the test from an empty working directory.
+func inTempDir(t *testing.T) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+}
+
+// captureStderr swaps the package's stderr writer for a buffer.
+func captureStderr(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	stderr = &buf
+	t.Cleanup(func() { stderr = os.Stderr })
+	return &buf
+}
+
+// TestCLI_Commands_ItemLifecycle runs add, list, edit, status and rm with
+// flags before and after the arguments.
+func TestCLI_Commands_ItemLifecycle(t *testing.T) {
+	inTempDir(t)
+	app := New()
+	ctx := context.Background()
+	run := func(args ...string) string {
+		t.Helper()
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, args)
+		out := getOutput()
+		if err != nil {
+			t.Fatalf("Run(%v): %v", args, err)
+		}
+		return out
+	}
+
+	run("add", "Buy", "milk")
+	run("add", "-status", "started", "Write report")
+	run("add", "Call mum", "-status", "not-started")
+	run("edit", "1", "Buy oat milk")
+	run("status", "3", "completed")
+	if out := run("list", "-status", "started"); !strings.Contains(out, "Write report") || strings.Contains(out, "oat milk") {
+		t.Fatalf("list -status started:\n%s", out)
+	}
+	run("rm", "1", "2")
+
+	list := readTodos(t, "todos.json")
+	if len(list) != 1 || list[0].Description != "Call mum" || list[0].Status != todo.StatusCompleted {
+		t.Fatalf("after lifecycle: %+v", list)
+	}
+
+	// rm is all or nothing.
+	getOutput := captureStdout(t)
+	err := app.Run(ctx, []string{"rm", "3", "99"})
+	getOutput()
+	if !errors.Is(err, todo.ErrNotFound) || len(readTodos(t, "todos.json")) != 1 {
+		t.Fatalf("rm with a missing id: err=%v", err)
+	}
+
+	// A global -out applies to the command; a command -out overrides it.
+	run("-out", "other.json", "add", "Elsewhere")
+	run("-out", "other.json", "add", "-out", "third.json", "Third")
+	if n := len(readTodos(t, "other.json")); n != 1 {
+		t.Fatalf("other.json has %d items", n)
+	}
+	if n := len(readTodos(t, "third.json")); n != 1 {
+		t.Fatalf("third.json has %d items", n)
+	}
+}
+
+// TestCLI_Commands_LegacyFlags maps the old flags onto commands with a
+// warning and rejects combinations the old parser silently half-ran.
+func TestCLI_Commands_LegacyFlags(t *testing.T) {
+	inTempDir(t)
+	app := New()
+	ctx := context.Background()
+	errOut := captureStderr(t)
+
+	getOutput := captureStdout(t)
+	err := app.Run(ctx, []string{"-add", "Buy milk", "-status", "started"})
+	getOutput()
+	if err != nil {
+		t.Fatalf("legacy add: %v", err)
+	}
+	if want := `warning: -add is deprecated; use: todo add "Buy milk" -status started`; !strings.Contains(errOut.String(), want) {
+		t.Fatalf("stderr = %q, want %q", errOut, want)
+	}
+	// The suggestion keeps the global flags it was given.
+	inv, err := Parse([]string{"-out", "work.json", "-server", "http://localhost:8080", "-logtext", "-delete", "3"})
+	if want := "warning: -delete is deprecated; use: todo -logtext -server http://localhost:8080 -out work.json rm 3"; err != nil || inv.warning != want {
+		t.Fatalf("warning = %q (%v), want %q", inv.warning, err, want)
+	}
+
+	for _, args := range [][]string{
+		{"-add", "x", "-delete", "1"},
+		{"-list", "-update", "1", "-newdesc", "y"},
+		{"-update", "1"},
+		{"-newdesc", "y"},
+		{"-delete", "1", "-status", "started"},
+		{"-delete", "1", "-in", "work"},
+		{"-list", "extra"},
+	} {
+		var ue usageError
+		if _, err := Parse(args); !errors.As(err, &ue) {
+			t.Fatalf("Parse(%q) = %v, want a usage error", args, err)
+		}
+	}
+	if list := readTodos(t, "todos.json"); len(list) != 1 {
+		t.Fatalf("conflicting flags changed the data: %+v", list)
+	}
+}
+
+// TestCLI_Commands_HelpAndErrors covers per-command help and bad command
+// lines.
+func TestCLI_Commands_HelpAndErrors(t *testing.T) {
+	inTempDir(t)
+	errOut := captureStderr(t)
+	app := New()
+	ctx := context.Background()
+
+	for _, args := range [][]string{{"help", "status"}, {"status", "-h"}} {
+		errOut.Reset()
+		if err := app.Run(ctx, args); err != nil {
+			t.Fatalf("Run(%v): %v", args, err)
+		}
+		if s := errOut.String(); !strings.Contains(s, "todo status <id> <status>") || !strings.Contains(s, "-out") {
+			t.Fatalf("help for %v:\n%s", args, s)
+		}
+	}
+	errOut.Reset()
+	if err := app.Run(ctx, []string{"help"}); err != nil || !strings.Contains(errOut.String(), "import") {
+		t.Fatalf("top-level help: %v\n%s", err, errOut)
+	}
+
+	for _, args := range [][]string{
+		{"frob"},
+		{"help", "frob"},
+		{"add"},
+		{"add", "   "},
+		{"edit", "1"},
+		{"edit", "one", "x"},
+		{"status", "1", "done-ish"},
+		{"rm"},
+		{"list", "extra"},
+		{"list", "-in", "work", "-all"},
+		{"export", "-all", "-in", "work"},
+		{"add", "-nope", "x"},
+	} {
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, args)
+		getOutput()
+		var ue usageError
+		if !errors.As(err, &ue) {
+			t.Fatalf("Run(%q) = %v, want a usage error", args, err)
+		}
+	}
+}
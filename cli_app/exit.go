@@ -0,0 +1,63 @@
+package cli_app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net"
+
+	"todo-app/client"
+	"todo-app/todo"
+)
+
+//
+// cli_app/exit.go (package cli_app)
+// ---------------------------------
+// Process exit codes, so scripts can tell a typo from a missing item from a
+// full disk without parsing messages. main passes the error from Exec to
+// ExitCode.
+//
+
+// Exit codes returned by the CLI.
+const (
+	ExitOK          = 0   // the command succeeded
+	ExitError       = 1   // any other failure
+	ExitUsage       = 2   // bad command line (as the flag package uses)
+	ExitNotFound    = 3   // no item with the given id
+	ExitInvalid     = 4   // validation: bad description, status, list or batch
+	ExitIO          = 5   // reading or writing files, or reaching the server
+	ExitConflict    = 6   // sync left conflicting edits for the user to settle
+	ExitInterrupted = 130 // stopped by Ctrl+C before finishing (128+SIGINT)
+)
+
+// ExitCode maps an error from Parse or Exec to the process exit code.
+func ExitCode(err error) int {
+	var (
+		usageErr  usageError
+		pathErr   *fs.PathError
+		netErr    net.Error
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, todo.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, todo.ErrConflict):
+		return ExitConflict
+	case errors.Is(err, todo.ErrInvalid), errors.Is(err, client.ErrInvalid):
+		return ExitInvalid
+	case errors.As(err, &pathErr), errors.As(err, &netErr),
+		errors.As(err, &syntaxErr), errors.As(err, &typeErr),
+		errors.Is(err, client.ErrServer):
+		return ExitIO
+	default:
+		return ExitError
+	}
+}
@@ -0,0 +1,59 @@
+package cli_app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"todo-app/todo"
+)
+
+// TestCLI_ExitCode runs failing commands and checks each maps to its own
+// exit code.
+func TestCLI_ExitCode(t *testing.T) {
+	inTempDir(t)
+	captureStderr(t)
+	app := New()
+	ctx := context.Background()
+	if err := os.MkdirAll("out/dir.json", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"add", "Buy milk"}, ExitOK},
+		{[]string{"list"}, ExitOK},
+		{[]string{"frobnicate"}, ExitUsage},
+		{[]string{"edit", "x", "Buy"}, ExitUsage},
+		{[]string{"list", "-in", "a", "-all"}, ExitUsage},
+		{[]string{"status", "1", "done"}, ExitUsage},
+		{[]string{"rm", "99"}, ExitNotFound},
+		{[]string{"edit", "42", "Buy"}, ExitNotFound},
+		{[]string{"add", "-in", "nope", "Buy"}, ExitInvalid},
+		{[]string{"edit", "1", " "}, ExitInvalid},
+		{[]string{"list", "-out", "dir.json"}, ExitIO},
+		{[]string{"import", "-file", "missing.json"}, ExitIO},
+	}
+	for _, c := range cases {
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, c.args)
+		getOutput()
+		if got := ExitCode(err); got != c.want {
+			t.Errorf("%v: exit code %d (err=%v), want %d", c.args, got, err, c.want)
+		}
+	}
+
+	if got := ExitCode(fmt.Errorf("saving: %w", context.Canceled)); got != ExitInterrupted {
+		t.Errorf("canceled: exit code %d, want %d", got, ExitInterrupted)
+	}
+	if got := ExitCode(fmt.Errorf("sync: 1 conflict(s): %w", todo.ErrConflict)); got != ExitConflict {
+		t.Errorf("sync conflict: exit code %d, want %d", got, ExitConflict)
+	}
+	if got := ExitCode(errors.New("boom")); got != ExitError {
+		t.Errorf("other error: exit code %d, want %d", got, ExitError)
+	}
+}
@@ -0,0 +1,395 @@
+package cli_app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"todo-app/client"
+	"todo-app/todo"
+)
+
+//
+// cli_app/items.go (package cli_app)
+// ----------------------------------
+// Local execution of the item commands against the data file under ./out,
+// plus import and export for both modes. Remote execution of the same
+// itemCmd lives in remote.go; both print through the command's printer so
+// the output is identical.
+//
+
+// itemCmd is a parsed item command; exactly one of its modes is set.
+type itemCmd struct {
+	list   bool
+	filter todo.Status // list: only this status ("" = all)
+
+	desc   string // add
+	status todo.Status
+
+	updateID int // edit
+	newDesc  string
+
+	statusID  int // status
+	newStatus todo.Status
+
+	deleteIDs []int // rm
+
+	in string // list/add: list name; "*" = every list
+
+	out printer // how the resulting items are printed
+}
+
+// runItem runs cmd locally or, in remote mode, through the API.
+func (a *CLI_App) runItem(ctx context.Context, inv *Invocation, cmd itemCmd) error {
+	cmd.out = inv.printer
+	if inv.remote != nil {
+		var err error
+		if cmd.in, err = remoteTarget(ctx, normalizeOutPath(inv.opts.out), cmd.in); err != nil {
+			return err
+		}
+		return runRemote(ctx, inv.remote, cmd)
+	}
+	return runLocal(ctx, normalizeOutPath(inv.opts.out), cmd)
+}
+
+// filterStatus keeps the items with status st ("" keeps all).
+func filterStatus(list []todo.Item, st todo.Status) []todo.Item {
+	if st == "" {
+		return list
+	}
+	out := []todo.Item{}
+	for _, it := range list {
+		if it.Status == st {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// loadLocal reads the items and the list settings for outPath and resolves
+// in (empty: the current list).
+func loadLocal(ctx context.Context, outPath, in string) ([]todo.Item, todo.Projects, string, error) {
+	list, err := todo.Load(ctx, outPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load todos", "error", err, "path", outPath)
+		return nil, todo.Projects{}, "", err
+	}
+	projects, err := todo.LoadProjects(ctx, todo.ProjectsPath(outPath))
+	if err != nil {
+		return nil, todo.Projects{}, "", err
+	}
+	target := projects.Current
+	if in != "" {
+		target = in
+	}
+	return list, projects, target, nil
+}
+
+// runLocal performs cmd on the data file at outPath. Mutations save and then
+// print every item.
+func runLocal(ctx context.Context, outPath string, cmd itemCmd) error {
+	list, projects, target, err := loadLocal(ctx, outPath, cmd.in)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case cmd.list:
+		return cmd.out.print(filterStatus(todo.FilterByList(list, target), cmd.filter))
+	case cmd.desc != "":
+		if err := projects.CheckWritable(target); err != nil {
+			slog.ErrorContext(ctx, "add failed", "error", err, "list", target)
+			return err
+		}
+		if list, _, err = todo.AddToList(list, target, cmd.desc, cmd.status); err != nil {
+			slog.ErrorContext(ctx, "add failed", "error", err)
+			return err
+		}
+	case cmd.updateID > 0:
+		if list, err = todo.UpdateDescription(list, cmd.updateID, cmd.newDesc); err != nil {
+			slog.ErrorContext(ctx, "update failed", "error", err)
+			return err
+		}
+	case cmd.statusID > 0:
+		if list, err = todo.UpdateStatus(list, cmd.statusID, cmd.newStatus); err != nil {
+			slog.ErrorContext(ctx, "status update failed", "error", err)
+			return err
+		}
+	case len(cmd.deleteIDs) > 0:
+		for _, id := range cmd.deleteIDs {
+			if list, err = todo.Delete(list, id); err != nil {
+				slog.ErrorContext(ctx, "delete failed", "error", err)
+				return err
+			}
+		}
+	default:
+		return usagef("nothing to do")
+	}
+	if err := todo.Save(ctx, list, outPath); err != nil {
+		return err
+	}
+	return cmd.out.print(list)
+}
+
+// listItems returns the items a list command shows, locally or through the API.
+func listItems(ctx context.Context, inv *Invocation, cmd itemCmd) ([]todo.Item, error) {
+	if inv.remote != nil {
+		target, err := remoteTarget(ctx, normalizeOutPath(inv.opts.out), cmd.in)
+		if err != nil {
+			return nil, err
+		}
+		items, err := inv.remote.List(ctx, client.Query{List: target})
+		if err != nil {
+			return nil, err
+		}
+		return filterStatus(items, cmd.filter), nil
+	}
+	list, _, target, err := loadLocal(ctx, normalizeOutPath(inv.opts.out), cmd.in)
+	if err != nil {
+		return nil, err
+	}
+	return filterStatus(todo.FilterByList(list, target), cmd.filter), nil
+}
+
+// runWatch prints the items of a list command, then prints them again each
+// time they change, until ctx is done. A failure on the first read is
+// returned; later ones (a server restarting, a file being rewritten) are
+// logged and retried on the next tick.
+func (a *CLI_App) runWatch(ctx context.Context, inv *Invocation, cmd itemCmd) error {
+	cmd.out = inv.printer
+	ticker := time.NewTicker(inv.opts.interval)
+	defer ticker.Stop()
+	var last []todo.Item
+	for first := true; ; first = false {
+		items, err := listItems(ctx, inv, cmd)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && first:
+			return err
+		case err != nil:
+			slog.WarnContext(ctx, "watch: reading items failed", "error", err)
+		case first || !reflect.DeepEqual(items, last):
+			// Machine formats get just the items, so NDJSON stays a stream.
+			if !first && !cmd.out.machine() {
+				fmt.Printf("\n-- changed at %s --\n", time.Now().Format(time.TimeOnly))
+			}
+			if err := cmd.out.print(items); err != nil {
+				return err
+			}
+			last = items
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// fileCodec picks the codec for import and export: -format, else the
+// extension of -file, else JSON.
+func fileCodec(format, file string) (todo.Codec, error) {
+	if format = strings.TrimSpace(format); format != "" {
+		c, err := todo.CodecFor(format)
+		if err != nil {
+			return nil, usagef("%v", err)
+		}
+		return c, nil
+	}
+	if c, ok := todo.CodecForFile(file); ok && file != "-" {
+		return c, nil
+	}
+	return todo.CodecFor("json")
+}
+
+// openImport opens -file for reading; "-" is stdin.
+func openImport(file string) (io.ReadCloser, error) {
+	if file == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(file)
+}
+
+// readImport decodes the items in -file ("-" is stdin) with codec.
+func readImport(codec todo.Codec, file string) ([]todo.Item, error) {
+	r, err := openImport(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	items, err := codec.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("import: reading items: %w", err)
+	}
+	return items, nil
+}
+
+// runImport adds the items from -file or stdin with new ids. With -dry-run
+// it prints the items it would add instead.
+func (a *CLI_App) runImport(ctx context.Context, inv *Invocation) error {
+	if src, ok := todo.SourceFor(inv.opts.format); ok {
+		return a.runImportExternal(ctx, inv, src)
+	}
+	codec, err := fileCodec(inv.opts.format, inv.opts.file)
+	if err != nil {
+		return err
+	}
+	items, err := readImport(codec, inv.opts.file)
+	if err != nil {
+		return err
+	}
+	in := strings.TrimSpace(inv.opts.in)
+
+	if inv.remote != nil && inv.opts.dryRun {
+		fmt.Fprintf(stderr, "dry run: %d to add; nothing was sent\n", len(items))
+		return inv.printer.print(items)
+	}
+	if inv.remote != nil {
+		if in, err = remoteTarget(ctx, normalizeOutPath(inv.opts.out), in); err != nil {
+			return err
+		}
+		if _, err := inv.remote.Import(ctx, items, in); err != nil {
+			slog.ErrorContext(ctx, "import failed", "error", err)
+			return err
+		}
+		all, err := inv.remote.List(ctx, client.Query{})
+		if err != nil {
+			return err
+		}
+		return inv.printer.print(all)
+	}
+
+	outPath := normalizeOutPath(inv.opts.out)
+	list, projects, current, err := loadLocal(ctx, outPath, "")
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		target := in
+		if target == "" {
+			target = items[i].List
+		}
+		if target == "" {
+			target = current
+		}
+		if err := projects.CheckWritable(target); err != nil {
+			return fmt.Errorf("import: item %d: %w", i+1, err)
+		}
+		items[i].List = target
+	}
+	list, added, err := todo.Import(list, items, "")
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	if inv.opts.dryRun {
+		fmt.Fprintf(stderr, "dry run: %d to add; nothing was saved\n", len(added))
+		return inv.printer.print(added)
+	}
+	if err := todo.Save(ctx, list, outPath); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "items imported", "count", len(added), "format", codec.Name(), "path", outPath)
+	return inv.printer.print(list)
+}
+
+// runImportExternal adds the tasks of another tool's export (see
+// todo/external.go) to the current list or -in, skipping the tasks imported
+// from that tool before. Their ids are kept beside the data file, which is
+// why this is local only.
+func (a *CLI_App) runImportExternal(ctx context.Context, inv *Invocation, src todo.Source) error {
+	if inv.remote != nil {
+		return fmt.Errorf("import: -format %s is not available with -server; import into the local data file", src.Name())
+	}
+	r, err := openImport(inv.opts.file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	records, err := src.Decode(r)
+	if err != nil {
+		return fmt.Errorf("import: reading the %s export: %w", src.Name(), err)
+	}
+
+	outPath := normalizeOutPath(inv.opts.out)
+	list, projects, target, err := loadLocal(ctx, outPath, strings.TrimSpace(inv.opts.in))
+	if err != nil {
+		return err
+	}
+	if err := projects.CheckWritable(target); err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	idsPath := todo.ExternalIDsPath(outPath)
+	ids, err := todo.LoadExternalIDs(ctx, idsPath)
+	if err != nil {
+		return err
+	}
+	res, err := todo.ImportExternal(list, src.Name(), records, ids, target)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	if inv.opts.dryRun {
+		fmt.Fprintf(stderr, "dry run: %d to add from %s, %d already imported; nothing was saved\n", len(res.Added), src.Name(), len(res.Skipped))
+		return inv.printer.print(res.Added)
+	}
+	if len(res.Added) > 0 {
+		if err := todo.Save(ctx, res.List, outPath); err != nil {
+			return err
+		}
+		if err := todo.SaveExternalIDs(ctx, res.IDs, idsPath); err != nil {
+			return err
+		}
+	}
+	slog.InfoContext(ctx, "items imported", "count", len(res.Added), "skipped", len(res.Skipped), "format", src.Name(), "path", outPath)
+	fmt.Fprintf(stderr, "%d added from %s, %d already imported\n", len(res.Added), src.Name(), len(res.Skipped))
+	return inv.printer.print(res.List)
+}
+
+// runExport writes the selected items in the -format codec.
+func (a *CLI_App) runExport(ctx context.Context, inv *Invocation) error {
+	codec, err := fileCodec(inv.opts.format, inv.opts.file)
+	if err != nil {
+		return err
+	}
+	target := listTarget(inv.opts)
+	var items []todo.Item
+	if inv.remote != nil {
+		if target, err = remoteTarget(ctx, normalizeOutPath(inv.opts.out), target); err != nil {
+			return err
+		}
+		if items, err = inv.remote.List(ctx, client.Query{List: target}); err != nil {
+			return err
+		}
+	} else {
+		list, _, resolved, err := loadLocal(ctx, normalizeOutPath(inv.opts.out), target)
+		if err != nil {
+			return err
+		}
+		items = todo.FilterByList(list, resolved)
+	}
+
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, items); err != nil {
+		return err
+	}
+	if inv.opts.file == "-" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	path := normalizeOutPath(inv.opts.file)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "items exported", "count", len(items), "format", codec.Name(), "path", path)
+	return nil
+}
@@ -0,0 +1,269 @@
+package cli_app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-app/todo"
+)
+
+// TestCLI_Items_ExportImportRoundTrip exports a list, imports it into
+// another data file and checks descriptions, statuses, lists and creation
+// times survive while ids are reassigned.
+func TestCLI_Items_ExportImportRoundTrip(t *testing.T) {
+	inTempDir(t)
+	app := New()
+	ctx := context.Background()
+	run := func(args ...string) string {
+		t.Helper()
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, args)
+		out := getOutput()
+		if err != nil {
+			t.Fatalf("Run(%v): %v", args, err)
+		}
+		return out
+	}
+
+	run("add", "Buy milk", "-status", "started")
+	run("projects", "create", "work")
+	run("add", "-in", "work", "Write report")
+
+	// export defaults to the current list, like list.
+	var exported []todo.Item
+	if err := json.Unmarshal([]byte(run("export")), &exported); err != nil || len(exported) != 1 {
+		t.Fatalf("export: %v %+v", err, exported)
+	}
+	run("export", "-all", "-file", "backup.json")
+	data, err := os.ReadFile("out/backup.json")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if err := json.Unmarshal(data, &exported); err != nil || len(exported) != 2 {
+		t.Fatalf("export -all: %v %+v", err, exported)
+	}
+
+	run("-out", "copy.json", "add", "Already here")
+	run("-out", "copy.json", "projects", "create", "work")
+	run("-out", "copy.json", "import", "-file", "out/backup.json")
+	got := readTodos(t, "copy.json")
+	if len(got) != 3 {
+		t.Fatalf("after import: %+v", got)
+	}
+	for i, it := range got[1:] {
+		want := exported[i]
+		if it.ID != i+2 || it.Description != want.Description || it.Status != want.Status ||
+			it.List != want.List || !it.CreatedAt.Equal(want.CreatedAt) {
+			t.Fatalf("imported %+v, want %+v with id %d", it, want, i+2)
+		}
+	}
+
+	// stdin, -in, and items without a creation time.
+	stdin = strings.NewReader(`[{"description":"From stdin","status":"completed"}]`)
+	t.Cleanup(func() { stdin = os.Stdin })
+	before := time.Now()
+	run("import", "-in", "work")
+	got = readTodos(t, "todos.json")
+	last := got[len(got)-1]
+	if last.Description != "From stdin" || last.List != "work" || last.CreatedAt.Before(before) {
+		t.Fatalf("stdin import: %+v", last)
+	}
+
+	// Bad input and unknown lists change nothing.
+	stdin = strings.NewReader(`[{"description":"x","list":"nope"}]`)
+	getOutput := captureStdout(t)
+	err = app.Run(ctx, []string{"import"})
+	getOutput()
+	if err == nil || len(readTodos(t, "todos.json")) != 3 {
+		t.Fatalf("import into a missing list: %v", err)
+	}
+	stdin = strings.NewReader(`{not json`)
+	if err := app.Run(ctx, []string{"import"}); err == nil {
+		t.Fatalf("import of invalid JSON succeeded")
+	}
+}
+
+// TestCLI_Items_ImportExportFormats moves items through todo.txt, CSV,
+// Markdown and iCalendar, picking the codec from -format or the file
+// extension, and checks priorities, due dates and lists survive.
+func TestCLI_Items_ImportExportFormats(t *testing.T) {
+	inTempDir(t)
+	app := New()
+	ctx := context.Background()
+	run := func(args ...string) string {
+		t.Helper()
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, args)
+		out := getOutput()
+		if err != nil {
+			t.Fatalf("Run(%v): %v", args, err)
+		}
+		return out
+	}
+	run("projects", "create", "home")
+
+	stdin = strings.NewReader("(A) Call the plumber +house @phone due:2024-05-03\nx 2024-05-04 2024-05-01 Buy milk list:home\n")
+	t.Cleanup(func() { stdin = os.Stdin })
+	run("import", "-format", "todo.txt")
+	got := readTodos(t, "todos.json")
+	if len(got) != 2 || got[0].Priority != "A" || got[0].Due.Format(time.DateOnly) != "2024-05-03" ||
+		got[1].List != "home" || got[1].Status != todo.StatusCompleted || got[1].CompletedAt.IsZero() {
+		t.Fatalf("todo.txt import: %+v", got)
+	}
+
+	run("export", "-all", "-file", "backup.csv")
+	data, err := os.ReadFile("out/backup.csv")
+	if err != nil || !strings.HasPrefix(string(data), strings.Join(todo.CSVColumns, ",")+"\n") {
+		t.Fatalf("csv export: %v\n%s", err, data)
+	}
+	run("-out", "copy.json", "projects", "create", "home")
+	run("-out", "copy.json", "import", "-file", "out/backup.csv")
+	copied := readTodos(t, "copy.json")
+	if len(copied) != 2 || copied[0].Priority != got[0].Priority || !copied[0].Due.Equal(got[0].Due) ||
+		copied[1].List != "home" || !copied[1].CompletedAt.Equal(got[1].CompletedAt) {
+		t.Fatalf("csv import: %+v, want %+v", copied, got)
+	}
+
+	md := run("-out", "copy.json", "export", "-all", "-format", "md")
+	for _, line := range []string{"- [ ] (A) Call the plumber +house @phone due:2024-05-03", "## home", "- [x] Buy milk"} {
+		if !strings.Contains(md, line+"\n") {
+			t.Fatalf("markdown export lacks %q:\n%s", line, md)
+		}
+	}
+
+	run("export", "-all", "-file", "todos.ics")
+	data, err = os.ReadFile("out/todos.ics")
+	if err != nil || !strings.Contains(string(data), "BEGIN:VTODO\r\n") || !strings.Contains(string(data), "PRIORITY:1\r\n") {
+		t.Fatalf("ical export: %v\n%s", err, data)
+	}
+	run("-out", "cal.json", "projects", "create", "home")
+	run("-out", "cal.json", "import", "-file", "out/todos.ics")
+	if cal := readTodos(t, "cal.json"); len(cal) != 2 || cal[0].Priority != "A" || !cal[0].Due.Equal(got[0].Due) || cal[1].List != "home" {
+		t.Fatalf("ical import: %+v", cal)
+	}
+
+	// An unknown format is a usage error; a bad line adds nothing.
+	if err := app.Run(ctx, []string{"export", "-format", "xml"}); ExitCode(err) != ExitUsage {
+		t.Fatalf("export -format xml: %v", err)
+	}
+	stdin = strings.NewReader("- [ ] fine\n- [ ] (A) due:2024-06-01\n")
+	if err := app.Run(ctx, []string{"import", "-format", "markdown"}); ExitCode(err) != ExitInvalid {
+		t.Fatalf("bad markdown import: %v", err)
+	}
+	if n := len(readTodos(t, "todos.json")); n != 2 {
+		t.Fatalf("failed import added items: %d", n)
+	}
+}
+
+// TestCLI_Items_ImportExternal previews and imports gh issue list output,
+// then imports a newer export and checks only the new issue is added.
+func TestCLI_Items_ImportExternal(t *testing.T) {
+	inTempDir(t)
+	app := New()
+	ctx := context.Background()
+	errOut := captureStderr(t)
+	run := func(args ...string) string {
+		t.Helper()
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, args)
+		out := getOutput()
+		if err != nil {
+			t.Fatalf("Run(%v): %v", args, err)
+		}
+		return out
+	}
+	run("projects", "create", "app")
+	issues := `[{"number": 1, "title": "Crash on start", "state": "OPEN", "url": "https://github.com/acme/app/issues/1", "labels": [{"name": "bug"}]},
+	  {"number": 2, "title": "Add docs", "state": "CLOSED", "url": "https://github.com/acme/app/issues/2", "closedAt": "2024-05-01T10:00:00Z"}]`
+	if err := os.WriteFile("issues.json", []byte(issues), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := run("import", "-format", "github", "-file", "issues.json", "-in", "app", "-dry-run", "-o", "json")
+	var preview []todo.Item
+	if err := json.Unmarshal([]byte(out), &preview); err != nil || len(preview) != 2 || preview[0].Description != "Crash on start @bug" {
+		t.Fatalf("dry run: %v\n%s", err, out)
+	}
+	if _, err := os.Stat("out/todos.json"); !os.IsNotExist(err) || !strings.Contains(errOut.String(), "dry run: 2 to add from github") {
+		t.Fatalf("dry run saved something (%v) or said %q", err, errOut.String())
+	}
+
+	run("import", "-format", "github", "-file", "issues.json", "-in", "app")
+	got := readTodos(t, "todos.json")
+	if len(got) != 2 || got[0].List != "app" || got[1].Status != todo.StatusCompleted {
+		t.Fatalf("import: %+v", got)
+	}
+
+	issues = strings.Replace(issues, `[`, `[{"number": 3, "title": "Dark mode", "state": "OPEN", "url": "https://github.com/acme/app/issues/3"},`, 1)
+	if err := os.WriteFile("issues.json", []byte(issues), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("import", "-format", "github", "-file", "issues.json", "-in", "app")
+	if got := readTodos(t, "todos.json"); len(got) != 3 || got[2].Description != "Dark mode" {
+		t.Fatalf("re-import: %+v", got)
+	}
+	if !strings.Contains(errOut.String(), "1 added from github, 2 already imported") {
+		t.Fatalf("re-import summary: %q", errOut.String())
+	}
+
+	// Deleting the newest issue frees its id for an unrelated item; the issue
+	// is imported again rather than taken to be that item.
+	run("rm", "3")
+	run("add", "-in", "app", "Unrelated groceries")
+	run("import", "-format", "github", "-file", "issues.json", "-in", "app")
+	if got := readTodos(t, "todos.json"); len(got) != 4 || got[2].Description != "Unrelated groceries" || got[3].Description != "Dark mode" {
+		t.Fatalf("re-import after the id was reused: %+v", got)
+	}
+}
+
+// TestCLI_Items_Watch runs watch until its context is canceled and checks
+// it prints the items once, then again after an outside change, and exits
+// cleanly.
+func TestCLI_Items_Watch(t *testing.T) {
+	inTempDir(t)
+	app := New()
+	getOutput := captureStdout(t)
+	if err := app.Run(context.Background(), []string{"add", "Buy milk"}); err != nil {
+		getOutput()
+		t.Fatalf("add: %v", err)
+	}
+	getOutput()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	getOutput = captureStdout(t)
+	done := make(chan error, 1)
+	go func() { done <- app.Run(ctx, []string{"watch", "-interval", "10ms"}) }()
+
+	time.Sleep(50 * time.Millisecond)
+	list := readTodos(t, "todos.json")
+	list, _, err := todo.Add(list, "Walk dog", todo.StatusStarted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := todo.Save(context.Background(), list, "out/todos.json"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("watch: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("watch did not stop after cancel")
+	}
+	out := getOutput()
+	if strings.Count(out, "Buy milk") != 2 || strings.Count(out, "Walk dog") != 1 || strings.Count(out, "-- changed at") != 1 {
+		t.Fatalf("watch output:\n%s", out)
+	}
+
+	if err := app.Run(context.Background(), []string{"watch", "-interval", "0s"}); ExitCode(err) != ExitUsage {
+		t.Fatalf("watch -interval 0s: err=%v", err)
+	}
+}
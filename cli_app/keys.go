@@ -0,0 +1,147 @@
+package cli_app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"todo-app/auth"
+	"todo-app/service"
+)
+
+//
+// cli_app/keys.go (package cli_app)
+// ---------------------------------
+// Admin subcommand for the API server's keyring:
+//   todo keys create -name <name> [-user <id>] [-scope read|write] [-keys out/keys.json]
+//   todo keys list   [-keys out/keys.json]
+//   todo keys revoke <id> [-keys out/keys.json]
+//
+
+// keysUsage prints help for the keys subcommand.
+func keysUsage() {
+	fmt.Fprintf(os.Stderr, `Manage API keys for the Todo API server.
+
+Usage:
+  go run ./cmd/cli keys create -name "<name>" [-user <id>] [-scope read|write] [-keys %[1]s]
+  go run ./cmd/cli keys list [-keys %[1]s]
+  go run ./cmd/cli keys revoke <id> [-keys %[1]s]
+
+The token printed by "create" is shown once; only its hash is stored.
+`, auth.DefaultPath)
+}
+
+// runKeys dispatches the keys subcommands.
+func (a *CLI_App) runKeys(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		keysUsage()
+		return nil
+	}
+	sub, rest := args[0], args[1:]
+
+	fs := flag.NewFlagSet("keys "+sub, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = keysUsage
+	path := fs.String("keys", auth.DefaultPath, "path to the API keys file")
+	name := fs.String("name", "", "human-readable name for the new key (create)")
+	user := fs.String("user", service.DefaultUser, "user the new key acts as; each user has a private list (create)")
+	scope := fs.String("scope", string(auth.ScopeRead), "scope for the new key: read|write (create)")
+
+	// Allow the id for "revoke" before or after flags.
+	positional, err := parseInterspersed(fs, rest)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	keys, err := auth.Open(*path)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to open keyring", "error", err, "path", *path)
+		return err
+	}
+
+	switch sub {
+	case "create":
+		if err := service.ValidateUserID(*user); err != nil {
+			return err
+		}
+		token, key, err := keys.Create(*name, *user, auth.Scope(*scope))
+		if err != nil {
+			slog.ErrorContext(ctx, "key create failed", "error", err)
+			return err
+		}
+		slog.InfoContext(ctx, "api key created", "id", key.ID, "user", key.User, "scope", key.Scope, "path", *path)
+		fmt.Printf("Created key %s (%s, user %s, scope %s).\n", key.ID, key.Name, key.User, key.Scope)
+		fmt.Printf("Token (shown once): %s\n", token)
+		return nil
+
+	case "list":
+		list, err := keys.List()
+		if err != nil {
+			return err
+		}
+		printKeys(list)
+		return nil
+
+	case "revoke":
+		if len(positional) != 1 {
+			return errors.New("keys revoke: expected exactly one key id")
+		}
+		key, err := keys.Revoke(positional[0])
+		if err != nil {
+			slog.ErrorContext(ctx, "key revoke failed", "error", err, "id", positional[0])
+			return err
+		}
+		slog.InfoContext(ctx, "api key revoked", "id", key.ID, "path", *path)
+		fmt.Printf("Revoked key %s (%s).\n", key.ID, key.Name)
+		return nil
+
+	default:
+		keysUsage()
+		return fmt.Errorf("unknown keys subcommand %q", sub)
+	}
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments (the standard flag package stops at the first
+// positional), returning the positionals in order. Everything after "--"
+// is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return append(positional, rest...), nil // "--" ends the flags
+		}
+		args = rest
+		if len(args) > 0 {
+			positional = append(positional, args[0])
+			args = args[1:]
+		}
+	}
+	return positional, nil
+}
+
+// printKeys prints the keyring as a table; secrets and hashes are never shown.
+func printKeys(list []auth.Key) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tUSER\tSCOPE\tCREATED\tREVOKED")
+	for _, k := range list {
+		revoked := "-"
+		if k.RevokedAt != nil {
+			revoked = k.RevokedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.User, k.Scope, k.CreatedAt.Format(time.RFC3339), revoked)
+	}
+	_ = w.Flush()
+}
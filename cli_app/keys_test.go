@@ -0,0 +1,69 @@
+package cli_app
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+
+	"todo-app/auth"
+)
+
+// TestCLI_Keys_CreateListRevoke drives the keys admin subcommand end to end
+// and verifies the printed token authenticates until it is revoked.
+// It uses an isolated temporary working directory for the test.
+func TestCLI_Keys_CreateListRevoke(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	app := New()
+	ctx := context.Background()
+
+	getOutput := captureStdout(t)
+	err = app.Run(ctx, []string{"keys", "create", "-name", "ci bot", "-user", "alice", "-scope", "write"})
+	out := getOutput()
+	if err != nil {
+		t.Fatalf("keys create: %v", err)
+	}
+	m := regexp.MustCompile(`Token \(shown once\): (todo_([0-9a-f]+)\.\S+)`).FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("token not printed:\n%s", out)
+	}
+	token, id := m[1], m[2]
+
+	keys, err := auth.Open(auth.DefaultPath)
+	if err != nil {
+		t.Fatalf("auth.Open: %v", err)
+	}
+	if k, err := keys.Authenticate(token); err != nil || k.Scope != auth.ScopeWrite || k.User != "alice" {
+		t.Fatalf("Authenticate = %+v, %v", k, err)
+	}
+
+	getOutput = captureStdout(t)
+	err = app.Run(ctx, []string{"keys", "list"})
+	out = getOutput()
+	if err != nil || !regexp.MustCompile(id+`\s+ci bot\s+alice\s+write`).MatchString(out) {
+		t.Fatalf("keys list err=%v output:\n%s", err, out)
+	}
+
+	getOutput = captureStdout(t)
+	err = app.Run(ctx, []string{"keys", "revoke", id})
+	_ = getOutput()
+	if err != nil {
+		t.Fatalf("keys revoke: %v", err)
+	}
+	if _, err := keys.Authenticate(token); err == nil {
+		t.Fatalf("token still valid after revoke")
+	}
+
+	if err := app.Run(ctx, []string{"keys", "revoke"}); err == nil {
+		t.Fatalf("revoke without id should fail")
+	}
+}
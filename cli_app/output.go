@@ -0,0 +1,143 @@
+package cli_app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"todo-app/todo"
+)
+
+//
+// cli_app/output.go (package cli_app)
+// -----------------------------------
+// Output formats for the item commands (-o): the aligned table people read,
+// and JSON, NDJSON, CSV, TSV, a Markdown table or a text/template for
+// scripts, jq and spreadsheets. Every format writes the same items; only the
+// table is meant to change shape over time.
+//
+
+// outputFormats are the values -o accepts, in help order.
+var outputFormats = []string{"table", "json", "ndjson", "csv", "tsv", "markdown", "template"}
+
+// printer writes item lists in one output format.
+type printer struct {
+	format string
+	tmpl   *template.Template // format "template"
+}
+
+// newPrinter checks format and tmpl. A template implies -o template; an
+// empty format is the table.
+func newPrinter(format, tmpl string) (printer, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "md" {
+		format = "markdown"
+	}
+	if tmpl != "" {
+		if format != "" && format != "table" && format != "template" {
+			return printer{}, usagef("-template cannot be used with -o %s", format)
+		}
+		format = "template"
+	}
+	if format == "" {
+		format = "table"
+	}
+	p := printer{format: format}
+	switch format {
+	case "table", "json", "ndjson", "csv", "tsv", "markdown":
+	case "template":
+		if tmpl == "" {
+			return printer{}, usagef("-o template needs -template '<text/template>', e.g. '{{.ID}} {{.Description}}'")
+		}
+		t, err := template.New("item").Parse(tmpl)
+		if err != nil {
+			return printer{}, usagef("-template: %v", err)
+		}
+		p.tmpl = t
+	default:
+		return printer{}, usagef("unknown output format %q (use %s)", format, strings.Join(outputFormats, ", "))
+	}
+	return p, nil
+}
+
+// machine reports whether the format is for programs, so nothing but the
+// items may be written to stdout.
+func (p printer) machine() bool { return p.format != "table" && p.format != "" }
+
+// print writes list to stdout.
+func (p printer) print(list []todo.Item) error {
+	return p.write(os.Stdout, list)
+}
+
+// write writes list to w in the printer's format.
+func (p printer) write(w io.Writer, list []todo.Item) error {
+	switch p.format {
+	case "json":
+		if list == nil {
+			list = []todo.Item{}
+		}
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, it := range list {
+			if err := enc.Encode(it); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv", "tsv":
+		// The export codec, so -o csv and export -format csv share columns.
+		codec, err := todo.CodecFor(p.format)
+		if err != nil {
+			return err
+		}
+		return codec.Encode(w, list)
+	case "markdown":
+		cell := strings.NewReplacer("|", `\|`, "\n", " ")
+		var sb strings.Builder
+		sb.WriteString("| ID | Description | Status | Created |\n| --: | --- | --- | --- |\n")
+		for _, it := range list {
+			fmt.Fprintf(&sb, "| %d | %s | %s | %s |\n", it.ID, cell.Replace(it.Description), it.Status, it.CreatedAt.Format(time.RFC3339))
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	case "template":
+		for _, it := range list {
+			var sb strings.Builder
+			if err := p.tmpl.Execute(&sb, it); err != nil {
+				return fmt.Errorf("-template: %w", err)
+			}
+			if !strings.HasSuffix(sb.String(), "\n") {
+				sb.WriteByte('\n')
+			}
+			if _, err := io.WriteString(w, sb.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return writeTable(w, list)
+	}
+}
+
+// writeTable writes the aligned table people read.
+// We rely on tabwriter to align columns regardless of content width.
+func writeTable(out io.Writer, list []todo.Item) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDESCRIPTION\tSTATUS\tCREATED")
+	for _, t := range list {
+		// Time is formatted as RFC3339 for easy machine readability and consistency.
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", t.ID, t.Description, t.Status, t.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,141 @@
+package cli_app
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-app/todo"
+)
+
+// outputItems are two items whose descriptions need quoting or escaping.
+func outputItems() []todo.Item {
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	return []todo.Item{
+		{ID: 1, Description: `Buy "oat" milk, 2l`, Status: todo.StatusStarted, CreatedAt: at, List: "home"},
+		{ID: 2, Description: "Pipes | tabs\there", Status: todo.StatusCompleted, CreatedAt: at},
+	}
+}
+
+// TestCLI_Output_Formats writes the same items in every format and parses
+// the machine-readable ones back.
+func TestCLI_Output_Formats(t *testing.T) {
+	items := outputItems()
+	write := func(format, tmpl string, list []todo.Item) string {
+		t.Helper()
+		p, err := newPrinter(format, tmpl)
+		if err != nil {
+			t.Fatalf("newPrinter(%q, %q): %v", format, tmpl, err)
+		}
+		var buf bytes.Buffer
+		if err := p.write(&buf, list); err != nil {
+			t.Fatalf("write %s: %v", format, err)
+		}
+		return buf.String()
+	}
+
+	var fromJSON []todo.Item
+	if err := json.Unmarshal([]byte(write("json", "", items)), &fromJSON); err != nil || len(fromJSON) != 2 || fromJSON[0] != items[0] {
+		t.Fatalf("json: %+v, %v", fromJSON, err)
+	}
+	if got := write("json", "", nil); got != "[]\n" {
+		t.Fatalf("json of no items = %q", got)
+	}
+
+	lines := strings.Split(strings.TrimSpace(write("ndjson", "", items)), "\n")
+	var second todo.Item
+	if len(lines) != 2 || json.Unmarshal([]byte(lines[1]), &second) != nil || second != items[1] {
+		t.Fatalf("ndjson: %q", lines)
+	}
+
+	for _, format := range []string{"csv", "tsv"} {
+		r := csv.NewReader(strings.NewReader(write(format, "", items)))
+		if format == "tsv" {
+			r.Comma = '\t'
+		}
+		rows, err := r.ReadAll()
+		if err != nil || len(rows) != 3 {
+			t.Fatalf("%s: %q, %v", format, rows, err)
+		}
+		if strings.Join(rows[0], ",") != strings.Join(todo.CSVColumns, ",") || rows[1][1] != items[0].Description ||
+			rows[2][1] != items[1].Description || rows[1][3] != "2024-05-01T09:30:00Z" || rows[1][4] != "home" {
+			t.Fatalf("%s rows: %q", format, rows)
+		}
+		// -o csv is export's CSV: import reads it back.
+		codec, _ := todo.CodecFor(format)
+		back, err := codec.Decode(strings.NewReader(write(format, "", items)))
+		if err != nil || len(back) != 2 || back[1].Description != items[1].Description {
+			t.Fatalf("%s does not import: %+v, %v", format, back, err)
+		}
+	}
+
+	md := write("md", "", items)
+	if !strings.HasPrefix(md, "| ID | Description | Status | Created |\n| --: |") ||
+		!strings.Contains(md, `| 2 | Pipes \| tabs`+"\there | completed |") {
+		t.Fatalf("markdown:\n%s", md)
+	}
+
+	if got := write("", "{{.ID}}:{{.Status}}", items); got != "1:started\n2:completed\n" {
+		t.Fatalf("template = %q", got)
+	}
+	if got := write("table", "", items); !strings.HasPrefix(got, "ID  DESCRIPTION") {
+		t.Fatalf("table:\n%s", got)
+	}
+}
+
+// TestCLI_Output_Errors checks bad -o and -template values are usage errors.
+func TestCLI_Output_Errors(t *testing.T) {
+	for _, c := range [][2]string{{"xml", ""}, {"template", ""}, {"json", "{{.ID}}"}, {"", "{{.ID"}} {
+		if _, err := newPrinter(c[0], c[1]); ExitCode(err) != ExitUsage {
+			t.Errorf("newPrinter(%q, %q): err=%v, want a usage error", c[0], c[1], err)
+		}
+	}
+	p, _ := newPrinter("", "{{.Nope}}")
+	if err := p.write(&bytes.Buffer{}, outputItems()); err == nil {
+		t.Fatal("template with an unknown field did not fail")
+	}
+}
+
+// TestCLI_Output_Commands checks -o on the command, as a global flag and
+// with the old -list flag, and that mutations print in the chosen format.
+func TestCLI_Output_Commands(t *testing.T) {
+	inTempDir(t)
+	captureStderr(t)
+	app := New()
+	run := func(args ...string) string {
+		t.Helper()
+		getOutput := captureStdout(t)
+		err := app.Run(context.Background(), args)
+		out := getOutput()
+		if err != nil {
+			t.Fatalf("Run(%v): %v", args, err)
+		}
+		return out
+	}
+
+	var added []todo.Item
+	if err := json.Unmarshal([]byte(run("add", "-o", "json", "Buy milk")), &added); err != nil || len(added) != 1 {
+		t.Fatalf("add -o json: %+v, %v", added, err)
+	}
+	run("add", "Walk dog", "-status", "started")
+	if got := run("-o", "ndjson", "list", "-status", "started"); strings.Count(got, "\n") != 1 || !strings.Contains(got, `"description":"Walk dog"`) {
+		t.Fatalf("-o ndjson list:\n%s", got)
+	}
+	if got := run("-o", "csv", "-list"); !strings.HasPrefix(got, strings.Join(todo.CSVColumns, ",")+"\n1,Buy milk,") {
+		t.Fatalf("-o csv -list:\n%s", got)
+	}
+	if got := run("status", "1", "completed", "-template", "{{.ID}} {{.Status}}"); got != "1 completed\n2 started\n" {
+		t.Fatalf("status -template = %q", got)
+	}
+
+	getOutput := captureStdout(t)
+	err := app.Run(context.Background(), []string{"list", "-o", "yaml"})
+	getOutput()
+	if ExitCode(err) != ExitUsage {
+		t.Fatalf("list -o yaml: err=%v", err)
+	}
+}
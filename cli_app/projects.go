@@ -0,0 +1,175 @@
+package cli_app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"todo-app/todo"
+)
+
+//
+// cli_app/projects.go (package cli_app)
+// -------------------------------------
+// Projects (named lists of items) inside one data file:
+//   todo projects                      show all projects (* marks the current one)
+//   todo projects create <name> [-desc ".."]
+//   todo projects rename <old> <new>
+//   todo projects archive|unarchive <name>
+//   todo projects switch <name>
+//   todo projects migrate              move project-less items into "default"
+// Every form accepts -out like the item commands. "todo lists" is the old,
+// deprecated name.
+//
+
+// projectsUsage prints help for the projects subcommand.
+func projectsUsage() {
+	fmt.Fprintf(os.Stderr, `Manage projects (named lists of items) in the data file.
+
+Usage:
+  go run ./cmd/cli projects [-out out/todos.json]
+  go run ./cmd/cli projects create <name> [-desc "<description>"]
+  go run ./cmd/cli projects rename <old> <new>
+  go run ./cmd/cli projects archive <name>
+  go run ./cmd/cli projects unarchive <name>
+  go run ./cmd/cli projects switch <name>
+  go run ./cmd/cli projects migrate
+
+Item commands use the current project; pass -in <name> to target another
+one, or "list -all" to show items from every project.
+`)
+}
+
+// runProjects dispatches the projects subcommands.
+func (a *CLI_App) runProjects(ctx context.Context, args []string) error {
+	sub := "show"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		sub, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("projects "+sub, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = projectsUsage
+	out := fs.String("out", "out/todos.json", "path to the JSON file to read/write (forced under ./out)")
+	desc := fs.String("desc", "", "description for a new project (create)")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	want := func(n int) error {
+		if len(positional) != n {
+			return fmt.Errorf("projects %s: expected %d argument(s), got %d", sub, n, len(positional))
+		}
+		return nil
+	}
+
+	outPath := normalizeOutPath(*out)
+	projPath := todo.ProjectsPath(outPath)
+	items, err := todo.Load(ctx, outPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load todos", "error", err, "path", outPath)
+		return err
+	}
+	projects, err := todo.LoadProjects(ctx, projPath)
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "show", "ls":
+		printProjects(projects, items)
+		return nil
+
+	case "create":
+		if err := want(1); err != nil {
+			return err
+		}
+		var pr todo.Project
+		if projects, pr, err = todo.CreateProject(projects, positional[0], *desc); err != nil {
+			return err
+		}
+		fmt.Printf("Created project %q.\n", pr.Name)
+		return todo.SaveProjects(ctx, projects, projPath)
+
+	case "rename":
+		if err := want(2); err != nil {
+			return err
+		}
+		if projects, items, err = todo.RenameProject(projects, items, positional[0], positional[1]); err != nil {
+			return err
+		}
+		// Items first: a crash in between leaves items in a project the
+		// registry doesn't know yet, which "projects migrate" repairs.
+		if err := todo.Save(ctx, items, outPath); err != nil {
+			return err
+		}
+		fmt.Printf("Renamed project %q to %q.\n", positional[0], positional[1])
+		return todo.SaveProjects(ctx, projects, projPath)
+
+	case "archive", "unarchive":
+		if err := want(1); err != nil {
+			return err
+		}
+		if projects, err = todo.SetArchived(projects, positional[0], sub == "archive"); err != nil {
+			return err
+		}
+		fmt.Printf("Project %q %sd.\n", positional[0], sub)
+		return todo.SaveProjects(ctx, projects, projPath)
+
+	case "switch":
+		if err := want(1); err != nil {
+			return err
+		}
+		if projects, err = todo.SwitchProject(projects, positional[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Now using project %q.\n", projects.Current)
+		return todo.SaveProjects(ctx, projects, projPath)
+
+	case "migrate":
+		if err := want(0); err != nil {
+			return err
+		}
+		var moved int
+		projects, items, moved = todo.Migrate(projects, items)
+		if err := todo.Save(ctx, items, outPath); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "projects migrated", "moved", moved, "path", outPath)
+		fmt.Printf("Moved %d item(s) into project %q.\n", moved, todo.DefaultList)
+		return todo.SaveProjects(ctx, projects, projPath)
+
+	default:
+		projectsUsage()
+		return fmt.Errorf("unknown projects subcommand %q", sub)
+	}
+}
+
+// printProjects prints every project with its item count; * marks the current one.
+func printProjects(p todo.Projects, items []todo.Item) {
+	counts := map[string]int{}
+	for _, it := range items {
+		counts[todo.ListOf(it)]++
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\tNAME\tITEMS\tARCHIVED\tDESCRIPTION")
+	for _, pr := range p.Lists {
+		mark := ""
+		if pr.Name == p.Current {
+			mark = "*"
+		}
+		archived := "no"
+		if pr.Archived {
+			archived = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", mark, pr.Name, counts[pr.Name], archived, pr.Description)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,127 @@
+package cli_app
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"todo-app/todo"
+)
+
+// TestCLI_Projects_CreateSwitchAddAndFilter verifies that items are added to
+// the current project, that -list only shows that project, and that -in
+// overrides it.
+// It uses an isolated temporary working directory for the test.
+func TestCLI_Projects_CreateSwitchAddAndFilter(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	app := New()
+	ctx := context.Background()
+	run := func(args ...string) string {
+		t.Helper()
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, args)
+		out := getOutput()
+		if err != nil {
+			t.Fatalf("Run(%v): %v", args, err)
+		}
+		return out
+	}
+
+	run("-add", "Buy milk")
+	run("projects", "create", "work", "-desc", "day job")
+	run("projects", "switch", "work")
+	run("-add", "Write report")
+
+	out := run("-list")
+	if !strings.Contains(out, "Write report") || strings.Contains(out, "Buy milk") {
+		t.Fatalf("-list should only show the work list:\n%s", out)
+	}
+	out = run("-list", "-in", "default")
+	if !strings.Contains(out, "Buy milk") || strings.Contains(out, "Write report") {
+		t.Fatalf("-in default should only show default:\n%s", out)
+	}
+	out = run("-list", "-in", "*")
+	if !strings.Contains(out, "Buy milk") || !strings.Contains(out, "Write report") {
+		t.Fatalf("-in * should show everything:\n%s", out)
+	}
+
+	run("projects", "rename", "work", "office")
+	list := readTodos(t, "todos.json")
+	if todo.ListOf(list[1]) != "office" {
+		t.Fatalf("rename did not move items: %+v", list)
+	}
+
+	run("projects", "switch", "default")
+	run("projects", "archive", "office")
+	if err := app.Run(ctx, []string{"-add", "More work", "-in", "office"}); err == nil {
+		t.Fatalf("adding to an archived project should fail")
+	}
+
+	out = run("projects")
+	if !strings.Contains(out, "office") || !strings.Contains(out, "yes") {
+		t.Fatalf("projects output missing archived office:\n%s", out)
+	}
+}
+
+// TestCLI_Projects_Migrate moves a legacy data file into the default project.
+// It uses an isolated temporary working directory for the test.
+func TestCLI_Projects_Migrate(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	_ = os.MkdirAll("out", 0o755)
+	legacy := `[{"id":1,"description":"old task","status":"started","created_at":"2024-01-01T00:00:00Z"}]`
+	if err := os.WriteFile("out/todos.json", []byte(legacy), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	getOutput := captureStdout(t)
+	err = New().Run(context.Background(), []string{"projects", "migrate"})
+	out := getOutput()
+	if err != nil || !strings.Contains(out, "Moved 1 item") {
+		t.Fatalf("migrate err=%v out=%s", err, out)
+	}
+	list := readTodos(t, "todos.json")
+	if len(list) != 1 || list[0].List != todo.DefaultList {
+		t.Fatalf("after migrate: %+v", list)
+	}
+	if _, err := os.Stat("out/todos.projects.json"); err != nil {
+		t.Fatalf("registry not written: %v", err)
+	}
+}
+
+// TestCLI_Projects_ListsAlias checks the old "lists" name still works, with a
+// warning naming the new command, and that its help is the projects help.
+func TestCLI_Projects_ListsAlias(t *testing.T) {
+	inTempDir(t)
+	errOut := captureStderr(t)
+	getOutput := captureStdout(t)
+	err := New().Run(context.Background(), []string{"lists", "create", "home"})
+	out := getOutput()
+	if err != nil || !strings.Contains(out, `Created project "home"`) {
+		t.Fatalf("lists create: err=%v out=%s", err, out)
+	}
+	if want := "warning: lists is deprecated; use: todo projects create home"; !strings.Contains(errOut.String(), want) {
+		t.Fatalf("stderr %q, want %q", errOut.String(), want)
+	}
+	if inv, err := Parse([]string{"help", "lists"}); err != nil || inv.helpFor != "projects" {
+		t.Fatalf("help lists: %+v %v", inv, err)
+	}
+}
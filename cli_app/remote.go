@@ -0,0 +1,129 @@
+package cli_app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"todo-app/client"
+	"todo-app/todo"
+)
+
+//
+// cli_app/remote.go (package cli_app)
+// -----------------------------------
+// Remote mode: with -server <url> (or TODO_SERVER) item commands and batch
+// go through the HTTP API via package client instead of reading and writing
+// out/todos.json, so the CLI and a running server share one store. Output is
+// printed by the same functions as local mode. TODO_TOKEN supplies an API key
+// and TODO_USER the dev-mode user. The trace id in ctx travels as a header.
+// The current project stays a local setting: without -in, remote commands use
+// the one in the projects file beside -out, as local commands do. `projects`,
+// `sync` and imports from other tools have no routes and stay local only.
+//
+
+// errProjectsRemote is returned for `projects` in remote mode: the server
+// has no projects routes; its registry is managed on the server's machine.
+var errProjectsRemote = errors.New("projects: not available with -server; run `todo projects switch` without -server to set the current project, or use -in <project>")
+
+// newRemote builds the API client for server.
+func newRemote(server string) (*client.Client, error) {
+	return client.New(client.Config{
+		BaseURL: server,
+		Token:   strings.TrimSpace(os.Getenv("TODO_TOKEN")),
+		User:    strings.TrimSpace(os.Getenv("TODO_USER")),
+	})
+}
+
+// remoteTarget resolves in for remote mode as loadLocal does locally: empty
+// means the current project of the projects file for outPath.
+func remoteTarget(ctx context.Context, outPath, in string) (string, error) {
+	if in != "" {
+		return in, nil
+	}
+	projects, err := todo.LoadProjects(ctx, todo.ProjectsPath(outPath))
+	if err != nil {
+		return "", err
+	}
+	return projects.Current, nil
+}
+
+// runRemote performs cmd against the server. Like local mode, mutations
+// print every item afterwards. cmd.in must already be resolved (see
+// remoteTarget).
+func runRemote(ctx context.Context, c *client.Client, cmd itemCmd) error {
+	switch {
+	case cmd.list:
+		items, err := c.List(ctx, client.Query{List: cmd.in})
+		if err != nil {
+			return err
+		}
+		return cmd.out.print(filterStatus(items, cmd.filter))
+	case cmd.desc != "":
+		if _, err := c.Add(ctx, client.NewItem{Description: cmd.desc, Status: cmd.status, List: cmd.in}); err != nil {
+			slog.ErrorContext(ctx, "add failed", "error", err)
+			return err
+		}
+	case cmd.updateID > 0:
+		if _, err := c.Update(ctx, cmd.updateID, client.Update{Description: cmd.newDesc}); err != nil {
+			slog.ErrorContext(ctx, "update failed", "error", err)
+			return err
+		}
+	case cmd.statusID > 0:
+		if _, err := c.Update(ctx, cmd.statusID, client.Update{Status: cmd.newStatus}); err != nil {
+			slog.ErrorContext(ctx, "status update failed", "error", err)
+			return err
+		}
+	case len(cmd.deleteIDs) > 0:
+		for _, id := range cmd.deleteIDs {
+			if err := c.Delete(ctx, id); err != nil {
+				slog.ErrorContext(ctx, "delete failed", "error", err)
+				return err
+			}
+		}
+	default:
+		return usagef("nothing to do")
+	}
+	items, err := c.List(ctx, client.Query{})
+	if err != nil {
+		return err
+	}
+	return cmd.out.print(items)
+}
+
+// runRemoteBatch sends ops to the server's batch route and prints the
+// per-operation results like local mode. Creates without a list go to in,
+// which must already be resolved.
+func runRemoteBatch(ctx context.Context, c *client.Client, ops []todo.Op, in string, atomic bool) error {
+	for i := range ops {
+		if ops[i].Op == todo.OpCreate && ops[i].List == "" {
+			ops[i].List = in
+		}
+	}
+	res, err := c.Batch(ctx, ops, atomic)
+	if res.Results == nil {
+		return err
+	}
+	results := make([]todo.OpResult, len(res.Results))
+	failed := 0
+	for i, r := range res.Results {
+		results[i] = todo.OpResult{Index: r.Index, Op: r.Op, Item: r.Item}
+		if r.Error != "" {
+			results[i].Err = errors.New(r.Error)
+			failed++
+		}
+	}
+	printBatchResults(results)
+	if err != nil {
+		slog.ErrorContext(ctx, "atomic batch failed; nothing written", "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "batch applied", "ops", len(ops), "failed", failed, "server", true)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d operations failed", failed, len(ops))
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package cli_app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"todo-app/api_app"
+	"todo-app/todo"
+	"todo-app/trace"
+)
+
+// createdCol matches the CREATED column so outputs from two runs compare equal.
+var createdCol = regexp.MustCompile(`\d{4}-\d\d-\d\dT\S+`)
+
+// TestCLI_Remote_MatchesLocalOutput runs the same commands locally and
+// against an API server and expects identical output, with the CLI's trace
+// id reaching the server and nothing written under ./out in remote mode.
+func TestCLI_Remote_MatchesLocalOutput(t *testing.T) {
+	tmp := t.TempDir()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	var mu sync.Mutex
+	var traceIDs []string
+	serverData := filepath.Join(t.TempDir(), "todos.json")
+	h := api_app.New(serverData).Handler()
+	// The server checks lists against the registry beside its data file,
+	// as local mode does.
+	projects, _, _ := todo.CreateProject(todo.DefaultProjects(), "work", "")
+	if err := todo.SaveProjects(context.Background(), projects, todo.ProjectsPath(serverData)); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceIDs = append(traceIDs, r.Header.Get(trace.Header))
+		mu.Unlock()
+		h.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+
+	app := New()
+	ctx, _ := trace.NewWithID(context.Background(), "cli-remote-1")
+	run := func(args ...string) string {
+		t.Helper()
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, args)
+		out := getOutput()
+		if err != nil {
+			t.Fatalf("Run(%v): %v", args, err)
+		}
+		return createdCol.ReplaceAllString(out, "<created>")
+	}
+	commands := [][]string{
+		{"-add", "Buy milk", "-status", "started"},
+		{"-add", "Write report", "-in", "work"},
+		{"-list"},
+		{"-list", "-in", "*"},
+		{"-update", "1", "-newdesc", "Buy oat milk"},
+		{"-delete", "2"},
+		{"add", "Call mum", "-in", "work"},
+		{"status", "1", "completed"},
+		{"list", "-all", "-status", "completed"},
+		{"export", "-all"},
+		{"rm", "1", "2"},
+	}
+
+	var local []string
+	run("projects", "create", "work") // local mode checks the list exists
+	for _, c := range commands {
+		local = append(local, run(c...))
+	}
+	if err := os.RemoveAll("out"); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	for i, c := range commands {
+		remote := run(append([]string{"-server", ts.URL}, c...)...)
+		if remote != local[i] {
+			t.Fatalf("%v: remote output differs\nlocal:\n%s\nremote:\n%s", c, local[i], remote)
+		}
+	}
+	if _, err := os.Stat("out"); !os.IsNotExist(err) {
+		t.Fatalf("remote mode touched ./out: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(traceIDs) == 0 {
+		t.Fatalf("server saw no requests")
+	}
+	for _, id := range traceIDs {
+		if id != "cli-remote-1" {
+			t.Fatalf("server saw trace id %q, want cli-remote-1", id)
+		}
+	}
+}
+
+// TestCLI_Remote_EnvBatchAndErrors covers TODO_SERVER, batch over the API,
+// server errors surfacing with the local exit codes, and projects being
+// refused in remote mode.
+func TestCLI_Remote_EnvBatchAndErrors(t *testing.T) {
+	ts := httptest.NewServer(api_app.New(filepath.Join(t.TempDir(), "todos.json")).Handler())
+	defer ts.Close()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	t.Setenv("TODO_SERVER", ts.URL)
+	t.Setenv("TODO_USER", "alice")
+
+	app := New()
+	ctx := context.Background()
+	stdin = strings.NewReader(`{"op":"create","description":"a"}
+{"op":"delete","id":42}`)
+	t.Cleanup(func() { stdin = os.Stdin })
+	getOutput := captureStdout(t)
+	err := app.Run(ctx, []string{"batch"})
+	out := getOutput()
+	if err == nil || !strings.Contains(out, "no to-do with id 42") {
+		t.Fatalf("batch err=%v out=%s", err, out)
+	}
+
+	getOutput = captureStdout(t)
+	err = app.Run(ctx, []string{"-delete", "42"})
+	getOutput()
+	if err == nil || !strings.Contains(err.Error(), "no to-do with id 42") {
+		t.Fatalf("delete missing id err=%v", err)
+	}
+	// A missing id exits as it does locally, and so does an unknown list.
+	for _, args := range [][]string{
+		{"-delete", "42"}, {"rm", "42"}, {"edit", "42", "x"}, {"status", "42", "completed"},
+		{"add", "x", "-in", "nosuch"},
+	} {
+		want := ExitNotFound
+		if args[0] == "add" {
+			want = ExitInvalid
+		}
+		getOutput = captureStdout(t)
+		err := app.Run(ctx, args)
+		getOutput()
+		if code := ExitCode(err); code != want {
+			t.Fatalf("%v: exit code %d (%v), want %d", args, code, err, want)
+		}
+	}
+	if err := app.Run(ctx, []string{"projects"}); !errors.Is(err, errProjectsRemote) {
+		t.Fatalf("projects in remote mode err=%v", err)
+	}
+	if err := app.Run(ctx, []string{"--server", "not a url", "-list"}); err == nil {
+		t.Fatalf("invalid --server accepted")
+	}
+}
+
+// TestCLI_Remote_CurrentProject checks that remote commands without -in use
+// the current project set locally, as local commands do, for list, add,
+// export, import and batch.
+func TestCLI_Remote_CurrentProject(t *testing.T) {
+	inTempDir(t)
+	serverData := filepath.Join(t.TempDir(), "todos.json")
+	projects, _, _ := todo.CreateProject(todo.DefaultProjects(), "work", "")
+	if err := todo.SaveProjects(context.Background(), projects, todo.ProjectsPath(serverData)); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(api_app.New(serverData).Handler())
+	defer ts.Close()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+
+	app := New()
+	ctx := context.Background()
+	run := func(args ...string) string {
+		t.Helper()
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, args)
+		out := getOutput()
+		if err != nil {
+			t.Fatalf("Run(%v): %v", args, err)
+		}
+		return out
+	}
+	run("projects", "create", "work")
+	run("projects", "switch", "work")
+	t.Cleanup(func() { stdin = os.Stdin })
+
+	run("-server", ts.URL, "add", "Plan sprint")
+	stdin = strings.NewReader("Ship it\n")
+	run("-server", ts.URL, "import", "-format", "todotxt")
+	stdin = strings.NewReader(`{"op":"create","description":"Review"}`)
+	run("-server", ts.URL, "batch")
+
+	want := []string{"Plan sprint", "Ship it", "Review"}
+	for _, args := range [][]string{{"list"}, {"list", "-in", "work"}, {"export"}} {
+		out := run(append([]string{"-server", ts.URL}, args...)...)
+		for _, w := range want {
+			if !strings.Contains(out, w) {
+				t.Fatalf("%v: %q missing from\n%s", args, w, out)
+			}
+		}
+	}
+	if out := run("-server", ts.URL, "list", "-in", todo.DefaultList); strings.Contains(out, "Plan sprint") {
+		t.Fatalf("item added to the default project:\n%s", out)
+	}
+}
@@ -0,0 +1,148 @@
+package cli_app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"reflect"
+	"time"
+
+	"todo-app/todo"
+)
+
+//
+// cli_app/sync.go (package cli_app)
+// ---------------------------------
+// `todo sync TODO.md`: two-way sync of a list with a Markdown checklist
+// (see todo/sync.go for the merge). The state of the last sync is kept
+// beside the data file; -watch runs a round every -interval, like `watch`.
+//
+
+// errSyncRemote is returned for `sync` in remote mode: the merge needs the
+// data file to be saved together with the sync state.
+var errSyncRemote = errors.New("sync: not available with -server; sync against the local data file")
+
+// runSync syncs once, or with -watch until ctx is done.
+func (a *CLI_App) runSync(ctx context.Context, inv *Invocation) error {
+	if inv.remote != nil {
+		return errSyncRemote
+	}
+	switch inv.opts.prefer {
+	case "", todo.SideFile, todo.SideStore:
+	default:
+		return usagef("sync: -prefer must be %q or %q", todo.SideFile, todo.SideStore)
+	}
+	if inv.opts.watch && inv.opts.interval <= 0 {
+		return usagef("sync: -interval must be positive")
+	}
+	s := syncer{outPath: normalizeOutPath(inv.opts.out), file: inv.args[0], in: inv.opts.in, prefer: inv.opts.prefer}
+	if !inv.opts.watch {
+		res, err := s.sync(ctx)
+		if err != nil {
+			return err
+		}
+		return s.report(res)
+	}
+	return s.watch(ctx, inv.opts.interval)
+}
+
+// syncer syncs one checklist file with one list of a data file.
+type syncer struct {
+	outPath, file, in, prefer string
+}
+
+// sync runs one round: merge, then save whichever sides changed and the
+// new state.
+func (s syncer) sync(ctx context.Context) (todo.SyncResult, error) {
+	list, projects, target, err := loadLocal(ctx, s.outPath, s.in)
+	if err != nil {
+		return todo.SyncResult{}, err
+	}
+	if err := projects.CheckWritable(target); err != nil {
+		return todo.SyncResult{}, err
+	}
+	doc, err := os.ReadFile(s.file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return todo.SyncResult{}, err
+	}
+	statePath := todo.SyncStatePath(s.outPath, s.file, target)
+	state, err := todo.LoadSyncState(ctx, statePath)
+	if err != nil {
+		return todo.SyncResult{}, err
+	}
+
+	res, err := todo.SyncChecklist(list, string(doc), state.Items, todo.SyncOptions{List: target, Prefer: s.prefer})
+	if err != nil {
+		return todo.SyncResult{}, fmt.Errorf("sync %s: %w", s.file, err)
+	}
+	if res.Store != (todo.SyncStats{}) {
+		if err := todo.Save(ctx, res.List, s.outPath); err != nil {
+			return todo.SyncResult{}, err
+		}
+	}
+	if res.Doc != string(doc) {
+		if err := todo.SaveChecklist(ctx, s.file, res.Doc); err != nil {
+			return todo.SyncResult{}, err
+		}
+	}
+	if state.SyncedAt.IsZero() || !reflect.DeepEqual(state.Items, res.Base) {
+		state = todo.SyncState{File: s.file, List: target, SyncedAt: time.Now().UTC(), Items: res.Base}
+		if err := todo.SaveSyncState(ctx, state, statePath); err != nil {
+			return todo.SyncResult{}, err
+		}
+	}
+	if !res.Changed() && len(res.Conflicts) == 0 {
+		return res, nil
+	}
+	slog.InfoContext(ctx, "checklist synced", "file", s.file, "list", target,
+		"store", res.Store.String(), "checklist", res.File.String(), "conflicts", len(res.Conflicts))
+	return res, nil
+}
+
+// report prints what a round did and turns conflicts into the error that
+// sets the exit code.
+func (s syncer) report(res todo.SyncResult) error {
+	fmt.Printf("%s: list %s, file %s\n", s.file, res.Store, res.File)
+	for _, c := range res.Conflicts {
+		fmt.Printf("conflict: %s\n", c)
+	}
+	if n := len(res.Conflicts); n > 0 {
+		return fmt.Errorf("sync: %d conflict(s) left as they are; edit one side to match, or rerun with -prefer file|store: %w", n, todo.ErrConflict)
+	}
+	return nil
+}
+
+// watch syncs every interval until ctx is done. A round with nothing to
+// do writes nothing, so only real changes on either side are reported.
+// Conflicts are reported when they change and watching goes on; a failure
+// on the first round is returned, later ones logged.
+func (s syncer) watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var reported []todo.Conflict
+	for first := true; ; first = false {
+		res, err := s.sync(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && first:
+			return err
+		case err != nil:
+			slog.WarnContext(ctx, "sync failed", "error", err, "file", s.file)
+		case first || res.Changed() || !reflect.DeepEqual(res.Conflicts, reported):
+			if !first {
+				fmt.Printf("\n-- synced at %s --\n", time.Now().Format(time.TimeOnly))
+			}
+			_ = s.report(res)
+			reported = res.Conflicts
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package cli_app
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-app/todo"
+)
+
+// TestCLI_Sync syncs a checklist with a list, applies edits from both
+// sides, reports a conflict with its exit code and settles it with -prefer.
+func TestCLI_Sync(t *testing.T) {
+	inTempDir(t)
+	app := New()
+	ctx := context.Background()
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		getOutput := captureStdout(t)
+		err := app.Run(ctx, args)
+		return getOutput(), err
+	}
+	mustRun := func(args ...string) string {
+		t.Helper()
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("Run(%v): %v\n%s", args, err, out)
+		}
+		return out
+	}
+	readDoc := func() string {
+		t.Helper()
+		b, err := os.ReadFile("TODO.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	writeDoc := func(doc string) {
+		t.Helper()
+		if err := os.WriteFile("TODO.md", []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mustRun("projects", "create", "repo")
+	mustRun("add", "-in", "repo", "Write docs")
+	mustRun("add", "Not in the repo list")
+	writeDoc("# Tasks\n\n- [ ] Fix CI\n")
+
+	out := mustRun("sync", "TODO.md", "-in", "repo")
+	if !strings.Contains(out, "TODO.md: list 1 added, 0 updated, 0 removed, file 1 added, 0 updated, 0 removed") {
+		t.Fatalf("first sync output:\n%s", out)
+	}
+	if got, want := readDoc(), "# Tasks\n\n- [ ] Fix CI <!-- id:3 -->\n- [ ] Write docs <!-- id:1 -->\n"; got != want {
+		t.Fatalf("TODO.md:\n%s\nwant:\n%s", got, want)
+	}
+	if list := readTodos(t, "todos.json"); len(list) != 3 || list[2].Description != "Fix CI" || list[2].List != "repo" {
+		t.Fatalf("store after first sync: %+v", list)
+	}
+
+	// Edits on both sides meet.
+	writeDoc(strings.Replace(readDoc(), "- [ ] Fix CI", "- [x] Fix CI", 1))
+	mustRun("edit", "1", "Write the docs")
+	mustRun("sync", "TODO.md", "-in", "repo")
+	if doc := readDoc(); !strings.Contains(doc, "- [ ] Write the docs <!-- id:1 -->") {
+		t.Fatalf("store edit not in the file:\n%s", doc)
+	}
+	if list := readTodos(t, "todos.json"); list[2].Status != todo.StatusCompleted {
+		t.Fatalf("file edit not in the store: %+v", list)
+	}
+
+	// Both sides rename item 1: a conflict, nothing overwritten.
+	writeDoc(strings.Replace(readDoc(), "Write the docs", "Write user docs", 1))
+	mustRun("edit", "1", "Write API docs")
+	out, err := run("sync", "TODO.md", "-in", "repo")
+	if ExitCode(err) != ExitConflict || !strings.Contains(out, `conflict: item 1: changed on both sides (description: file "Write user docs", store "Write API docs")`) {
+		t.Fatalf("conflict: err=%v\n%s", err, out)
+	}
+	if !strings.Contains(readDoc(), "Write user docs") || readTodos(t, "todos.json")[0].Description != "Write API docs" {
+		t.Fatal("a conflict overwrote one side")
+	}
+	mustRun("sync", "TODO.md", "-in", "repo", "-prefer", "file")
+	if readTodos(t, "todos.json")[0].Description != "Write user docs" {
+		t.Fatal("-prefer file did not settle the conflict")
+	}
+
+	if _, err := run("sync", "TODO.md", "-prefer", "newest"); ExitCode(err) != ExitUsage {
+		t.Fatalf("-prefer newest: %v", err)
+	}
+	if _, err := run("sync"); ExitCode(err) != ExitUsage {
+		t.Fatalf("sync without a file: %v", err)
+	}
+}
+
+// TestCLI_Sync_Watch runs sync -watch and checks a checklist edit reaches
+// the store and a store edit reaches the checklist.
+func TestCLI_Sync_Watch(t *testing.T) {
+	inTempDir(t)
+	app := New()
+	if err := os.WriteFile("TODO.md", []byte("- [ ] Buy milk\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	getOutput := captureStdout(t)
+	done := make(chan error, 1)
+	go func() { done <- app.Run(ctx, []string{"sync", "TODO.md", "-watch", "-interval", "10ms"}) }()
+
+	waitFor := func(what string, ok func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(2 * time.Second); !ok(); time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+		}
+	}
+	doc := func() string { b, _ := os.ReadFile("TODO.md"); return string(b) }
+	waitFor("the first sync", func() bool { return strings.Contains(doc(), "<!-- id:1 -->") })
+
+	// Save the way editors do, so a round never reads a half-written file.
+	if err := todo.SaveChecklist(context.Background(), "TODO.md", "- [x] Buy milk <!-- id:1 -->\n"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the checklist edit", func() bool {
+		list, _ := todo.Load(context.Background(), "out/todos.json")
+		return len(list) == 1 && list[0].Status == todo.StatusCompleted
+	})
+
+	list, _ := todo.Load(context.Background(), "out/todos.json")
+	list, _, _ = todo.Add(list, "Walk dog", todo.StatusNotStarted)
+	if err := todo.Save(context.Background(), list, "out/todos.json"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the store edit", func() bool { return strings.Contains(doc(), "- [ ] Walk dog <!-- id:2 -->") })
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("sync -watch: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("sync -watch did not stop after cancel")
+	}
+	if out := getOutput(); !strings.Contains(out, "-- synced at") {
+		t.Fatalf("watch output:\n%s", out)
+	}
+}
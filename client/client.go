@@ -0,0 +1,347 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-app/idempotency"
+	"todo-app/todo"
+	"todo-app/trace"
+)
+
+//
+// client/client.go (package client)
+// ---------------------------------
+// Typed Go client for the HTTP API. Each call:
+//   - carries the context's trace id in trace.Header (one is started if the
+//     context has none), so server spans join the caller's trace;
+//   - retries transport errors, 5xx and 429 with exponential backoff,
+//     honouring Retry-After; mutating calls send one Idempotency-Key across
+//     all attempts so a retried write is applied once;
+//   - stops waiting as soon as ctx is cancelled;
+//   - turns non-2xx answers into *APIError (see errors.go).
+//
+
+// UserHeader names the calling user when the server runs without API keys
+// (httpapi.UserHeader).
+const UserHeader = "X-User-ID"
+
+// Defaults applied by New when the Config leaves them zero.
+const (
+	DefaultMaxRetries  = 3
+	DefaultBaseBackoff = 200 * time.Millisecond
+	DefaultMaxBackoff  = 5 * time.Second
+	DefaultTimeout     = 30 * time.Second
+)
+
+// Config configures a Client. Only BaseURL is required.
+type Config struct {
+	// BaseURL is the server root, e.g. http://localhost:8080.
+	BaseURL string
+	// Token is a bearer API key; leave empty for a dev server (TODO_AUTH=off).
+	Token string
+	// User is sent as UserHeader; it only matters without API keys.
+	User string
+	// HTTPClient sends the requests (one with DefaultTimeout when nil).
+	HTTPClient *http.Client
+	// MaxRetries is how many times a failed call is retried
+	// (DefaultMaxRetries when zero; negative disables retries).
+	MaxRetries int
+	// BaseBackoff and MaxBackoff bound the exponential wait between attempts.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Client calls the to-do API. It is safe for concurrent use.
+type Client struct {
+	cfg  Config
+	base *url.URL
+}
+
+// New validates cfg and fills in defaults.
+func New(cfg Config) (*Client, error) {
+	base, err := url.Parse(strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q: want http(s)://host[:port]", cfg.BaseURL)
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	switch {
+	case cfg.MaxRetries == 0:
+		cfg.MaxRetries = DefaultMaxRetries
+	case cfg.MaxRetries < 0:
+		cfg.MaxRetries = 0
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = DefaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	return &Client{cfg: cfg, base: base}, nil
+}
+
+// NewItem is the input of Add. Status defaults to not started and List to
+// the default list.
+type NewItem struct {
+	Description string      `json:"description"`
+	Status      todo.Status `json:"status,omitempty"`
+	List        string      `json:"list,omitempty"`
+}
+
+// Query filters List. The zero value returns every item.
+type Query struct {
+	// List limits the result to one named list; "*" or empty means all.
+	List string
+}
+
+// Update is the input of Update; empty fields are left unchanged.
+type Update struct {
+	Description string      `json:"description,omitempty"`
+	Status      todo.Status `json:"status,omitempty"`
+}
+
+// Add creates an item and returns it as stored.
+func (c *Client) Add(ctx context.Context, item NewItem) (todo.Item, error) {
+	var out todo.Item
+	err := c.do(ctx, http.MethodPost, "/add", nil, item, &out)
+	return out, err
+}
+
+// Get returns the item with id; a missing id matches ErrNotFound.
+func (c *Client) Get(ctx context.Context, id int) (todo.Item, error) {
+	var out todo.Item
+	err := c.do(ctx, http.MethodGet, "/get", url.Values{"id": {strconv.Itoa(id)}}, nil, &out)
+	return out, err
+}
+
+// List returns the caller's items matching q.
+func (c *Client) List(ctx context.Context, q Query) ([]todo.Item, error) {
+	var params url.Values
+	if l := strings.TrimSpace(q.List); l != "" && l != "*" {
+		params = url.Values{"list": {l}}
+	}
+	var out []todo.Item
+	err := c.do(ctx, http.MethodGet, "/todos", params, nil, &out)
+	return out, err
+}
+
+// Update changes the description and/or status of item id and returns it; a
+// missing id matches ErrNotFound.
+func (c *Client) Update(ctx context.Context, id int, u Update) (todo.Item, error) {
+	req := struct {
+		ID int `json:"id"`
+		Update
+	}{id, u}
+	var out todo.Item
+	err := c.do(ctx, http.MethodPost, "/update", nil, req, &out)
+	return out, err
+}
+
+// Delete removes item id; a missing id matches ErrNotFound.
+func (c *Client) Delete(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodPost, "/delete", nil, struct {
+		ID int `json:"id"`
+	}{id}, nil)
+}
+
+// OpResult is the outcome of one batch operation.
+type OpResult struct {
+	Index int         `json:"index"`
+	Op    todo.OpKind `json:"op"`
+	// Status is the HTTP status this operation alone would have returned.
+	Status int        `json:"status"`
+	Item   *todo.Item `json:"item,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// BatchResult is the answer to Batch.
+type BatchResult struct {
+	// Applied reports whether the store was written.
+	Applied bool       `json:"applied"`
+	Error   string     `json:"error,omitempty"`
+	Results []OpResult `json:"results"`
+}
+
+// Batch applies ops in one store write (see todo.ApplyBatch). Per-operation
+// failures are reported in the result, not as an error; a failed atomic
+// batch returns both the result and an error matching ErrInvalid.
+func (c *Client) Batch(ctx context.Context, ops []todo.Op, atomic bool) (BatchResult, error) {
+	req := struct {
+		Atomic bool      `json:"atomic"`
+		Ops    []todo.Op `json:"ops"`
+	}{atomic, ops}
+	var out BatchResult
+	err := c.do(ctx, http.MethodPost, "/todos:batch", nil, req, &out)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity {
+		_ = json.Unmarshal(apiErr.Body, &out)
+	}
+	return out, err
+}
+
+// ImportResult is the answer to Import.
+type ImportResult struct {
+	Imported int         `json:"imported"`
+	Items    []todo.Item `json:"items"`
+}
+
+// Import adds items with new ids in one all-or-nothing write, keeping their
+// status, priority, dates and list (see todo.Import). A non-empty list
+// overrides each item's list.
+func (c *Client) Import(ctx context.Context, items []todo.Item, list string) (ImportResult, error) {
+	params := url.Values{"format": {"json"}}
+	if list != "" {
+		params.Set("list", list)
+	}
+	if items == nil {
+		items = []todo.Item{}
+	}
+	var out ImportResult
+	err := c.do(ctx, http.MethodPost, "/todos:import", params, items, &out)
+	return out, err
+}
+
+// do sends one logical call, retrying as described in the file header, and
+// decodes a successful JSON answer into out (when non-nil).
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+	u := *c.base
+	u.Path += path
+	u.RawQuery = params.Encode()
+
+	ctx, span := trace.Start(ctx, "client "+method+" "+path, "http.request.method", method, "url.path", path)
+	defer span.End()
+	tid, _ := trace.From(ctx)
+
+	var idemKey string
+	if method != http.MethodGet {
+		idemKey = newKey()
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var wait time.Duration
+		wait, err = c.attempt(ctx, method, u.String(), body, tid, idemKey, out)
+		if err == nil || wait < 0 || attempt >= c.cfg.MaxRetries {
+			break
+		}
+		if d := c.backoff(attempt); d > wait {
+			wait = d
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			err = ctx.Err()
+		case <-t.C:
+			continue
+		}
+		break
+	}
+	span.SetError(err)
+	return err
+}
+
+// attempt sends the request once. On failure it returns the minimum wait
+// before a retry (the server's Retry-After, if any), or a negative wait when
+// the error is final.
+func (c *Client) attempt(ctx context.Context, method, rawURL string, body []byte, tid, idemKey string, out any) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+	if c.cfg.User != "" {
+		req.Header.Set(UserHeader, c.cfg.User)
+	}
+	if tid != "" {
+		req.Header.Set(trace.Header, tid)
+	}
+	if idemKey != "" {
+		req.Header.Set(idempotency.Header, idemKey)
+	}
+
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return -1, ctxErr
+		}
+		return 0, err // connection refused, reset, timeout: try again
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode < 300 {
+		if out == nil || resp.StatusCode == http.StatusNoContent {
+			return 0, nil
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return -1, fmt.Errorf("decode %s response: %w", req.URL.Path, err)
+		}
+		return 0, nil
+	}
+
+	apiErr := &APIError{StatusCode: resp.StatusCode, TraceID: resp.Header.Get(trace.Header), Body: data}
+	var eb struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &eb) == nil && eb.Error != "" {
+		apiErr.Message = eb.Error
+	} else {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		apiErr.RetryAfter = time.Duration(secs) * time.Second
+	}
+	if !apiErr.retryable() {
+		return -1, apiErr
+	}
+	return apiErr.RetryAfter, apiErr
+}
+
+// backoff is the wait after failed attempt n (0-based): BaseBackoff doubled
+// per attempt up to MaxBackoff, with jitter so clients do not retry in step.
+func (c *Client) backoff(n int) time.Duration {
+	d := c.cfg.BaseBackoff
+	for i := 0; i < n && d < c.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, c.cfg.MaxBackoff)
+	return d/2 + rand.N(d/2+1)
+}
+
+// newKey returns a random Idempotency-Key for one logical call.
+func newKey() string {
+	var b [16]byte
+	_, _ = cryptorand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
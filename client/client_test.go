@@ -0,0 +1,278 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"todo-app/api_app"
+	"todo-app/idempotency"
+	"todo-app/service"
+	"todo-app/todo"
+	"todo-app/trace"
+)
+
+// newAPIServer starts the real API in an isolated working directory and
+// records the trace id header of every request it receives.
+func newAPIServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+
+	var mu sync.Mutex
+	var seen []string
+	h := api_app.New("out/todos.json").Handler()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get(trace.Header))
+		mu.Unlock()
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), seen...)
+	}
+}
+
+// createLists registers named lists for user on the server started by
+// newAPIServer, which only adds items to lists that exist.
+func createLists(t *testing.T, user string, names ...string) {
+	t.Helper()
+	path := service.NewActorStoreFactory("out/todos.json").PathFor(user)
+	projects := todo.DefaultProjects()
+	for _, n := range names {
+		var err error
+		if projects, _, err = todo.CreateProject(projects, n, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := todo.SaveProjects(context.Background(), projects, todo.ProjectsPath(path)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestClient_CRUDAgainstServer exercises every call against api_app.
+func TestClient_CRUDAgainstServer(t *testing.T) {
+	ts, traces := newAPIServer(t)
+	c, err := New(Config{BaseURL: ts.URL, User: "alice"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, _ := trace.NewWithID(context.Background(), "client-test-1")
+	createLists(t, "alice", "work")
+
+	milk, err := c.Add(ctx, NewItem{Description: "Buy milk"})
+	if err != nil || milk.ID != 1 || milk.Status != todo.StatusNotStarted {
+		t.Fatalf("Add = %+v, %v", milk, err)
+	}
+	if _, err := c.Add(ctx, NewItem{Description: "Write report", Status: todo.StatusStarted, List: "work"}); err != nil {
+		t.Fatalf("Add work: %v", err)
+	}
+
+	got, err := c.Get(ctx, milk.ID)
+	if err != nil || got.Description != "Buy milk" {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+	all, err := c.List(ctx, Query{})
+	if err != nil || len(all) != 2 {
+		t.Fatalf("List all = %+v, %v", all, err)
+	}
+	work, err := c.List(ctx, Query{List: "work"})
+	if err != nil || len(work) != 1 || work[0].Description != "Write report" {
+		t.Fatalf("List work = %+v, %v", work, err)
+	}
+
+	upd, err := c.Update(ctx, milk.ID, Update{Description: "Buy oat milk", Status: todo.StatusCompleted})
+	if err != nil || upd.Description != "Buy oat milk" || upd.Status != todo.StatusCompleted {
+		t.Fatalf("Update = %+v, %v", upd, err)
+	}
+	if err := c.Delete(ctx, milk.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	_, err = c.Get(ctx, milk.ID)
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.TraceID != "client-test-1" {
+		t.Fatalf("Get after delete err=%v (%+v), want ErrNotFound with the trace id", err, apiErr)
+	}
+	if _, err := c.Add(ctx, NewItem{}); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Add empty err=%v, want ErrInvalid", err)
+	}
+
+	for i, tid := range traces() {
+		if tid != "client-test-1" {
+			t.Fatalf("request %d carried trace id %q", i, tid)
+		}
+	}
+
+	// Another user sees nothing of alice's.
+	bob, _ := New(Config{BaseURL: ts.URL, User: "bob"})
+	if items, err := bob.List(context.Background(), Query{}); err != nil || len(items) != 0 {
+		t.Fatalf("bob sees %+v, %v", items, err)
+	}
+}
+
+// TestClient_RetriesServerErrorsWithOneIdempotencyKey verifies 5xx answers
+// are retried, every attempt of one call reuses the Idempotency-Key, and a
+// fresh trace id is started when the context has none.
+func TestClient_RetriesServerErrorsWithOneIdempotencyKey(t *testing.T) {
+	var calls atomic.Int32
+	var mu sync.Mutex
+	keys := map[string]int{}
+	var traceIDs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys[r.Header.Get(idempotency.Header)]++
+		traceIDs = append(traceIDs, r.Header.Get(trace.Header))
+		mu.Unlock()
+		if calls.Add(1) <= 2 {
+			http.Error(w, `{"error":"store unavailable"}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":7,"description":"x","status":"not started","created_at":"2024-01-01T00:00:00Z"}`))
+	}))
+	defer ts.Close()
+
+	c, _ := New(Config{BaseURL: ts.URL, BaseBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+	it, err := c.Add(context.Background(), NewItem{Description: "x"})
+	if err != nil || it.ID != 7 {
+		t.Fatalf("Add = %+v, %v", it, err)
+	}
+	if calls.Load() != 3 || len(keys) != 1 {
+		t.Fatalf("calls=%d keys=%v, want 3 attempts with one key", calls.Load(), keys)
+	}
+	if traceIDs[0] == "" || traceIDs[0] != traceIDs[2] {
+		t.Fatalf("trace ids across attempts: %q", traceIDs)
+	}
+
+	// With retries disabled the 5xx surfaces as ErrServer.
+	calls.Store(0)
+	c, _ = New(Config{BaseURL: ts.URL, MaxRetries: -1})
+	if _, err := c.Add(context.Background(), NewItem{Description: "x"}); !errors.Is(err, ErrServer) || calls.Load() != 1 {
+		t.Fatalf("no retries: err=%v calls=%d", err, calls.Load())
+	}
+}
+
+// TestClient_DoesNotRetryClientErrors verifies 4xx answers fail at once.
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"API key k1 lacks \"write\" scope"}`))
+	}))
+	defer ts.Close()
+
+	c, _ := New(Config{BaseURL: ts.URL, Token: "todo_x.y", BaseBackoff: time.Millisecond})
+	err := c.Delete(context.Background(), 1)
+	if !errors.Is(err, ErrForbidden) || calls.Load() != 1 {
+		t.Fatalf("err=%v calls=%d, want one ErrForbidden", err, calls.Load())
+	}
+	if err.Error() != `API key k1 lacks "write" scope (HTTP 403)` {
+		t.Fatalf("message = %q", err.Error())
+	}
+}
+
+// TestClient_ContextCancelsRetryWait verifies a long Retry-After does not
+// outlive the caller's context.
+func TestClient_ContextCancelsRetryWait(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	c, _ := New(Config{BaseURL: ts.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.List(ctx, Query{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("List waited %v despite the deadline", time.Since(start))
+	}
+}
+
+// TestClient_NewValidatesURL rejects URLs without a scheme or host.
+func TestClient_NewValidatesURL(t *testing.T) {
+	for _, bad := range []string{"", "localhost:8080", "ftp://host", "http://"} {
+		if _, err := New(Config{BaseURL: bad}); err == nil {
+			t.Fatalf("New(%q) accepted", bad)
+		}
+	}
+	if _, err := New(Config{BaseURL: "http://localhost:8080/"}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+}
+
+// TestClient_Batch reports per-op results, and the results of a failed
+// atomic batch alongside its error.
+func TestClient_Batch(t *testing.T) {
+	ts, _ := newAPIServer(t)
+	c, _ := New(Config{BaseURL: ts.URL})
+	ctx := context.Background()
+
+	res, err := c.Batch(ctx, []todo.Op{
+		{Op: todo.OpCreate, Description: "a"},
+		{Op: todo.OpDelete, ID: 99},
+	}, false)
+	if err != nil || !res.Applied || len(res.Results) != 2 || res.Results[0].Item == nil || res.Results[1].Error == "" {
+		t.Fatalf("partial batch = %+v, %v", res, err)
+	}
+
+	res, err = c.Batch(ctx, []todo.Op{
+		{Op: todo.OpCreate, Description: "b"},
+		{Op: todo.OpDelete, ID: 99},
+	}, true)
+	if !errors.Is(err, ErrInvalid) || res.Applied || len(res.Results) != 2 || res.Results[1].Error == "" {
+		t.Fatalf("atomic batch = %+v, %v", res, err)
+	}
+	if items, _ := c.List(ctx, Query{}); len(items) != 1 {
+		t.Fatalf("failed atomic batch changed the store: %+v", items)
+	}
+}
+
+// TestClient_Import adds items with their extra fields through
+// /todos:import and checks a bad item adds nothing.
+func TestClient_Import(t *testing.T) {
+	ts, _ := newAPIServer(t)
+	c, _ := New(Config{BaseURL: ts.URL})
+	ctx := context.Background()
+	createLists(t, service.DefaultUser, "home")
+
+	due := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	res, err := c.Import(ctx, []todo.Item{
+		{Description: "a", Priority: "A", Due: due},
+		{Description: "b", Status: todo.StatusCompleted},
+	}, "home")
+	if err != nil || res.Imported != 2 || res.Items[0].Priority != "A" || !res.Items[0].Due.Equal(due) ||
+		res.Items[1].CompletedAt.IsZero() || res.Items[1].List != "home" {
+		t.Fatalf("Import = %+v, %v", res, err)
+	}
+	if _, err := c.Import(ctx, []todo.Item{{Description: "c"}, {Description: " "}}, ""); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("bad import err = %v", err)
+	}
+	if items, _ := c.List(ctx, Query{}); len(items) != 2 {
+		t.Fatalf("failed import changed the store: %+v", items)
+	}
+}
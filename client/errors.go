@@ -0,0 +1,82 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"todo-app/todo"
+)
+
+//
+// client/errors.go (package client)
+// ---------------------------------
+// Typed errors for failed API calls. Every non-2xx answer becomes an
+// *APIError; match the kind of failure with errors.Is against the sentinels
+// below, or errors.As to read the status, message and trace id.
+//
+
+// Sentinels matched (via errors.Is) by *APIError according to its status.
+// ErrNotFound is todo.ErrNotFound, so callers can handle a missing item the
+// same way whether the store is local or remote.
+var (
+	ErrNotFound     = todo.ErrNotFound
+	ErrInvalid      = errors.New("invalid request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// APIError is a non-2xx response from the server.
+type APIError struct {
+	StatusCode int
+	// Message is the server's {"error": ...} text, or the status text.
+	Message string
+	// TraceID identifies the request in the server's logs and traces.
+	TraceID string
+	// RetryAfter is the server's Retry-After hint, zero when absent.
+	RetryAfter time.Duration
+	// Body is the raw response, for routes whose errors carry details.
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// Is maps the status code onto the package sentinels.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrInvalid:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusRequestEntityTooLarge ||
+			e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// retryable reports whether the same request may succeed if sent again:
+// server errors, rate limiting, and an Idempotency-Key still in flight.
+func (e *APIError) retryable() bool {
+	switch {
+	case e.StatusCode >= 500, e.StatusCode == http.StatusTooManyRequests:
+		return true
+	case e.StatusCode == http.StatusConflict:
+		return e.RetryAfter > 0
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"todo-app/todo"
+)
+
+// TestClient_APIErrorIs maps statuses onto the sentinels and retryability.
+func TestClient_APIErrorIs(t *testing.T) {
+	cases := []struct {
+		status    int
+		retry     time.Duration
+		want      error
+		retryable bool
+	}{
+		{400, 0, ErrInvalid, false},
+		{413, 0, ErrInvalid, false},
+		{401, 0, ErrUnauthorized, false},
+		{403, 0, ErrForbidden, false},
+		{404, 0, todo.ErrNotFound, false},
+		{409, 0, ErrConflict, false},
+		{409, time.Second, ErrConflict, true}, // Idempotency-Key in flight
+		{429, time.Second, ErrRateLimited, true},
+		{502, 0, ErrServer, true},
+	}
+	for _, tc := range cases {
+		e := &APIError{StatusCode: tc.status, Message: "m", RetryAfter: tc.retry}
+		if !errors.Is(e, tc.want) {
+			t.Fatalf("%d should match %v", tc.status, tc.want)
+		}
+		if tc.want != ErrServer && errors.Is(e, ErrServer) {
+			t.Fatalf("%d should not match ErrServer", tc.status)
+		}
+		if e.retryable() != tc.retryable {
+			t.Fatalf("%d retryable=%v", tc.status, e.retryable())
+		}
+	}
+}
@@ -8,14 +8,20 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"todo-app/api_app"
 	"todo-app/trace"
 )
 
-// main is the entry point for the Todo API server.
+// main is the entry point for the Todo API server. It exits non-zero when
+// the server fails or shutdown could not confirm that all data was saved.
 func main() {
+	os.Exit(run())
+}
+
+// run sets up logging and tracing, then serves until SIGINT/SIGTERM.
+// It returns the process exit code so deferred cleanup still runs.
+func run() int {
 	// Logging (mirrors CLI style): JSON by default, text when LOGTEXT=1.
 	var handler slog.Handler
 
@@ -30,23 +36,33 @@ func main() {
 	logger := slog.New(handler).With(slog.String("trace_id", trace.GenerateID()))
 	slog.SetDefault(logger)
 
+	// Optional span export: TODO_TRACE_FILE=out/traces.jsonl writes OTLP/JSON lines.
+	if path := os.Getenv("TODO_TRACE_FILE"); path != "" {
+		exp, err := trace.NewFileExporter(path, "todo-api")
+		if err != nil {
+			slog.Error("trace exporter disabled", "error", err, "path", path)
+		} else {
+			trace.SetExporter(exp)
+			defer exp.Close()
+			slog.Info("exporting spans", "path", path)
+		}
+	}
+
 	// Build server from env and run.
-	s, addr := api_app.FromEnv()
+	s, addr, err := api_app.FromEnv()
+	if err != nil {
+		slog.Error("todo api configuration failed", "error", err)
+		return 1
+	}
 
 	slog.Info("todo api starting", "addr", addr)
-	// Graceful shutdown
+	// Graceful shutdown: Run drains requests and flushes the stores before returning.
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	// Run server in background.
-	done := make(chan struct{})
-	go func() {
-		if err := s.Run(ctx, addr); err != nil {
-			slog.Error("server exited with error", "error", err)
-		}
-		close(done)
-	}()
-
-	<-done
-	time.Sleep(50 * time.Millisecond) // small drain period for logs
+	if err := s.Run(ctx, addr); err != nil {
+		slog.Error("server exited with error", "error", err)
+		return 1
+	}
+	return 0
 }
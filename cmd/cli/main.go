@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 
 	// Local packages
@@ -19,73 +18,43 @@ import (
 // ----------------------
 // This is the executable entrypoint for Todo-App.
 // Responsibilities:
-//  1) Parse *global* flags that affect logging style and the TraceID before any subcommand runs.
+//  1) Parse the command line (cli_app.Parse) so the global flags that affect
+//     logging style and the TraceID are known before the command runs.
 //  2) Create a context that cancels on SIGINT (Ctrl+C).
 //  3) Generate or accept an external TraceID and attach it to all logs.
-//  4) Run the CLI logic (cli_app.CLI_App) while keeping the process alive until Ctrl+C.
+//  4) Run the command to completion and exit with a code that says how it
+//     went (cli_app.ExitCode). Long-running commands such as watch stop on
+//     Ctrl+C; -wait keeps the old wait-for-Ctrl+C behaviour for any command.
 //
 
-// stripGlobalFlags extracts global flags that must be handled before the CLI flagset.
-// Recognized flags:
-//   - -logtext / --logtext     -> switch from JSON logs to human-readable text logs
-//   - -traceid <val> / --traceid <val> / --traceid=<val>  -> provide an external trace id
-//
-// The function returns (remainingArgs, useTextLogger, providedTraceID).
-func stripGlobalFlags(args []string) (clean []string, useText bool, traceID string) {
-	clean = make([]string, 0, len(args))
-	for i := 0; i < len(args); i++ {
-		a := args[i]
-
-		// Boolean logging style flag
-		if a == "-logtext" || a == "--logtext" {
-			useText = true
-			continue
-		}
-
-		// Long form with equals: --traceid=VALUE
-		if strings.HasPrefix(a, "--traceid=") {
-			traceID = strings.TrimPrefix(a, "--traceid=")
-			continue
-		}
-
-		// Space-separated forms: -traceid VALUE or --traceid VALUE
-		if a == "-traceid" || a == "--traceid" {
-			if i+1 < len(args) {
-				traceID = args[i+1]
-				i++ // consume value
-			}
-			continue
-		}
-
-		// Unhandled: keep arg
-		clean = append(clean, a)
-	}
-	return clean, useText, traceID
-}
-
 func main() {
-	// 1) Parse global flags from os.Args. We do this *before* CLI flag parsing
-	// so we can configure logging (JSON vs text) and establish a TraceID early.
-	args, useText, providedTrace := stripGlobalFlags(os.Args[1:])
+	// 1) Parse the command line first; a bad one is reported before any
+	// logging is set up, with exit code 2 like the flag package.
+	inv, err := cli_app.Parse(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(cli_app.ExitCode(err))
+	}
 
 	// 2) Create a signal-aware context that is canceled on SIGINT (Ctrl+C).
-	//    This lets downstream code optionally react to cancellation when needed.
+	//    A one-shot command interrupted part way stops and exits with 130;
+	//    watch treats it as the normal way to stop.
 	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
 	// 3) Create a context that also carries a TraceID for end-to-end logging.
-	//    If the user provided one via -traceid/--traceid, we use it; otherwise we generate one.
+	//    If the user provided one via -traceid, we use it; otherwise we generate one.
 	var ctx context.Context
 	var traceID string
-	if providedTrace != "" {
-		ctx, traceID = trace.NewWithID(sigCtx, providedTrace)
+	if inv.TraceID != "" {
+		ctx, traceID = trace.NewWithID(sigCtx, inv.TraceID)
 	} else {
 		ctx, traceID = trace.New(sigCtx)
 	}
 
 	// Configure slog globally. We attach the trace_id so all logs include it.
 	var handler slog.Handler
-	if useText {
+	if inv.LogText {
 		handler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})
 	} else {
 		handler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})
@@ -93,31 +62,19 @@ func main() {
 	logger := slog.New(handler).With(slog.String("trace_id", traceID))
 	slog.SetDefault(logger)
 
-	// 4) Run the CLI in a goroutine so we can keep the process alive until Ctrl+C.
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- cli_app.New().Run(ctx, args)
-	}()
-
-	// Block the process until Ctrl+C (or SIGTERM).
-	<-sigCtx.Done()
-	fmt.Fprintln(os.Stderr, "Shutting down...")
-
-	// Cancel signal handlers (and context) then wait for CLI to end.
-	// If the CLI finished earlier, errCh already has a value; otherwise,
-	// the cancellation will prompt Run to return.
-	stop()
-	runErr := <-errCh
+	// 4) Run the command; it returns when done (or, for watch, on Ctrl+C).
+	runErr := cli_app.New().Exec(ctx, inv)
 	if runErr != nil {
 		slog.ErrorContext(ctx, "cli run failed", "error", runErr)
 		fmt.Fprintln(os.Stderr, runErr)
 	} else {
 		slog.InfoContext(ctx, "cli run completed")
+		if inv.Wait {
+			// The old behaviour: stay up until Ctrl+C (or SIGTERM).
+			<-sigCtx.Done()
+			fmt.Fprintln(os.Stderr, "Shutting down...")
+		}
 	}
-
-	// Graceful exit code: 1 if CLI returned an error, else 0.
-	if runErr != nil {
-		os.Exit(1)
-	}
-	os.Exit(0)
+	stop()
+	os.Exit(cli_app.ExitCode(runErr))
 }
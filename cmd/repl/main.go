@@ -0,0 +1,59 @@
+// cmd/repl/main.go
+// Main entry point for the interactive Todo REPL.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"todo-app/repl"
+	"todo-app/service"
+	"todo-app/trace"
+)
+
+// main runs the REPL and exits non-zero if the session or the final save failed.
+func main() {
+	os.Exit(run())
+}
+
+// run opens one ActorStore for the whole session and loops until Ctrl+D,
+// Ctrl+C, quit or SIGTERM. It returns the exit code so deferred cleanup runs.
+func run() int {
+	out := flag.String("out", "out/todos.json", "data file (kept under ./out)")
+	logtext := flag.Bool("logtext", false, "text logs instead of JSON")
+	flag.Parse()
+
+	// Same signal handling as the CLI: SIGINT/SIGTERM cancel ctx. In raw
+	// terminal mode Ctrl+C arrives as a key instead and ends the loop too.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, traceID := trace.New(sigCtx)
+
+	// Logs go to stderr at warning level so they do not interleave with the session.
+	opts := &slog.HandlerOptions{Level: slog.LevelWarn}
+	var handler slog.Handler = slog.NewJSONHandler(os.Stderr, opts)
+	if *logtext {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler).With(slog.String("trace_id", traceID)))
+
+	path := filepath.Join("out", filepath.Base(*out))
+	store := service.NewActorStore(path)
+	err := repl.Run(ctx, repl.NewSession(store, os.Stdout), os.Stdin, os.Stdout, filepath.Join("out", "repl_history"))
+	if closeErr := store.Close(); closeErr != nil {
+		slog.Error("saving todos failed", "error", closeErr, "path", path)
+		fmt.Fprintln(os.Stderr, closeErr)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+// cmd/tui/main.go
+// Main entry point for the full-screen Todo board.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"todo-app/service"
+	"todo-app/trace"
+	"todo-app/tui"
+)
+
+// main runs the board and exits non-zero if it could not start or failed.
+func main() {
+	os.Exit(run())
+}
+
+// run shows the board on the data file until q, Ctrl+C or SIGTERM. It
+// returns the exit code so deferred cleanup (restoring the terminal) runs.
+func run() int {
+	out := flag.String("out", "out/todos.json", "data file (kept under ./out)")
+	logPath := flag.String("log", "", "write logs to this file (the screen is owned by the board)")
+	logtext := flag.Bool("logtext", false, "text logs instead of JSON")
+	flag.Parse()
+
+	// Same signal handling as the CLI; in raw mode Ctrl+C arrives as a key.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, traceID := trace.New(sigCtx)
+
+	var logw io.Writer = io.Discard
+	if *logPath != "" {
+		f, err := os.OpenFile(*logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		logw = f
+	}
+	var handler slog.Handler = slog.NewJSONHandler(logw, nil)
+	if *logtext {
+		handler = slog.NewTextHandler(logw, nil)
+	}
+	slog.SetDefault(slog.New(handler).With(slog.String("trace_id", traceID)))
+
+	// A FileStore reads the file on every Load, so the board sees changes
+	// made by other processes.
+	path := filepath.Join("out", filepath.Base(*out))
+	if err := tui.Run(ctx, service.NewFileStore(path), path, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+//
+// httpapi/batch.go (package httpapi)
+// ----------------------------------
+// POST /todos:batch applies many create/update/delete operations with a
+// single store write:
+//
+//	{"atomic": true, "ops": [{"op":"create","description":"a"}, {"op":"delete","id":3}]}
+//
+// The response lists one HTTP-style status per operation. Atomic batches are
+// all-or-nothing (422, failed ops 4xx, the others 424); otherwise every
+// operation that can be applied is, and a partial failure answers 207.
+//
+
+// batchResult is one entry of the batch response.
+type batchResult struct {
+	Index  int         `json:"index"`
+	Op     todo.OpKind `json:"op"`
+	Status int         `json:"status"`
+	Item   *todo.Item  `json:"item,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// batchResponse is the body of every /todos:batch answer after decoding.
+type batchResponse struct {
+	Applied bool          `json:"applied"`
+	Error   string        `json:"error,omitempty"`
+	Results []batchResult `json:"results"`
+}
+
+// opStatus maps an operation outcome onto an HTTP status code.
+func opStatus(r todo.OpResult) int {
+	switch {
+	case r.Err == nil && r.Op == todo.OpCreate:
+		return http.StatusCreated
+	case r.Err == nil:
+		return http.StatusOK
+	case errors.Is(r.Err, todo.ErrSkipped):
+		return http.StatusFailedDependency
+	case errors.Is(r.Err, todo.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// Batch handler
+func batchHandler(stores service.StoreFactory, limits Limits) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
+		var req struct {
+			Atomic bool      `json:"atomic"`
+			Ops    []todo.Op `json:"ops"`
+		}
+		if !decodeBody(ctx, w, r, &req) {
+			return
+		}
+		// As in the CLI, a create into a missing or archived list rejects
+		// the whole batch before anything is applied.
+		for i, op := range req.Ops {
+			if op.Op != todo.OpCreate {
+				continue
+			}
+			if err := writableLists(ctx, stores, op.List); err != nil {
+				respondWritableErr(ctx, w, fmt.Errorf("op %d: %w", i, err))
+				return
+			}
+		}
+
+		var results []todo.OpResult
+		var batchErr error
+		err := service.Update(ctx, store, func(list []todo.Item) ([]todo.Item, error) {
+			var next []todo.Item
+			next, results, batchErr = todo.ApplyBatch(list, req.Ops, req.Atomic, limits.items()...)
+			if batchErr != nil {
+				return list, batchErr // nothing to write
+			}
+			return next, nil
+		})
+		if results == nil && batchErr != nil {
+			respondErr(ctx, w, http.StatusBadRequest, batchErr) // empty or oversized batch
+			return
+		}
+		if err != nil && !errors.Is(err, batchErr) {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp := batchResponse{Applied: batchErr == nil, Results: make([]batchResult, len(results))}
+		failed := 0
+		for i, res := range results {
+			out := batchResult{Index: res.Index, Op: res.Op, Status: opStatus(res), Item: res.Item}
+			if res.Err != nil {
+				out.Error = res.Err.Error()
+				failed++
+			}
+			resp.Results[i] = out
+		}
+		status := http.StatusOK
+		switch {
+		case batchErr != nil:
+			resp.Error = batchErr.Error()
+			status = http.StatusUnprocessableEntity
+		case failed > 0:
+			status = http.StatusMultiStatus
+		}
+		respondJSON(w, status, resp)
+	}
+}
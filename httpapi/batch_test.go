@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"todo-app/service"
+)
+
+// TestHTTPAPI_Batch_AtomicAndPartial checks the per-op statuses of
+// /todos:batch and that an atomic failure writes nothing.
+func TestHTTPAPI_Batch_AtomicAndPartial(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	stores := service.NewActorStoreFactory(filepath.Join(t.TempDir(), "todos.json"))
+	t.Cleanup(func() { _ = stores.Close() })
+	mux := http.NewServeMux()
+	RegisterWith(mux, stores, Options{})
+
+	batch := func(body string) (int, batchResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/todos:batch", strings.NewReader(body)))
+		var resp batchResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	count := func() int {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/todos", nil))
+		var items []json.RawMessage
+		_ = json.Unmarshal(w.Body.Bytes(), &items)
+		return len(items)
+	}
+
+	code, resp := batch(`{"ops":[{"op":"create","description":"a"},{"op":"create","description":"b"},{"op":"create","description":"c"}]}`)
+	if code != http.StatusOK || !resp.Applied || len(resp.Results) != 3 || resp.Results[2].Status != http.StatusCreated {
+		t.Fatalf("creates: %d %+v", code, resp)
+	}
+
+	code, resp = batch(`{"atomic":true,"ops":[{"op":"delete","id":1},{"op":"update","id":42,"status":"completed"}]}`)
+	if code != http.StatusUnprocessableEntity || resp.Applied {
+		t.Fatalf("atomic failure: %d %+v", code, resp)
+	}
+	if resp.Results[0].Status != http.StatusFailedDependency || resp.Results[1].Status != http.StatusNotFound {
+		t.Fatalf("atomic statuses: %+v", resp.Results)
+	}
+	if n := count(); n != 3 {
+		t.Fatalf("atomic failure wrote changes: %d items", n)
+	}
+
+	code, resp = batch(`{"ops":[{"op":"delete","id":1},{"op":"update","id":42,"status":"completed"}]}`)
+	if code != http.StatusMultiStatus || resp.Results[0].Status != http.StatusOK || resp.Results[1].Status != http.StatusNotFound {
+		t.Fatalf("partial: %d %+v", code, resp)
+	}
+	if n := count(); n != 2 {
+		t.Fatalf("partial batch: %d items, want 2", n)
+	}
+
+	if code, _ := batch(`{"ops":[]}`); code != http.StatusBadRequest {
+		t.Fatalf("empty batch: status=%d", code)
+	}
+}
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"todo-app/auth"
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+//
+// httpapi/calendar.go (package httpapi)
+// -------------------------------------
+// A subscribable iCalendar feed of the caller's items as VTODOs:
+//
+//	GET /calendar.ics?token=todo_<id>.<secret>&list=home
+//
+// Calendar apps cannot send an Authorization header, so the API key rides
+// in the URL. Only read-scoped keys are accepted there: a URL ends up in
+// app settings and sync logs, and revoking its key is how a subscription is
+// cut off. The usual bearer header works too.
+//
+
+// calendarToken turns ?token= into a bearer header for authn, which must
+// come next. An Authorization header, if sent, wins.
+func calendarToken(next CtxHandler) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		if tok := strings.TrimSpace(r.URL.Query().Get("token")); tok != "" && r.Header.Get("Authorization") == "" {
+			r = r.Clone(ctx)
+			r.Header.Set("Authorization", "Bearer "+tok)
+		}
+		next(ctx, w, r)
+	}
+}
+
+// Calendar handler
+func calendarHandler(stores service.StoreFactory) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		if key, ok := auth.FromContext(ctx); ok && key.Scope != auth.ScopeRead && r.URL.Query().Has("token") {
+			respondErr(ctx, w, http.StatusForbidden, fmt.Errorf("API key %s has %q scope; calendar URLs take read-only keys", key.ID, key.Scope))
+			return
+		}
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
+		list, err := store.Load(ctx)
+		if err != nil {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		var buf bytes.Buffer
+		if err := (todo.ICalCodec{}).Encode(&buf, todo.FilterByList(list, strings.TrimSpace(r.URL.Query().Get("list")))); err != nil {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		// Apps poll the feed; the ETag lets unchanged polls end in a 304.
+		sum := sha256.Sum256(buf.Bytes())
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+		w.Header().Set("Content-Type", todo.ICalCodec{}.ContentType())
+		w.Header().Set("Cache-Control", "private, no-cache")
+		http.ServeContent(w, r, "calendar.ics", time.Time{}, bytes.NewReader(buf.Bytes()))
+	}
+}
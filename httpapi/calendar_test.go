@@ -0,0 +1,92 @@
+package httpapi
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"todo-app/auth"
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+// TestHTTPAPI_Calendar_Feed subscribes with a read key in the URL, checks
+// the VTODOs, the list filter and ETag revalidation, and that write keys,
+// bad tokens and other users' keys do not get a feed of alice's items.
+func TestHTTPAPI_Calendar_Feed(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	dir := t.TempDir()
+	keys, err := auth.Open(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatalf("auth.Open: %v", err)
+	}
+	readTok, _, _ := keys.Create("calendar", "alice", auth.ScopeRead)
+	writeTok, _, _ := keys.Create("laptop", "alice", auth.ScopeWrite)
+	bobTok, _, _ := keys.Create("calendar", "bob", auth.ScopeRead)
+
+	stores := service.NewActorStoreFactory(filepath.Join(dir, "todos.json"))
+	t.Cleanup(func() { _ = stores.Close() })
+	createLists(t, stores, "alice", "home")
+	mux := http.NewServeMux()
+	RegisterWith(mux, stores, Options{Keys: keys})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/todos:import?format=todotxt",
+		strings.NewReader("(A) Pay rent due:2024-06-01\nWater plants list:home\n"))
+	r.Header.Set("Authorization", "Bearer "+writeTok)
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("seed import: %d %s", w.Code, w.Body.String())
+	}
+
+	feed := func(query string, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/calendar.ics"+query, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	w = feed("?token="+url.QueryEscape(readTok), nil)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/calendar") {
+		t.Fatalf("feed: %d %q %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	items, err := todo.ICalCodec{}.Decode(w.Body)
+	if err != nil || len(items) != 2 || items[0].Description != "Pay rent" || items[0].Due.IsZero() || items[0].Priority != "A" {
+		t.Fatalf("feed items: %v %+v", err, items)
+	}
+
+	etag := w.Header().Get("ETag")
+	if w := feed("?token="+url.QueryEscape(readTok), http.Header{"If-None-Match": {etag}}); etag == "" || w.Code != http.StatusNotModified {
+		t.Fatalf("revalidation with %q: %d", etag, w.Code)
+	}
+	w = feed("?list=home&token="+url.QueryEscape(readTok), nil)
+	if items, _ := (todo.ICalCodec{}).Decode(w.Body); len(items) != 1 || items[0].List != "home" {
+		t.Fatalf("list filter: %+v", items)
+	}
+	// The usual header works too, with either scope.
+	if w := feed("", http.Header{"Authorization": {"Bearer " + writeTok}}); w.Code != http.StatusOK {
+		t.Fatalf("bearer header: %d", w.Code)
+	}
+
+	if w := feed("?token="+url.QueryEscape(writeTok), nil); w.Code != http.StatusForbidden {
+		t.Fatalf("write key in the URL: %d", w.Code)
+	}
+	for _, q := range []string{"", "?token=todo_bogus.token"} {
+		if w := feed(q, nil); w.Code != http.StatusUnauthorized {
+			t.Fatalf("feed%s: %d", q, w.Code)
+		}
+	}
+	w = feed("?token="+url.QueryEscape(bobTok), nil)
+	if items, _ := (todo.ICalCodec{}).Decode(w.Body); w.Code != http.StatusOK || len(items) != 0 {
+		t.Fatalf("bob's feed: %d %+v", w.Code, items)
+	}
+}
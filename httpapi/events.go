@@ -0,0 +1,124 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-app/service"
+)
+
+//
+// httpapi/events.go (package httpapi)
+// -----------------------------------
+// GET /events streams the caller's item changes as Server-Sent Events:
+//
+//	id: 1718000000000123
+//	event: updated
+//	data: {"id":...,"type":"updated","revision":7,"item":{...},"time":"..."}
+//
+// Reconnecting clients send Last-Event-ID (browsers do this automatically) and
+// receive the buffered events they missed. When the id is too old to resume
+// from, a "reset" event tells the client to reload the full list.
+//
+
+// sseHeartbeat keeps idle connections (and proxies) from timing out.
+const sseHeartbeat = 15 * time.Second
+
+// Events handler - Server-Sent Events change stream
+func eventsHandler(stores service.StoreFactory) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
+		pub, ok := store.(service.Publisher)
+		if !ok {
+			respondErr(ctx, w, http.StatusNotImplemented, fmt.Errorf("this store does not publish change events"))
+			return
+		}
+
+		// Last-Event-ID header, or ?lastEventId= for clients that cannot set headers.
+		rawID := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+		if rawID == "" {
+			rawID = strings.TrimSpace(r.URL.Query().Get("lastEventId"))
+		}
+		var lastID uint64
+		resume := rawID != ""
+		if resume {
+			id, err := strconv.ParseUint(rawID, 10, 64)
+			if err != nil {
+				respondErr(ctx, w, http.StatusBadRequest, fmt.Errorf("invalid Last-Event-ID %q", rawID))
+				return
+			}
+			lastID = id
+		}
+
+		sub, err := pub.Subscribe(ctx, lastID, resume)
+		if err != nil {
+			respondErr(ctx, w, http.StatusServiceUnavailable, err)
+			return
+		}
+		defer sub.Cancel()
+
+		rc := http.NewResponseController(w)
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("Connection", "keep-alive")
+		h.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprint(w, "retry: 2000\n\n")
+		if sub.Gap {
+			fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+		}
+		for _, ev := range sub.Backlog {
+			if writeSSE(w, ev) != nil {
+				return
+			}
+		}
+		if rc.Flush() != nil {
+			return
+		}
+
+		tick := time.NewTicker(sseHeartbeat)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, open := <-sub.Events:
+				if !open {
+					// store shutting down or we fell behind; the client reconnects
+					return
+				}
+				if writeSSE(w, ev) != nil {
+					return
+				}
+			case <-tick.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			}
+			if rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeSSE writes one event frame.
+func writeSSE(w io.Writer, ev service.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+	return err
+}
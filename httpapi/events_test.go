@@ -0,0 +1,162 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-app/service"
+)
+
+// sseFrame is one parsed Server-Sent Events frame.
+type sseFrame struct{ id, event, data string }
+
+// readFrames parses frames from r onto a channel until r ends.
+func readFrames(r io.Reader) <-chan sseFrame {
+	ch := make(chan sseFrame, 16)
+	go func() {
+		defer close(ch)
+		sc := bufio.NewScanner(r)
+		var f sseFrame
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case line == "":
+				if f.event != "" || f.data != "" {
+					ch <- f
+				}
+				f = sseFrame{}
+			case strings.HasPrefix(line, "id: "):
+				f.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				f.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				f.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return ch
+}
+
+// nextFrame waits for a frame or fails the test.
+func nextFrame(t *testing.T, ch <-chan sseFrame) sseFrame {
+	t.Helper()
+	select {
+	case f, ok := <-ch:
+		if !ok {
+			t.Fatalf("stream ended")
+		}
+		return f
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for SSE frame")
+	}
+	return sseFrame{}
+}
+
+// TestHTTPAPI_Events_StreamAndResume verifies that mutations show up on
+// /events, that Last-Event-ID replays missed events, and that the stream
+// ends when the store's subscribers are closed (server shutdown).
+func TestHTTPAPI_Events_StreamAndResume(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	stores := service.NewActorStoreFactory(filepath.Join(t.TempDir(), "todos.json"))
+	t.Cleanup(func() { _ = stores.Close() })
+	mux := http.NewServeMux()
+	RegisterWith(mux, stores, Options{})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	open := func(lastID string) (*http.Response, <-chan sseFrame) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/events", nil)
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET /events: %v", err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Content-Type=%q", ct)
+		}
+		return resp, readFrames(resp.Body)
+	}
+	post := func(path, body string) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, frames := open("")
+	post("/add", `{"description":"first"}`)
+	created := nextFrame(t, frames)
+	if created.event != "created" || !strings.Contains(created.data, `"first"`) || created.id == "" {
+		t.Fatalf("created frame = %+v", created)
+	}
+	resp.Body.Close() // client disconnects
+
+	post("/update", `{"id":1,"status":"completed"}`)
+	post("/delete", `{"id":1}`)
+
+	resp, frames = open(created.id)
+	defer resp.Body.Close()
+	if f := nextFrame(t, frames); f.event != "updated" || !strings.Contains(f.data, `"completed"`) {
+		t.Fatalf("replayed frame 1 = %+v", f)
+	}
+	if f := nextFrame(t, frames); f.event != "deleted" {
+		t.Fatalf("replayed frame 2 = %+v", f)
+	}
+
+	stores.CloseSubscribers()
+	select {
+	case _, ok := <-frames:
+		if ok {
+			t.Fatalf("unexpected frame after shutdown")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("stream did not end on shutdown")
+	}
+}
+
+// TestHTTPAPI_Events_StaleIDSendsReset checks that an unknown Last-Event-ID
+// yields a reset event instead of silently skipping changes.
+func TestHTTPAPI_Events_StaleIDSendsReset(t *testing.T) {
+	stores := service.NewActorStoreFactory(filepath.Join(t.TempDir(), "todos.json"))
+	t.Cleanup(func() { _ = stores.Close() })
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	mux := http.NewServeMux()
+	RegisterWith(mux, stores, Options{})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+	if f := nextFrame(t, readFrames(resp.Body)); f.event != "reset" {
+		t.Fatalf("first frame = %+v, want reset", f)
+	}
+}
+
+// TestHTTPAPI_Events_NotImplementedForPlainStore ensures stores without a
+// change feed answer 501 rather than hanging.
+func TestHTTPAPI_Events_NotImplementedForPlainStore(t *testing.T) {
+	mux := newMuxWithStore(&memStore{})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("status=%d want 501", w.Code)
+	}
+}
@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+//
+// httpapi/health.go (package httpapi)
+// -----------------------------------
+// Probe endpoints for supervisors and load balancers. They are never
+// authenticated and skip the request logger so frequent probes do not flood
+// the logs.
+//
+//	/healthz  the process is up and serving HTTP
+//	/readyz   every ReadinessCheck passes (503 otherwise)
+//	/version  build information from runtime/debug.ReadBuildInfo
+//
+
+// readyTimeout bounds each readiness check.
+const readyTimeout = 2 * time.Second
+
+// ReadinessCheck is one named readiness probe; a nil error means ready.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// startedAt is reported by /version as the process start time.
+var startedAt = time.Now()
+
+// registerHealth wires the probe routes.
+func registerHealth(mux *http.ServeMux, checks []ReadinessCheck) {
+	mux.HandleFunc("/healthz", withCtx(healthzHandler))
+	mux.HandleFunc("/readyz", withCtx(readyzHandler(checks)))
+	mux.HandleFunc("/version", withCtx(versionHandler))
+}
+
+// Healthz handler (liveness)
+func healthzHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyzResponse reports each check as "ok" or its error.
+type readyzResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Readyz handler (readiness)
+func readyzHandler(checks []ReadinessCheck) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		resp := readyzResponse{Status: "ok", Checks: map[string]string{}}
+		for _, c := range checks {
+			cctx, cancel := context.WithTimeout(ctx, readyTimeout)
+			err := c.Check(cctx)
+			cancel()
+			if err != nil {
+				resp.Status = "unavailable"
+				resp.Checks[c.Name] = err.Error()
+				continue
+			}
+			resp.Checks[c.Name] = "ok"
+		}
+		status := http.StatusOK
+		if resp.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		respondJSON(w, status, resp)
+	}
+}
+
+// versionInfo is the /version body.
+type versionInfo struct {
+	Module    string    `json:"module"`
+	Version   string    `json:"version"`
+	GoVersion string    `json:"go_version"`
+	Revision  string    `json:"vcs_revision,omitempty"`
+	Time      string    `json:"vcs_time,omitempty"`
+	Modified  bool      `json:"vcs_modified,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Version handler
+func versionHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	info := versionInfo{Version: "(unknown)", StartedAt: startedAt}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Module, info.Version, info.GoVersion = bi.Main.Path, bi.Main.Version, bi.GoVersion
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Revision = s.Value
+			case "vcs.time":
+				info.Time = s.Value
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	respondJSON(w, http.StatusOK, info)
+}
@@ -0,0 +1,37 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestHTTPAPI_Readyz_ReportsFailingChecks verifies that /readyz runs
+// every check, reports failures by name and gives each a deadline.
+func TestHTTPAPI_Readyz_ReportsFailingChecks(t *testing.T) {
+	h := readyzHandler([]ReadinessCheck{
+		{Name: "ok", Check: func(context.Context) error { return nil }},
+		{Name: "broken", Check: func(context.Context) error { return errors.New("disk on fire") }},
+		{Name: "bounded", Check: func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				return errors.New("no deadline")
+			}
+			return nil
+		}},
+	})
+	w := httptest.NewRecorder()
+	h(context.Background(), w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(body, "disk on fire") || !strings.Contains(body, `"bounded":"ok"`) {
+		t.Fatalf("status=%d body=%s", w.Code, body)
+	}
+
+	w = httptest.NewRecorder()
+	newMuxWithStore(&memStore{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("no checks should be ready, status=%d", w.Code)
+	}
+}
@@ -3,6 +3,7 @@ package httpapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
@@ -11,22 +12,75 @@ import (
 	"strings"
 	"time"
 
+	"todo-app/auth"
+	"todo-app/idempotency"
+	"todo-app/lists"
 	"todo-app/service"
 	"todo-app/todo"
 	"todo-app/trace"
+	"todo-app/webhook"
 )
 
 // CtxHandler defines a handler with context.
 type CtxHandler func(context.Context, http.ResponseWriter, *http.Request)
 
+// Options configures optional behaviour of the registered routes.
+// The zero value matches Register: no authentication.
+type Options struct {
+	// Keys enables bearer API key authentication when non-nil.
+	Keys *auth.Keyring
+	// Lists enables the shared list routes (/lists/...) when non-nil.
+	// Audit must then be set too; it records every membership change.
+	Lists *service.ListStore
+	Audit *lists.AuditLog
+	// Webhooks enables the webhook management routes (/webhooks/...) when non-nil.
+	Webhooks *webhook.Dispatcher
+	// Idempotency enables Idempotency-Key handling on mutating routes when non-nil.
+	Idempotency *idempotency.Store
+	// Readiness lists the checks behind /readyz; with none it always reports ready.
+	Readiness []ReadinessCheck
+	// Limits caps request rates and body sizes; the zero value only applies
+	// DefaultMaxBodyBytes.
+	Limits Limits
+}
+
+// UserHeader names the calling user in dev mode (no keyring, TODO_AUTH=off).
+// With authentication enabled the user always comes from the API key instead.
+const UserHeader = "X-User-ID"
+
 // Register wires routes onto the provided mux using the given store.
+// Every caller shares store; use RegisterWith for per-user isolation.
 func Register(mux *http.ServeMux, store service.Store) {
-	// Handlers with logging and context injection
-	mux.HandleFunc("/add", withCtx(logger(addHandler(store))))
-	mux.HandleFunc("/get", withCtx(logger(getHandler(store))))
-	mux.HandleFunc("/update", withCtx(logger(updateHandler(store))))
-	mux.HandleFunc("/delete", withCtx(logger(deleteHandler(store))))
-	mux.HandleFunc("/list", withCtx(logger(listHandler(store))))
+	RegisterWith(mux, service.SharedStore(store), Options{})
+}
+
+// RegisterWith wires routes onto mux like Register, resolving each request's
+// Store from stores by the calling user and applying opts.
+func RegisterWith(mux *http.ServeMux, stores service.StoreFactory, opts Options) {
+	// Handlers with logging, context injection and authentication/identity
+	mux.HandleFunc("/add", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, addHandler(stores, opts.Limits))))))
+	mux.HandleFunc("/get", withCtx(logger(authn(opts, auth.ScopeRead, getHandler(stores)))))
+	mux.HandleFunc("/update", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, updateHandler(stores, opts.Limits))))))
+	mux.HandleFunc("/delete", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, deleteHandler(stores))))))
+	mux.HandleFunc("/list", withCtx(logger(authn(opts, auth.ScopeRead, listHandler(stores)))))
+	mux.HandleFunc("/todos", withCtx(logger(authn(opts, auth.ScopeRead, todosHandler(stores)))))
+	mux.HandleFunc("/todos:batch", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, batchHandler(stores, opts.Limits))))))
+	mux.HandleFunc("/todos:export", withCtx(logger(authn(opts, auth.ScopeRead, exportHandler(stores)))))
+	mux.HandleFunc("/todos:import", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, importHandler(stores, opts.Limits))))))
+	mux.HandleFunc("/calendar.ics", withCtx(logger(calendarToken(authn(opts, auth.ScopeRead, calendarHandler(stores))))))
+	mux.HandleFunc("/events", withCtx(logger(authn(opts, auth.ScopeRead, eventsHandler(stores)))))
+	if opts.Lists != nil {
+		registerLists(mux, opts)
+	}
+	if opts.Webhooks != nil {
+		registerWebhooks(mux, opts)
+	}
+
+	registerHealth(mux, opts.Readiness)
+	// Scrapers send a read key when authentication is on; no request logging.
+	mux.HandleFunc("/metrics", withCtx(authn(opts, auth.ScopeRead, metricsHandler)))
+
+	registerDocs(mux)
 
 	// Serve static /about/ from ./static/about
 	mux.Handle("/about/", http.StripPrefix("/about/", http.FileServer(http.Dir("static/about"))))
@@ -36,14 +90,18 @@ func Register(mux *http.ServeMux, store service.Store) {
 }
 
 // Add handler
-func addHandler(store service.Store) CtxHandler {
+func addHandler(stores service.StoreFactory, limits Limits) CtxHandler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
 		var req struct {
 			Description string `json:"description"`
 			Status      string `json:"status"` // optional; default below
+			List        string `json:"list"`   // optional; default list when empty
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			respondErr(ctx, w, http.StatusBadRequest, err)
+		if !decodeBody(ctx, w, r, &req) {
 			return
 		}
 
@@ -59,6 +117,10 @@ func addHandler(store service.Store) CtxHandler {
 			rawStatus = "not started" // use whatever your app treats as the default
 		}
 		st := todo.Status(rawStatus)
+		if err := writableLists(ctx, stores, req.List); err != nil {
+			respondWritableErr(ctx, w, err)
+			return
+		}
 
 		list, err := store.Load(ctx)
 		if err != nil {
@@ -66,8 +128,8 @@ func addHandler(store service.Store) CtxHandler {
 			return
 		}
 
-		// NOTE: todo.Add(list, description, status)
-		list, item, err := todo.Add(list, desc, st)
+		// NOTE: todo.AddToList(list, listName, description, status)
+		list, item, err := todo.AddToList(list, strings.TrimSpace(req.List), desc, st, limits.items()...)
 		if err != nil {
 			respondErr(ctx, w, http.StatusBadRequest, err)
 			return
@@ -82,8 +144,12 @@ func addHandler(store service.Store) CtxHandler {
 }
 
 // Get handler
-func getHandler(store service.Store) CtxHandler {
+func getHandler(stores service.StoreFactory) CtxHandler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
 		// load list once
 		list, err := store.Load(ctx)
 		if err != nil {
@@ -108,16 +174,35 @@ func getHandler(store service.Store) CtxHandler {
 	}
 }
 
+// Todos handler - items as JSON, optionally filtered with ?list=<name>
+func todosHandler(stores service.StoreFactory) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
+		list, err := store.Load(ctx)
+		if err != nil {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, todo.FilterByList(list, strings.TrimSpace(r.URL.Query().Get("list"))))
+	}
+}
+
 // Update handler
-func updateHandler(store service.Store) func(context.Context, http.ResponseWriter, *http.Request) {
+func updateHandler(stores service.StoreFactory, limits Limits) func(context.Context, http.ResponseWriter, *http.Request) {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
 		var req struct {
 			ID          int    `json:"id"`
 			Description string `json:"description"`
 			Status      string `json:"status"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			respondErr(ctx, w, http.StatusBadRequest, err)
+		if !decodeBody(ctx, w, r, &req) {
 			return
 		}
 		list, err := store.Load(ctx)
@@ -126,9 +211,9 @@ func updateHandler(store service.Store) func(context.Context, http.ResponseWrite
 			return
 		}
 		if req.Description != "" {
-			list, err = todo.UpdateDescription(list, req.ID, strings.TrimSpace(req.Description))
+			list, err = todo.UpdateDescription(list, req.ID, strings.TrimSpace(req.Description), limits.items()...)
 			if err != nil {
-				respondErr(ctx, w, http.StatusBadRequest, err)
+				respondErr(ctx, w, itemErrStatus(err), err)
 				return
 			}
 		}
@@ -136,7 +221,7 @@ func updateHandler(store service.Store) func(context.Context, http.ResponseWrite
 		if req.Status != "" {
 			list, err = todo.UpdateStatus(list, req.ID, todo.Status(strings.TrimSpace(req.Status)))
 			if err != nil {
-				respondErr(ctx, w, http.StatusBadRequest, err)
+				respondErr(ctx, w, itemErrStatus(err), err)
 				return
 			}
 		}
@@ -154,13 +239,16 @@ func updateHandler(store service.Store) func(context.Context, http.ResponseWrite
 }
 
 // Delete handler
-func deleteHandler(store service.Store) CtxHandler {
+func deleteHandler(stores service.StoreFactory) CtxHandler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
 		var req struct {
 			ID int `json:"id"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			respondErr(ctx, w, http.StatusBadRequest, err)
+		if !decodeBody(ctx, w, r, &req) {
 			return
 		}
 		list, err := store.Load(ctx)
@@ -170,7 +258,7 @@ func deleteHandler(store service.Store) CtxHandler {
 		}
 		list, err = todo.Delete(list, req.ID)
 		if err != nil {
-			respondErr(ctx, w, http.StatusBadRequest, err)
+			respondErr(ctx, w, itemErrStatus(err), err)
 			return
 		}
 		if err := store.Save(ctx, list); err != nil {
@@ -182,8 +270,12 @@ func deleteHandler(store service.Store) CtxHandler {
 }
 
 // List handler - serves HTML page
-func listHandler(store service.Store) CtxHandler {
+func listHandler(stores service.StoreFactory) CtxHandler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
 		list, err := store.Load(ctx)
 		if err != nil {
 			respondErr(ctx, w, http.StatusInternalServerError, err)
@@ -191,22 +283,165 @@ func listHandler(store service.Store) CtxHandler {
 		}
 		tpl := template.Must(template.New("list").Parse(listTemplate))
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		_ = tpl.Execute(w, struct{ Items []todo.Item }{Items: list})
+		_ = tpl.Execute(w, struct {
+			User  string
+			Items []todo.Item
+		}{User: currentUser(ctx), Items: list})
 	}
 }
 
 // withCtx injects a TraceID and passes context to a functional handler.
+// A valid trace.Header from the caller is adopted so one trace spans both
+// processes; the id in use is echoed back in the same header.
 func withCtx(next func(context.Context, http.ResponseWriter, *http.Request)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		if _, ok := trace.From(ctx); !ok {
-			ctx, _ = trace.NewWithID(ctx, trace.GenerateID())
+		tid, ok := trace.From(ctx)
+		if !ok {
+			tid = strings.TrimSpace(r.Header.Get(trace.Header))
+			if !trace.ValidID(tid) {
+				tid = trace.GenerateID()
+			}
+			ctx, _ = trace.NewWithID(ctx, tid)
 			r = r.WithContext(ctx)
 		}
+		w.Header().Set(trace.Header, tid)
 		next(ctx, w, r)
 	}
 }
 
+// authn establishes who is calling before next runs. With a keyring it
+// requires a valid bearer API key granting scope and takes the user from the
+// key; with a nil keyring (development mode) the user comes from UserHeader.
+// It also applies opts.Limits: the per-IP rate limit before authenticating,
+// the per-key limit after, and the request body size cap.
+func authn(opts Options, scope auth.Scope, next CtxHandler) CtxHandler {
+	keys := opts.Keys
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		if !throttle(ctx, w, opts.Limits.PerIP, clientIP(r), "client") {
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, opts.Limits.maxBody())
+
+		if keys == nil {
+			user := strings.TrimSpace(r.Header.Get(UserHeader))
+			if user == "" {
+				user = service.DefaultUser
+			}
+			if err := service.ValidateUserID(user); err != nil {
+				respondErr(ctx, w, http.StatusBadRequest, err)
+				return
+			}
+			ctx = auth.WithUser(ctx, user)
+			next(ctx, w, r.WithContext(ctx))
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="todo"`)
+			respondErr(ctx, w, http.StatusUnauthorized, fmt.Errorf("missing bearer token"))
+			return
+		}
+		key, err := keys.Authenticate(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="todo", error="invalid_token"`)
+			respondErr(ctx, w, http.StatusUnauthorized, err)
+			return
+		}
+		if !key.Allows(scope) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="todo", error="insufficient_scope", scope=%q`, scope))
+			respondErr(ctx, w, http.StatusForbidden, fmt.Errorf("API key %s lacks %q scope", key.ID, scope))
+			return
+		}
+		if !throttle(ctx, w, opts.Limits.PerKey, key.ID, "API key") {
+			return
+		}
+		user := key.User
+		if user == "" {
+			user = service.DefaultUser // keys created before users existed
+		}
+		if span, ok := trace.SpanFrom(ctx); ok {
+			span.SetAttrs("auth.key_id", key.ID, "enduser.id", user)
+		}
+		ctx = auth.WithUser(auth.NewContext(ctx, key), user)
+		next(ctx, w, r.WithContext(ctx))
+	}
+}
+
+// currentUser returns the user authn placed in ctx (DefaultUser if none).
+func currentUser(ctx context.Context) string {
+	if u, ok := auth.UserFrom(ctx); ok {
+		return u
+	}
+	return service.DefaultUser
+}
+
+// userStore resolves the calling user's Store, responding with an error
+// (and returning false) if that is not possible.
+func userStore(ctx context.Context, w http.ResponseWriter, stores service.StoreFactory) (service.Store, bool) {
+	store, err := stores.For(ctx, currentUser(ctx))
+	if err != nil {
+		respondErr(ctx, w, http.StatusBadRequest, err)
+		return nil, false
+	}
+	return store, true
+}
+
+// writableLists fails unless the calling user can add items to every named
+// list ("" is the default list), as the CLI checks with
+// Projects.CheckWritable: the list must exist and not be archived; those
+// errors match todo.ErrInvalid. Factories without a registry accept any list.
+func writableLists(ctx context.Context, stores service.StoreFactory, names ...string) error {
+	reg, ok := stores.(service.ProjectRegistry)
+	if !ok {
+		return nil
+	}
+	projects, err := reg.ProjectsFor(ctx, currentUser(ctx))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name == "" {
+			name = todo.DefaultList
+		}
+		if err := projects.CheckWritable(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// respondWritableErr answers a writableLists error: 400 for a list that
+// cannot take items, 500 if the registry could not be read.
+func respondWritableErr(ctx context.Context, w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, todo.ErrInvalid) {
+		status = http.StatusBadRequest
+	}
+	respondErr(ctx, w, status, err)
+}
+
+// itemErrStatus maps an error from changing one item: 404 for an unknown
+// id, 400 otherwise.
+func itemErrStatus(err error) int {
+	if errors.Is(err, todo.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	h := strings.TrimSpace(r.Header.Get("Authorization"))
+	scheme, token, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
 // statusRecorder captures status/bytes for logging.
 type statusRecorder struct {
 	http.ResponseWriter
@@ -224,12 +459,29 @@ func (s *statusRecorder) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController (Flush etc.).
+func (s *statusRecorder) Unwrap() http.ResponseWriter { return s.ResponseWriter }
+
 // logger emits start/end logs with trace_id, method, path, status and duration.
+// It also opens the request's root span so store work nests underneath it.
 func logger(next CtxHandler) CtxHandler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 		tid, _ := trace.From(ctx)
 		start := time.Now()
 
+		// The span is named by route, not path, so ids in paths do not make
+		// a new span name per request; the path is an attribute.
+		route := requestRoute(r)
+		name := route
+		if !strings.Contains(route, " ") {
+			name = r.Method + " " + route
+		}
+		ctx, span := trace.Start(ctx, name,
+			"http.request.method", r.Method, "http.route", route, "url.path", r.URL.Path,
+		)
+		defer span.End()
+		r = r.WithContext(ctx)
+
 		sr := &statusRecorder{ResponseWriter: w, status: 200}
 		slog.InfoContext(ctx, "request start",
 			"method", r.Method, "path", r.URL.Path, "trace_id", tid,
@@ -238,6 +490,11 @@ func logger(next CtxHandler) CtxHandler {
 		next(ctx, sr, r)
 
 		dur := time.Since(start)
+		observeRequest(r, sr.status, dur.Seconds())
+		span.SetAttrs("http.response.status_code", sr.status, "http.response.body.size", sr.bytes)
+		if sr.status >= 500 {
+			span.SetError(fmt.Errorf("%s", http.StatusText(sr.status)))
+		}
 		fields := []any{
 			"status", sr.status, "bytes", sr.bytes, "duration_ms", dur.Milliseconds(),
 			"method", r.Method, "path", r.URL.Path, "trace_id", tid,
@@ -270,4 +527,4 @@ func respondErr(ctx context.Context, w http.ResponseWriter, status int, err erro
 	respondJSON(w, status, errResp{Error: err.Error()})
 }
 
-const listTemplate = "<!doctype html><html><head><meta charset=\"utf-8\"><title>Todos</title></head><body><h1>Todos</h1><ul>{{range .Items}}<li>{{.ID}} - {{.Description}} - {{.Status}}</li>{{else}}<li>none</li>{{end}}</ul></body></html>"
+const listTemplate = "<!doctype html><html><head><meta charset=\"utf-8\"><title>Todos</title></head><body><h1>Todos for {{.User}}</h1><ul>{{range .Items}}<li>{{.ID}} - {{.Description}} - {{.Status}}</li>{{else}}<li>none</li>{{end}}</ul></body></html>"
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -11,11 +12,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"todo-app/auth"
 	"todo-app/service"
 	"todo-app/todo"
+	"todo-app/trace"
 )
 
 // --- test helpers & fakes ---
@@ -278,6 +282,294 @@ func TestHTTPAPI_About_StaticRedirectAndFiles(t *testing.T) {
 	}
 }
 
+// createLists registers named lists for user beside their data file, so
+// writes into them pass the registry check.
+func createLists(t *testing.T, stores *service.ActorStoreFactory, user string, names ...string) {
+	t.Helper()
+	projects := todo.DefaultProjects()
+	for _, n := range names {
+		var err error
+		if projects, _, err = todo.CreateProject(projects, n, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := todo.SaveProjects(context.Background(), projects, todo.ProjectsPath(stores.PathFor(user))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// spanCollector is an in-memory trace.Exporter used to inspect spans.
+type spanCollector struct {
+	mu    sync.Mutex
+	spans []*trace.Span
+}
+
+func (c *spanCollector) Export(s *trace.Span) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spans = append(c.spans, s)
+	return nil
+}
+func (c *spanCollector) Close() error { return nil }
+
+// TestHTTPAPI_Logger_CreatesRequestSpan verifies that the logger middleware
+// opens a root span per request and that store spans nest underneath it.
+func TestHTTPAPI_Logger_CreatesRequestSpan(t *testing.T) {
+	c := &spanCollector{}
+	prev := trace.SetExporter(c)
+	t.Cleanup(func() { trace.SetExporter(prev) })
+
+	store := service.NewFileStore(filepath.Join(t.TempDir(), "todos.json"))
+	mux := newMuxWithStore(store)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/get", nil)
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d, want %d", w.Code, http.StatusOK)
+	}
+
+	var root, load *trace.Span
+	for _, s := range c.spans {
+		switch s.Name {
+		case "GET /get":
+			root = s
+		case "FileStore.Load":
+			load = s
+		}
+	}
+	if root == nil || load == nil {
+		t.Fatalf("missing spans; got %d", len(c.spans))
+	}
+	if load.ParentID != root.SpanID || load.TraceID != root.TraceID {
+		t.Fatalf("FileStore.Load not nested under request span")
+	}
+	var status any
+	for _, a := range root.Attrs() {
+		if a.Key == "http.response.status_code" {
+			status = a.Value
+		}
+	}
+	if status != http.StatusOK {
+		t.Fatalf("status attr=%v, want %d", status, http.StatusOK)
+	}
+}
+
+// TestHTTPAPI_Logger_SpanNamedByRoute checks the request span is named by
+// the matched pattern, with the raw path kept as an attribute.
+func TestHTTPAPI_Logger_SpanNamedByRoute(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	c := &spanCollector{}
+	prev := trace.SetExporter(c)
+	t.Cleanup(func() { trace.SetExporter(prev) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/items/{id}", withCtx(logger(func(ctx context.Context, w http.ResponseWriter, r *http.Request) {})))
+	mux.HandleFunc("POST /things/{id}", withCtx(logger(func(ctx context.Context, w http.ResponseWriter, r *http.Request) {})))
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/items/42", nil),
+		httptest.NewRequest(http.MethodGet, "/items/43", nil),
+		httptest.NewRequest(http.MethodPost, "/things/7", nil),
+	} {
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var names, paths []string
+	for _, s := range c.spans {
+		names = append(names, s.Name)
+		for _, a := range s.Attrs() {
+			if a.Key == "url.path" {
+				paths = append(paths, fmt.Sprint(a.Value))
+			}
+		}
+	}
+	if got := strings.Join(names, ","); got != "GET /items/{id},GET /items/{id},POST /things/{id}" {
+		t.Fatalf("span names: %s", got)
+	}
+	if got := strings.Join(paths, ","); got != "/items/42,/items/43,/things/7" {
+		t.Fatalf("url.path attrs: %s", got)
+	}
+}
+
+// TestHTTPAPI_Auth_EnforcesScopes verifies the authn middleware: missing or
+// bad tokens get 401, read keys cannot write (403), write keys can do both.
+func TestHTTPAPI_Auth_EnforcesScopes(t *testing.T) {
+	keys, err := auth.Open(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil {
+		t.Fatalf("auth.Open: %v", err)
+	}
+	readTok, _, _ := keys.Create("reader", "alice", auth.ScopeRead)
+	writeTok, _, _ := keys.Create("writer", "alice", auth.ScopeWrite)
+
+	store := &memStore{}
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	mux := http.NewServeMux()
+	RegisterWith(mux, service.SharedStore(store), Options{Keys: keys})
+
+	call := func(method, path, token, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := call(http.MethodGet, "/get", "", ""); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("no token: status=%d", w.Code)
+	}
+	if w := call(http.MethodGet, "/get", "todo_bogus.token", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("bad token: status=%d", w.Code)
+	}
+	if w := call(http.MethodGet, "/get", readTok, ""); w.Code != http.StatusOK {
+		t.Fatalf("read key GET: status=%d", w.Code)
+	}
+	if w := call(http.MethodPost, "/add", readTok, `{"description":"x"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("read key POST /add: status=%d", w.Code)
+	}
+	if w := call(http.MethodPost, "/add", writeTok, `{"description":"x"}`); w.Code != http.StatusCreated {
+		t.Fatalf("write key POST /add: status=%d body=%s", w.Code, w.Body.String())
+	}
+	if len(store.list) != 1 {
+		t.Fatalf("store has %d items, want 1", len(store.list))
+	}
+}
+
+// TestHTTPAPI_MultiUser_Isolation verifies that users never see or modify
+// each other's items, both in dev mode (X-User-ID) and with API keys.
+func TestHTTPAPI_MultiUser_Isolation(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	dir := t.TempDir()
+
+	keys, err := auth.Open(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatalf("auth.Open: %v", err)
+	}
+	aliceTok, _, _ := keys.Create("a", "alice", auth.ScopeWrite)
+	bobTok, _, _ := keys.Create("b", "bob", auth.ScopeWrite)
+
+	for _, mode := range []string{"header", "keys"} {
+		t.Run(mode, func(t *testing.T) {
+			stores := service.NewActorStoreFactory(filepath.Join(t.TempDir(), "todos.json"))
+			t.Cleanup(func() { _ = stores.Close() })
+			mux := http.NewServeMux()
+			opts := Options{}
+			if mode == "keys" {
+				opts.Keys = keys
+			}
+			RegisterWith(mux, stores, opts)
+
+			as := func(user, method, path, body string) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(method, path, strings.NewReader(body))
+				if mode == "keys" {
+					tok := map[string]string{"alice": aliceTok, "bob": bobTok}[user]
+					req.Header.Set("Authorization", "Bearer "+tok)
+				} else {
+					req.Header.Set(UserHeader, user)
+				}
+				mux.ServeHTTP(w, req)
+				return w
+			}
+
+			if w := as("alice", http.MethodPost, "/add", `{"description":"alice secret"}`); w.Code != http.StatusCreated {
+				t.Fatalf("alice add: status=%d body=%s", w.Code, w.Body.String())
+			}
+
+			var bobList []todo.Item
+			w := as("bob", http.MethodGet, "/get", "")
+			decodeJSON(t, w.Result(), &bobList)
+			if len(bobList) != 0 {
+				t.Fatalf("bob sees alice's items: %+v", bobList)
+			}
+			if w := as("bob", http.MethodGet, "/get?id=1", ""); w.Code != http.StatusNotFound {
+				t.Fatalf("bob get alice's id: status=%d", w.Code)
+			}
+			if w := as("bob", http.MethodPost, "/delete", `{"id":1}`); w.Code != http.StatusNotFound {
+				t.Fatalf("bob delete alice's id: status=%d", w.Code)
+			}
+			if w := as("bob", http.MethodGet, "/list", ""); strings.Contains(w.Body.String(), "alice secret") {
+				t.Fatalf("bob's /list shows alice's item")
+			}
+
+			var aliceList []todo.Item
+			w = as("alice", http.MethodGet, "/get", "")
+			decodeJSON(t, w.Result(), &aliceList)
+			if len(aliceList) != 1 || aliceList[0].Description != "alice secret" {
+				t.Fatalf("alice list=%+v", aliceList)
+			}
+			if w := as("alice", http.MethodGet, "/list", ""); !strings.Contains(w.Body.String(), "alice secret") {
+				t.Fatalf("alice's /list missing her item: %s", w.Body.String())
+			}
+		})
+	}
+
+	// A header cannot be used to impersonate another user once keys are on.
+	stores := service.NewActorStoreFactory(filepath.Join(dir, "todos.json"))
+	t.Cleanup(func() { _ = stores.Close() })
+	mux := http.NewServeMux()
+	RegisterWith(mux, stores, Options{Keys: keys})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(`{"description":"x"}`))
+	req.Header.Set("Authorization", "Bearer "+bobTok)
+	req.Header.Set(UserHeader, "alice")
+	mux.ServeHTTP(w, req)
+	st, _ := stores.For(context.Background(), "alice")
+	if list, _ := st.Load(context.Background()); len(list) != 0 {
+		t.Fatalf("X-User-ID overrode the key's user")
+	}
+}
+
+// TestHTTPAPI_DevMode_RejectsBadUserID ensures header ids are validated
+// before they are used to build a file path.
+func TestHTTPAPI_DevMode_RejectsBadUserID(t *testing.T) {
+	mux := newMuxWithStore(&memStore{})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/get", nil)
+	req.Header.Set(UserHeader, "../etc")
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status=%d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+// TestHTTPAPI_Todos_FilterByList verifies /todos?list= returns only the
+// items of that list while /todos returns everything.
+func TestHTTPAPI_Todos_FilterByList(t *testing.T) {
+	store := &memStore{}
+	mux := newMuxWithStore(store)
+
+	for _, body := range []string{
+		`{"description":"milk"}`,
+		`{"description":"report","list":"work"}`,
+		`{"description":"slides","list":"work"}`,
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("add %s: status=%d", body, w.Code)
+		}
+	}
+
+	get := func(path string) []todo.Item {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var list []todo.Item
+		decodeJSON(t, w.Result(), &list)
+		return list
+	}
+	if got := get("/todos?list=work"); len(got) != 2 || got[0].List != "work" {
+		t.Fatalf("/todos?list=work = %+v", got)
+	}
+	if got := get("/todos?list=default"); len(got) != 1 || got[0].Description != "milk" {
+		t.Fatalf("/todos?list=default = %+v", got)
+	}
+	if got := get("/todos"); len(got) != 3 {
+		t.Fatalf("/todos len=%d want 3", len(got))
+	}
+}
+
 // itoa is a tiny helper to avoid importing strconv in tests.
 func itoa(i int) string { return strconvItoa(i) }
 
@@ -315,3 +607,73 @@ func strconvItoa(i int) string {
 		return string(buf[n:])
 	}(i)
 }
+
+// TestHTTPAPI_TraceHeader_AdoptedAndEchoed verifies a caller's X-Trace-ID
+// becomes the request's trace id, and that one is generated otherwise.
+func TestHTTPAPI_TraceHeader_AdoptedAndEchoed(t *testing.T) {
+	mux := newMuxWithStore(&memStore{})
+
+	req := httptest.NewRequest(http.MethodGet, "/get", nil)
+	req.Header.Set(trace.Header, "cli-run-7")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if got := w.Header().Get(trace.Header); got != "cli-run-7" {
+		t.Fatalf("echoed trace id = %q, want cli-run-7", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/get", nil)
+	req.Header.Set(trace.Header, "not valid")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if got := w.Header().Get(trace.Header); len(got) != 32 {
+		t.Fatalf("invalid header should be replaced by a generated id, got %q", got)
+	}
+}
+
+// TestHTTPAPI_Writes_CheckListRegistry checks add, batch create and import
+// refuse lists missing from or archived in the user's registry, like the
+// CLI, and accept the default list and registered ones.
+func TestHTTPAPI_Writes_CheckListRegistry(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	stores := service.NewActorStoreFactory(filepath.Join(t.TempDir(), "todos.json"))
+	t.Cleanup(func() { _ = stores.Close() })
+	createLists(t, stores, service.DefaultUser, "work", "old")
+	path := todo.ProjectsPath(stores.PathFor(service.DefaultUser))
+	projects, _ := todo.LoadProjects(context.Background(), path)
+	projects, _ = todo.SetArchived(projects, "old", true)
+	if err := todo.SaveProjects(context.Background(), projects, path); err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	RegisterWith(mux, stores, Options{})
+	call := func(target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+		return w
+	}
+
+	for _, tc := range []struct {
+		target, body string
+		want         int
+	}{
+		{"/add", `{"description":"a"}`, http.StatusCreated},
+		{"/add", `{"description":"b","list":"work"}`, http.StatusCreated},
+		{"/add", `{"description":"c","list":"nosuch"}`, http.StatusBadRequest},
+		{"/add", `{"description":"d","list":"old"}`, http.StatusBadRequest},
+		{"/todos:batch", `{"ops":[{"op":"create","description":"e","list":"work"},{"op":"create","description":"f","list":"nosuch"}]}`, http.StatusBadRequest},
+		{"/todos:import?format=todotxt", "g list:old\n", http.StatusBadRequest},
+		{"/todos:import?format=todotxt&list=nosuch", "h\n", http.StatusBadRequest},
+		{"/todos:import?format=todotxt", "i list:work\n", http.StatusCreated},
+	} {
+		if w := call(tc.target, tc.body); w.Code != tc.want {
+			t.Fatalf("%s %s: %d, want %d: %s", tc.target, tc.body, w.Code, tc.want, w.Body.String())
+		}
+	}
+	if w := call("/todos:batch", `{"ops":[{"op":"create","description":"f","list":"nosuch"}]}`); !strings.Contains(w.Body.String(), "op 0: no project named") {
+		t.Fatalf("batch error: %s", w.Body.String())
+	}
+	list, _ := stores.For(context.Background(), service.DefaultUser)
+	if items, _ := list.Load(context.Background()); len(items) != 3 {
+		t.Fatalf("items after the refused writes: %+v", items)
+	}
+}
@@ -0,0 +1,122 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"todo-app/idempotency"
+	"todo-app/trace"
+)
+
+//
+// httpapi/idempotency.go (package httpapi)
+// ----------------------------------------
+// Idempotency-Key support for mutating routes. The first request with a key
+// runs normally and its response is remembered; retries with the same key and
+// payload get that response back (marked Idempotent-Replayed: true) without
+// running the handler again. Keys are scoped per user, method and path.
+//
+//	same key, same payload, finished   -> original response replayed
+//	same key, different payload        -> 422
+//	same key while the first is running -> 409
+//
+// 5xx responses are not remembered so the client can retry them.
+//
+
+// ReplayedHeader marks responses served from the idempotency store.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// perRequestHeaders describe one request rather than its result, so a replay
+// keeps the retry's own values instead of the first request's.
+var perRequestHeaders = []string{trace.Header, "Date", "Retry-After"}
+
+// idempotent wraps a mutating handler; with a nil store it is a no-op.
+// It must run inside authn so the key can be scoped to the caller.
+func idempotent(store *idempotency.Store, next CtxHandler) CtxHandler {
+	if store == nil {
+		return next
+	}
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get(idempotency.Header))
+		if key == "" {
+			next(ctx, w, r)
+			return
+		}
+		if err := idempotency.ValidateKey(key); err != nil {
+			respondErr(ctx, w, http.StatusBadRequest, err)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondBodyErr(ctx, w, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := currentUser(ctx) + " " + r.Method + " " + r.URL.Path
+		fp := idempotency.Fingerprint([]byte(r.URL.RawQuery), body)
+		prev, err := store.Begin(scope, key, fp)
+		switch {
+		case errors.Is(err, idempotency.ErrMismatch):
+			respondErr(ctx, w, http.StatusUnprocessableEntity, err)
+			return
+		case errors.Is(err, idempotency.ErrInFlight):
+			w.Header().Set("Retry-After", "1")
+			respondErr(ctx, w, http.StatusConflict, err)
+			return
+		case prev != nil:
+			if span, ok := trace.SpanFrom(ctx); ok {
+				span.SetAttr("http.idempotent_replay", true)
+			}
+			for k, v := range prev.Header {
+				w.Header()[k] = v
+			}
+			w.Header().Set(ReplayedHeader, "true")
+			w.WriteHeader(prev.Status)
+			_, _ = w.Write(prev.Body)
+			return
+		}
+
+		rec := &captureWriter{ResponseWriter: w, status: http.StatusOK}
+		stored := false
+		defer func() {
+			if !stored {
+				store.Abort(scope, key) // panic or 5xx: let the client retry
+			}
+		}()
+		next(ctx, rec, r)
+		if rec.status >= 500 {
+			return
+		}
+		header := w.Header().Clone()
+		for _, h := range perRequestHeaders {
+			header.Del(h)
+		}
+		store.Complete(scope, key, idempotency.Response{Status: rec.status, Header: header, Body: rec.buf.Bytes()})
+		stored = true
+	}
+}
+
+// captureWriter passes the response through while keeping a copy of it.
+type captureWriter struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (c *captureWriter) WriteHeader(code int) {
+	c.status = code
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *captureWriter) Write(b []byte) (int, error) {
+	c.buf.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (c *captureWriter) Unwrap() http.ResponseWriter { return c.ResponseWriter }
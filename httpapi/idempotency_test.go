@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"todo-app/idempotency"
+	"todo-app/service"
+	"todo-app/trace"
+)
+
+// TestHTTPAPI_Idempotency_AddReplays verifies a retried /add with the same
+// key creates one item and replays the original response, while a reused key
+// with another payload is rejected with 422.
+func TestHTTPAPI_Idempotency_AddReplays(t *testing.T) {
+	store := &memStore{}
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	mux := http.NewServeMux()
+	RegisterWith(mux, service.SharedStore(store), Options{Idempotency: idempotency.New(0)})
+
+	traces := 0
+	post := func(user, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(body))
+		req.Header.Set(UserHeader, user)
+		traces++
+		req.Header.Set(trace.Header, fmt.Sprintf("trace-%d", traces))
+		if key != "" {
+			req.Header.Set(idempotency.Header, key)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	first := post("alice", "k-1", `{"description":"buy milk"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first add: status=%d body=%s", first.Code, first.Body)
+	}
+	retry := post("alice", "k-1", `{"description":"buy milk"}`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Fatalf("retry: status=%d body=%s, want replay of %s", retry.Code, retry.Body, first.Body)
+	}
+	if retry.Header().Get(ReplayedHeader) != "true" || first.Header().Get(ReplayedHeader) != "" {
+		t.Fatalf("replay header: first=%q retry=%q", first.Header().Get(ReplayedHeader), retry.Header().Get(ReplayedHeader))
+	}
+	if n := len(store.list); n != 1 {
+		t.Fatalf("store has %d items after retry, want 1", n)
+	}
+	// The retry is its own request: it answers with its own trace id.
+	if got := retry.Header().Get(trace.Header); got != "trace-2" {
+		t.Fatalf("replayed trace id %q, want trace-2", got)
+	}
+
+	if w := post("alice", "k-1", `{"description":"buy bread"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reused key with new payload: status=%d want 422", w.Code)
+	}
+	if w := post("alice", "bad key", `{"description":"x"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid key: status=%d want 400", w.Code)
+	}
+	// No key: plain behaviour, every request runs.
+	post("alice", "", `{"description":"again"}`)
+	post("alice", "", `{"description":"again"}`)
+	if n := len(store.list); n != 3 {
+		t.Fatalf("store has %d items, want 3", n)
+	}
+}
+
+// TestHTTPAPI_Idempotency_ErrorsNotStored checks that 5xx responses are not
+// remembered, so a retry after a server failure runs again.
+func TestHTTPAPI_Idempotency_ErrorsNotStored(t *testing.T) {
+	store := idempotency.New(0)
+	calls := 0
+	h := idempotent(store, func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			respondErr(ctx, w, http.StatusInternalServerError, errors.New("disk full"))
+			return
+		}
+		respondJSON(w, http.StatusOK, "ok")
+	})
+	for i, want := range []int{http.StatusInternalServerError, http.StatusOK, http.StatusOK} {
+		req := httptest.NewRequest(http.MethodPost, "/add", strings.NewReader("{}"))
+		req.Header.Set(idempotency.Header, "k")
+		w := httptest.NewRecorder()
+		h(context.Background(), w, req)
+		if w.Code != want {
+			t.Fatalf("call %d: status=%d want %d", i+1, w.Code, want)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("handler ran %d times, want 2 (third call is a replay)", calls)
+	}
+}
@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+//
+// httpapi/interchange.go (package httpapi)
+// ----------------------------------------
+// Download and upload in the todo package's interchange formats:
+//
+//	GET  /todos:export?format=csv&list=home   the items as a file
+//	POST /todos:import?format=todotxt&list=home   add the items in the body
+//
+// The format is a codec name (json, csv, tsv, markdown, todotxt, ical).
+// Uploads without ?format= are recognised by Content-Type; downloads
+// default to JSON. Imports are all-or-nothing and give the items new ids.
+//
+
+// codecByType maps upload Content-Types onto codecs.
+var codecByType = map[string]string{
+	"application/json":          "json",
+	"text/csv":                  "csv",
+	"text/tab-separated-values": "tsv",
+	"text/markdown":             "markdown",
+	"text/plain":                "todotxt",
+	"text/calendar":             "ical",
+}
+
+// requestCodec picks the codec from ?format=, else the Content-Type, else
+// JSON. It responds 400 and returns false for an unknown format.
+func requestCodec(ctx context.Context, w http.ResponseWriter, r *http.Request) (todo.Codec, bool) {
+	name := strings.TrimSpace(r.URL.Query().Get("format"))
+	if name == "" {
+		if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && r.Method == http.MethodPost {
+			name = codecByType[mt]
+		}
+	}
+	if name == "" {
+		name = "json"
+	}
+	c, err := todo.CodecFor(name)
+	if err != nil {
+		respondErr(ctx, w, http.StatusBadRequest, err)
+		return nil, false
+	}
+	return c, true
+}
+
+// Export handler
+func exportHandler(stores service.StoreFactory) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
+		codec, ok := requestCodec(ctx, w, r)
+		if !ok {
+			return
+		}
+		list, err := store.Load(ctx)
+		if err != nil {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		var buf bytes.Buffer
+		if err := codec.Encode(&buf, todo.FilterByList(list, strings.TrimSpace(r.URL.Query().Get("list")))); err != nil {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", codec.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "todos."+fileExt(codec)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+// fileExt is the download file extension for codec.
+func fileExt(c todo.Codec) string {
+	switch c.Name() {
+	case "markdown":
+		return "md"
+	case "todotxt":
+		return "txt"
+	case "ical":
+		return "ics"
+	}
+	return c.Name()
+}
+
+// importResponse is the body of a successful /todos:import.
+type importResponse struct {
+	Imported int         `json:"imported"`
+	Items    []todo.Item `json:"items"`
+}
+
+// Import handler
+func importHandler(stores service.StoreFactory, limits Limits) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		store, ok := userStore(ctx, w, stores)
+		if !ok {
+			return
+		}
+		codec, ok := requestCodec(ctx, w, r)
+		if !ok {
+			return
+		}
+		// Read the whole body first so an oversized upload is a 413, not a
+		// parse error partway through.
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondBodyErr(ctx, w, err)
+			return
+		}
+		records, err := codec.Decode(bytes.NewReader(body))
+		if err != nil {
+			respondErr(ctx, w, http.StatusBadRequest, err)
+			return
+		}
+
+		target := strings.TrimSpace(r.URL.Query().Get("list"))
+		names := []string{target}
+		if target == "" {
+			names = names[:0]
+			for _, rec := range records {
+				names = append(names, rec.List)
+			}
+		}
+		if err := writableLists(ctx, stores, names...); err != nil {
+			respondWritableErr(ctx, w, fmt.Errorf("import: %w", err))
+			return
+		}
+
+		var added []todo.Item
+		err = service.Update(ctx, store, func(list []todo.Item) ([]todo.Item, error) {
+			var next []todo.Item
+			var err error
+			next, added, err = todo.Import(list, records, target, limits.items()...)
+			return next, err
+		})
+		switch {
+		case errors.Is(err, todo.ErrInvalid):
+			respondErr(ctx, w, http.StatusBadRequest, err)
+			return
+		case err != nil:
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, importResponse{Imported: len(added), Items: added})
+	}
+}
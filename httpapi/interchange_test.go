@@ -0,0 +1,87 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+// TestHTTPAPI_Interchange_ImportExport uploads todo.txt and CSV, downloads
+// the items in several formats and checks failed uploads add nothing.
+func TestHTTPAPI_Interchange_ImportExport(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	stores := service.NewActorStoreFactory(filepath.Join(t.TempDir(), "todos.json"))
+	t.Cleanup(func() { _ = stores.Close() })
+	createLists(t, stores, service.DefaultUser, "home", "work")
+	mux := http.NewServeMux()
+	RegisterWith(mux, stores, Options{Limits: Limits{MaxBodyBytes: 4096}})
+
+	upload := func(query, ctype, body string) (*httptest.ResponseRecorder, importResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/todos:import"+query, strings.NewReader(body))
+		if ctype != "" {
+			r.Header.Set("Content-Type", ctype)
+		}
+		mux.ServeHTTP(w, r)
+		var resp importResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+	download := func(query string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/todos:export"+query, nil))
+		return w
+	}
+
+	// todo.txt, recognised by Content-Type.
+	w, resp := upload("", "text/plain; charset=utf-8", "(A) 2024-05-01 Call plumber @phone due:2024-05-03\nx 2024-05-04 2024-05-02 Buy milk list:home\n")
+	if w.Code != http.StatusCreated || resp.Imported != 2 || resp.Items[0].ID != 1 || resp.Items[0].Priority != "A" ||
+		resp.Items[1].Status != todo.StatusCompleted || resp.Items[1].List != "home" {
+		t.Fatalf("todo.txt upload: %d %s", w.Code, w.Body.String())
+	}
+	// CSV with ?format= and a target list.
+	w, resp = upload("?format=csv&list=work", "", "description,status\nWrite report,started\n")
+	if w.Code != http.StatusCreated || resp.Imported != 1 || resp.Items[0].ID != 3 || resp.Items[0].List != "work" {
+		t.Fatalf("csv upload: %d %s", w.Code, w.Body.String())
+	}
+
+	// Failures: a bad line, an unknown format, an oversized body.
+	if w, _ := upload("?format=todotxt", "", "ok\n(A) due:2024-06-01\n"); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "line 2") {
+		t.Fatalf("bad todo.txt: %d %s", w.Code, w.Body.String())
+	}
+	if w, _ := upload("?format=xml", "", "<x/>"); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown format: %d", w.Code)
+	}
+	if w, _ := upload("?format=csv", "", "description\n"+strings.Repeat("x\n", 3000)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized: %d", w.Code)
+	}
+
+	w = download("?format=markdown")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/markdown") ||
+		!strings.Contains(w.Header().Get("Content-Disposition"), `filename="todos.md"`) {
+		t.Fatalf("markdown download: %d %v", w.Code, w.Header())
+	}
+	if want := "## default\n\n- [ ] (A) Call plumber @phone due:2024-05-03\n\n## home\n\n- [x] Buy milk\n\n## work\n\n- [/] Write report\n"; w.Body.String() != want {
+		t.Fatalf("markdown body:\n%s", w.Body.String())
+	}
+	if w := download("?format=todotxt&list=home"); w.Body.String() != "x 2024-05-04 2024-05-02 Buy milk list:home\n" {
+		t.Fatalf("todo.txt download: %q", w.Body.String())
+	}
+	var items []todo.Item
+	if w := download(""); json.Unmarshal(w.Body.Bytes(), &items) != nil || len(items) != 3 {
+		t.Fatalf("json download: %s", w.Body.String())
+	}
+	if w := download("?format=yaml"); w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown download format: %d", w.Code)
+	}
+}
@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"todo-app/ratelimit"
+	"todo-app/todo"
+)
+
+//
+// httpapi/limits.go (package httpapi)
+// -----------------------------------
+// Abuse protection shared by every authenticated route: token-bucket rate
+// limits per client IP and per API key (429 + Retry-After), a cap on request
+// body size (413) and strict JSON decoding that rejects unknown fields.
+//
+
+// DefaultMaxBodyBytes caps request bodies when Limits.MaxBodyBytes is zero.
+const DefaultMaxBodyBytes = 1 << 20
+
+// Limits configures request throttling. Nil limiters disable that limit.
+type Limits struct {
+	// PerIP is keyed by the client address and checked before authentication.
+	PerIP *ratelimit.Limiter
+	// PerKey is keyed by API key id; it only applies when a keyring is set.
+	PerKey *ratelimit.Limiter
+	// MaxBodyBytes caps request bodies (DefaultMaxBodyBytes when zero).
+	MaxBodyBytes int64
+	// MaxDescription caps item descriptions in characters
+	// (todo.MaxDescriptionLen when zero).
+	MaxDescription int
+}
+
+// items returns the todo options that apply these limits to item writes.
+func (l Limits) items() []todo.Option {
+	if l.MaxDescription > 0 {
+		return []todo.Option{todo.MaxDescription(l.MaxDescription)}
+	}
+	return nil
+}
+
+func (l Limits) maxBody() int64 {
+	if l.MaxBodyBytes > 0 {
+		return l.MaxBodyBytes
+	}
+	return DefaultMaxBodyBytes
+}
+
+// throttle takes a token from limiter for key, responding 429 with
+// Retry-After (whole seconds, rounded up) and returning false when none is left.
+func throttle(ctx context.Context, w http.ResponseWriter, limiter *ratelimit.Limiter, key, what string) bool {
+	ok, wait := limiter.Allow(key)
+	if ok {
+		return true
+	}
+	secs := int(math.Ceil(wait.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	respondErr(ctx, w, http.StatusTooManyRequests, fmt.Errorf("rate limit exceeded for this %s; retry in %ds", what, secs))
+	return false
+}
+
+// clientIP is the host part of the connection's remote address. Forwarding
+// headers are ignored since any client can set them.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// decodeBody decodes the request body into v, rejecting unknown fields.
+// On failure it responds (413 for an oversized body, 400 otherwise) and
+// returns false.
+func decodeBody(ctx context.Context, w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		respondBodyErr(ctx, w, err)
+		return false
+	}
+	return true
+}
+
+// respondBodyErr reports a failure reading the request body: 413 when it
+// hit the MaxBytesReader cap, 400 otherwise.
+func respondBodyErr(ctx context.Context, w http.ResponseWriter, err error) {
+	var tooBig *http.MaxBytesError
+	if errors.As(err, &tooBig) {
+		respondErr(ctx, w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", tooBig.Limit))
+		return
+	}
+	respondErr(ctx, w, http.StatusBadRequest, err)
+}
@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"todo-app/auth"
+	"todo-app/ratelimit"
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+// TestHTTPAPI_Limits_RateLimitPerIPAndKey verifies 429 + Retry-After once a
+// client IP or an API key runs out of tokens, and that other IPs and keys
+// keep their own budget.
+func TestHTTPAPI_Limits_RateLimitPerIPAndKey(t *testing.T) {
+	keys, err := auth.Open(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil {
+		t.Fatalf("auth.Open: %v", err)
+	}
+	aliceTok, _, _ := keys.Create("a", "alice", auth.ScopeRead)
+	bobTok, _, _ := keys.Create("b", "bob", auth.ScopeRead)
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	mux := http.NewServeMux()
+	RegisterWith(mux, service.SharedStore(&memStore{}), Options{
+		Keys:   keys,
+		Limits: Limits{PerIP: ratelimit.New(0.01, 3), PerKey: ratelimit.New(0.01, 2)},
+	})
+	get := func(ip, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/get", nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := get("10.0.0.1", aliceTok); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status=%d", i+1, w.Code)
+		}
+	}
+	w := get("10.0.0.2", aliceTok)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("alice's key over its burst from a new IP: status=%d want 429", w.Code)
+	}
+	if ra, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || ra < 1 {
+		t.Fatalf("Retry-After=%q, want whole seconds", w.Header().Get("Retry-After"))
+	}
+	if w := get("10.0.0.1", bobTok); w.Code != http.StatusOK {
+		t.Fatalf("bob's key from the first IP (third request): status=%d", w.Code)
+	}
+	// The first IP has now spent its burst of 3, even for a fresh key.
+	if w := get("10.0.0.1", bobTok); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("fourth request from the first IP: status=%d want 429", w.Code)
+	}
+}
+
+// TestHTTPAPI_Limits_BodyAndUnknownFields verifies oversized bodies get 413,
+// unknown JSON fields 400, and over-long descriptions 400 on every write path.
+func TestHTTPAPI_Limits_BodyAndUnknownFields(t *testing.T) {
+	store := &memStore{}
+	store.seed([]todo.Item{{ID: 1, Description: "seed", Status: todo.StatusNotStarted}})
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	mux := http.NewServeMux()
+	RegisterWith(mux, service.SharedStore(store), Options{Limits: Limits{MaxBodyBytes: 64, MaxDescription: 10}})
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return w
+	}
+
+	cases := []struct {
+		name, path, body string
+		want             int
+	}{
+		{"ok", "/add", `{"description":"short"}`, http.StatusCreated},
+		{"unknown field", "/add", `{"description":"x","priority":1}`, http.StatusBadRequest},
+		{"too large", "/add", `{"description":"` + strings.Repeat("x", 100) + `"}`, http.StatusRequestEntityTooLarge},
+		{"long add", "/add", `{"description":"eleven char"}`, http.StatusBadRequest},
+		{"long update", "/update", `{"id":1,"description":"eleven char"}`, http.StatusBadRequest},
+		// batch reports the failed op in a multi-status body
+		{"long batch", "/todos:batch", `{"ops":[{"op":"create","description":"eleven char"}]}`, http.StatusMultiStatus},
+	}
+	for _, tc := range cases {
+		if w := post(tc.path, tc.body); w.Code != tc.want {
+			t.Fatalf("%s: status=%d want %d body=%s", tc.name, w.Code, tc.want, w.Body)
+		}
+	}
+	if len(store.list) != 2 || store.list[0].Description != "seed" {
+		t.Fatalf("rejected writes changed the store: %+v", store.list)
+	}
+}
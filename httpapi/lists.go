@@ -0,0 +1,359 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todo-app/auth"
+	"todo-app/lists"
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+//
+// httpapi/lists.go (package httpapi)
+// ----------------------------------
+// Shared list routes. Every handler resolves the caller's role on the list
+// first: non-members get 404 (list ids don't leak), members whose role is
+// too low get 403. Membership changes are written to the audit log.
+//
+
+// registerLists wires the shared list routes when a ListStore is configured.
+func registerLists(mux *http.ServeMux, opts Options) {
+	ls, audit := opts.Lists, opts.Audit
+	mux.HandleFunc("/lists", withCtx(logger(authn(opts, auth.ScopeRead, listsIndexHandler(ls)))))
+	mux.HandleFunc("/lists/create", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, listsCreateHandler(ls, audit))))))
+	mux.HandleFunc("/lists/get", withCtx(logger(authn(opts, auth.ScopeRead, listsGetHandler(ls)))))
+	mux.HandleFunc("/lists/items/add", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, listsItemAddHandler(ls, opts.Limits))))))
+	mux.HandleFunc("/lists/items/update", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, listsItemUpdateHandler(ls, opts.Limits))))))
+	mux.HandleFunc("/lists/items/delete", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, listsItemDeleteHandler(ls))))))
+	mux.HandleFunc("/lists/members/invite", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, listsMemberSetHandler(ls, audit, lists.ActionInvite))))))
+	mux.HandleFunc("/lists/members/role", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, listsMemberSetHandler(ls, audit, lists.ActionRole))))))
+	mux.HandleFunc("/lists/members/remove", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, listsMemberRemoveHandler(ls, audit))))))
+	mux.HandleFunc("/lists/audit", withCtx(logger(authn(opts, auth.ScopeRead, listsAuditHandler(ls, audit)))))
+}
+
+// listSummary is the /lists index entry: the list without its items.
+type listSummary struct {
+	ID        int            `json:"id"`
+	Name      string         `json:"name"`
+	Role      lists.Role     `json:"role"`
+	Members   []lists.Member `json:"members"`
+	ItemCount int            `json:"item_count"`
+}
+
+// respondListErr maps list domain errors onto HTTP statuses.
+func respondListErr(ctx context.Context, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, lists.ErrNotFound), errors.Is(err, todo.ErrNotFound):
+		respondErr(ctx, w, http.StatusNotFound, err)
+	case errors.Is(err, lists.ErrForbidden):
+		respondErr(ctx, w, http.StatusForbidden, err)
+	case errors.Is(err, lists.ErrLastOwner):
+		respondErr(ctx, w, http.StatusConflict, err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		respondErr(ctx, w, http.StatusServiceUnavailable, err)
+	case errors.Is(err, errAudit):
+		respondErr(ctx, w, http.StatusInternalServerError, err)
+	default:
+		respondErr(ctx, w, http.StatusBadRequest, err)
+	}
+}
+
+// errAudit matches a membership change refused because its audit entry
+// could not be written.
+var errAudit = errors.New("audit log unavailable")
+
+// recordAudit writes e to the audit log. It runs inside the ListStore update
+// that makes the change, so a change that cannot be audited is not saved.
+func recordAudit(ctx context.Context, audit *lists.AuditLog, e lists.AuditEntry) error {
+	if err := audit.Record(ctx, e); err != nil {
+		slog.ErrorContext(ctx, "audit write failed", "error", err, "action", e.Action, "list_id", e.ListID)
+		return fmt.Errorf("%w: %v", errAudit, err)
+	}
+	return nil
+}
+
+// Lists index handler
+func listsIndexHandler(ls *service.ListStore) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		all, err := ls.All(ctx)
+		if err != nil {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		user := currentUser(ctx)
+		out := []listSummary{}
+		for _, l := range lists.VisibleTo(all, user) {
+			role, _ := l.RoleOf(user)
+			out = append(out, listSummary{ID: l.ID, Name: l.Name, Role: role, Members: l.Members, ItemCount: len(l.Items)})
+		}
+		respondJSON(w, http.StatusOK, out)
+	}
+}
+
+// Lists create handler
+func listsCreateHandler(ls *service.ListStore, audit *lists.AuditLog) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if !decodeBody(ctx, w, r, &req) {
+			return
+		}
+		user := currentUser(ctx)
+		var created lists.List
+		err := ls.Update(ctx, func(all []lists.List) ([]lists.List, error) {
+			var err error
+			if all, created, err = lists.Create(all, req.Name, user); err != nil {
+				return all, err
+			}
+			return all, recordAudit(ctx, audit, lists.AuditEntry{Actor: user, ListID: created.ID, Action: lists.ActionCreate, User: user, NewRole: lists.RoleOwner})
+		})
+		if err != nil {
+			respondListErr(ctx, w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, created)
+	}
+}
+
+// Lists get handler (list with items)
+func listsGetHandler(ls *service.ListStore) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("id")))
+		if err != nil {
+			respondErr(ctx, w, http.StatusBadRequest, fmt.Errorf("id query parameter is required"))
+			return
+		}
+		all, err := ls.All(ctx)
+		if err != nil {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		l, _, err := lists.Find(all, id, currentUser(ctx))
+		if err != nil {
+			respondListErr(ctx, w, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, l)
+	}
+}
+
+// updateListItems runs fn against list id's items as the current user and
+// returns the resulting list.
+func updateListItems(ctx context.Context, ls *service.ListStore, id int, fn func([]todo.Item) ([]todo.Item, error)) (lists.List, error) {
+	user := currentUser(ctx)
+	var updated lists.List
+	err := ls.Update(ctx, func(all []lists.List) ([]lists.List, error) {
+		all, err := lists.UpdateItems(all, id, user, fn)
+		if err != nil {
+			return all, err
+		}
+		updated, _, err = lists.Find(all, id, user)
+		return all, err
+	})
+	return updated, err
+}
+
+// Lists item add handler
+func listsItemAddHandler(ls *service.ListStore, limits Limits) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ListID      int    `json:"list_id"`
+			Description string `json:"description"`
+			Status      string `json:"status"`
+		}
+		if !decodeBody(ctx, w, r, &req) {
+			return
+		}
+		st := todo.Status(strings.TrimSpace(req.Status))
+		if st == "" {
+			st = todo.StatusNotStarted
+		}
+		var item todo.Item
+		_, err := updateListItems(ctx, ls, req.ListID, func(items []todo.Item) ([]todo.Item, error) {
+			var err error
+			items, item, err = todo.Add(items, req.Description, st, limits.items()...)
+			return items, err
+		})
+		if err != nil {
+			respondListErr(ctx, w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, item)
+	}
+}
+
+// Lists item update handler
+func listsItemUpdateHandler(ls *service.ListStore, limits Limits) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ListID      int    `json:"list_id"`
+			ID          int    `json:"id"`
+			Description string `json:"description"`
+			Status      string `json:"status"`
+		}
+		if !decodeBody(ctx, w, r, &req) {
+			return
+		}
+		l, err := updateListItems(ctx, ls, req.ListID, func(items []todo.Item) ([]todo.Item, error) {
+			var err error
+			if req.Description != "" {
+				if items, err = todo.UpdateDescription(items, req.ID, req.Description, limits.items()...); err != nil {
+					return items, err
+				}
+			}
+			if req.Status != "" {
+				if items, err = todo.UpdateStatus(items, req.ID, todo.Status(strings.TrimSpace(req.Status))); err != nil {
+					return items, err
+				}
+			}
+			if _, ok := service.FindByID(items, req.ID); !ok {
+				return items, fmt.Errorf("%w: id %d", todo.ErrNotFound, req.ID)
+			}
+			return items, nil
+		})
+		if err != nil {
+			respondListErr(ctx, w, err)
+			return
+		}
+		updated, _ := service.FindByID(l.Items, req.ID)
+		respondJSON(w, http.StatusOK, updated)
+	}
+}
+
+// Lists item delete handler
+func listsItemDeleteHandler(ls *service.ListStore) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ListID int `json:"list_id"`
+			ID     int `json:"id"`
+		}
+		if !decodeBody(ctx, w, r, &req) {
+			return
+		}
+		_, err := updateListItems(ctx, ls, req.ListID, func(items []todo.Item) ([]todo.Item, error) {
+			return todo.Delete(items, req.ID)
+		})
+		if err != nil {
+			respondListErr(ctx, w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Lists member invite / role change handler
+func listsMemberSetHandler(ls *service.ListStore, audit *lists.AuditLog, action string) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ListID int    `json:"list_id"`
+			User   string `json:"user"`
+			Role   string `json:"role"`
+		}
+		if !decodeBody(ctx, w, r, &req) {
+			return
+		}
+		member := strings.TrimSpace(req.User)
+		if err := service.ValidateUserID(member); err != nil {
+			respondErr(ctx, w, http.StatusBadRequest, err)
+			return
+		}
+		role := lists.Role(strings.ToLower(strings.TrimSpace(req.Role)))
+		actor := currentUser(ctx)
+
+		var prev lists.Role
+		var updated lists.List
+		err := ls.Update(ctx, func(all []lists.List) ([]lists.List, error) {
+			// invite is for newcomers, role is for existing members
+			if l, _, err := lists.Find(all, req.ListID, actor); err == nil {
+				_, isMember := l.RoleOf(member)
+				if action == lists.ActionInvite && isMember {
+					return all, fmt.Errorf("%q is already a member; change their role instead", member)
+				}
+				if action == lists.ActionRole && !isMember {
+					return all, fmt.Errorf("%q is not a member of list %d", member, req.ListID)
+				}
+			}
+			var err error
+			all, prev, err = lists.SetMember(all, req.ListID, actor, member, role)
+			if err != nil {
+				return all, err
+			}
+			if updated, _, err = lists.Find(all, req.ListID, actor); err != nil {
+				return all, err
+			}
+			return all, recordAudit(ctx, audit, lists.AuditEntry{Actor: actor, ListID: req.ListID, Action: action, User: member, OldRole: prev, NewRole: role})
+		})
+		if err != nil {
+			respondListErr(ctx, w, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, updated)
+	}
+}
+
+// Lists member remove handler
+func listsMemberRemoveHandler(ls *service.ListStore, audit *lists.AuditLog) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ListID int    `json:"list_id"`
+			User   string `json:"user"`
+		}
+		if !decodeBody(ctx, w, r, &req) {
+			return
+		}
+		actor := currentUser(ctx)
+		member := strings.TrimSpace(req.User)
+		var prev lists.Role
+		err := ls.Update(ctx, func(all []lists.List) ([]lists.List, error) {
+			var err error
+			if all, prev, err = lists.RemoveMember(all, req.ListID, actor, member); err != nil {
+				return all, err
+			}
+			return all, recordAudit(ctx, audit, lists.AuditEntry{Actor: actor, ListID: req.ListID, Action: lists.ActionRemove, User: member, OldRole: prev})
+		})
+		if err != nil {
+			respondListErr(ctx, w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Lists audit handler (owners only)
+func listsAuditHandler(ls *service.ListStore, audit *lists.AuditLog) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get("id")))
+		if err != nil {
+			respondErr(ctx, w, http.StatusBadRequest, fmt.Errorf("id query parameter is required"))
+			return
+		}
+		all, err := ls.All(ctx)
+		if err != nil {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		_, role, err := lists.Find(all, id, currentUser(ctx))
+		if err != nil {
+			respondListErr(ctx, w, err)
+			return
+		}
+		if !role.CanManage() {
+			respondListErr(ctx, w, lists.ErrForbidden)
+			return
+		}
+		entries, err := audit.Entries(id)
+		if err != nil {
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, entries)
+	}
+}
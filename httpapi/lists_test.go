@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"todo-app/lists"
+	"todo-app/service"
+)
+
+// TestHTTPAPI_Lists_RolesAndAudit drives shared lists through the API:
+// owners invite and change roles, editors write, viewers only read,
+// outsiders get 404, and every membership change is audited.
+func TestHTTPAPI_Lists_RolesAndAudit(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	dir := t.TempDir()
+	ls := service.NewListStore(filepath.Join(dir, "lists.json"))
+	t.Cleanup(func() { _ = ls.Close() })
+	audit := lists.NewAuditLog(filepath.Join(dir, "audit.log"))
+
+	mux := http.NewServeMux()
+	RegisterWith(mux, service.SharedStore(&memStore{}), Options{Lists: ls, Audit: audit})
+
+	as := func(user, method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(UserHeader, user)
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	expect := func(w *httptest.ResponseRecorder, code int, what string) {
+		t.Helper()
+		if w.Code != code {
+			t.Fatalf("%s: status=%d want %d; body=%s", what, w.Code, code, w.Body.String())
+		}
+	}
+
+	expect(as("olive", "POST", "/lists/create", `{"name":"Launch"}`), http.StatusCreated, "create")
+	expect(as("olive", "POST", "/lists/members/invite", `{"list_id":1,"user":"ed","role":"editor"}`), http.StatusOK, "invite ed")
+	expect(as("olive", "POST", "/lists/members/invite", `{"list_id":1,"user":"vic","role":"viewer"}`), http.StatusOK, "invite vic")
+	expect(as("olive", "POST", "/lists/members/invite", `{"list_id":1,"user":"vic","role":"editor"}`), http.StatusBadRequest, "re-invite vic")
+
+	expect(as("ed", "POST", "/lists/items/add", `{"list_id":1,"description":"ship it"}`), http.StatusCreated, "editor add")
+	expect(as("vic", "POST", "/lists/items/add", `{"list_id":1,"description":"nope"}`), http.StatusForbidden, "viewer add")
+	expect(as("vic", "POST", "/lists/items/update", `{"list_id":1,"id":1,"status":"completed"}`), http.StatusForbidden, "viewer update")
+	expect(as("mallory", "GET", "/lists/get?id=1", ""), http.StatusNotFound, "outsider get")
+	expect(as("ed", "POST", "/lists/members/invite", `{"list_id":1,"user":"zed","role":"viewer"}`), http.StatusForbidden, "editor invite")
+
+	w := as("vic", "GET", "/lists/get?id=1", "")
+	expect(w, http.StatusOK, "viewer get")
+	var l lists.List
+	_ = json.Unmarshal(w.Body.Bytes(), &l)
+	if len(l.Items) != 1 || l.Items[0].Description != "ship it" {
+		t.Fatalf("viewer sees items %+v", l.Items)
+	}
+
+	expect(as("olive", "POST", "/lists/members/role", `{"list_id":1,"user":"vic","role":"editor"}`), http.StatusOK, "promote vic")
+	expect(as("vic", "POST", "/lists/items/update", `{"list_id":1,"id":1,"status":"completed"}`), http.StatusOK, "promoted update")
+	// A missing item is 404 like a missing list, on every item route.
+	expect(as("vic", "POST", "/lists/items/update", `{"list_id":1,"id":99,"status":"completed"}`), http.StatusNotFound, "update missing item")
+	expect(as("vic", "POST", "/lists/items/update", `{"list_id":1,"id":99}`), http.StatusNotFound, "no-op update of missing item")
+	expect(as("vic", "POST", "/lists/items/delete", `{"list_id":1,"id":99}`), http.StatusNotFound, "delete missing item")
+	expect(as("vic", "POST", "/lists/items/update", `{"list_id":1,"id":1,"status":"someday"}`), http.StatusBadRequest, "invalid status")
+	expect(as("olive", "POST", "/lists/members/remove", `{"list_id":1,"user":"ed"}`), http.StatusNoContent, "remove ed")
+	expect(as("ed", "GET", "/lists/get?id=1", ""), http.StatusNotFound, "removed member get")
+	expect(as("olive", "POST", "/lists/members/remove", `{"list_id":1,"user":"olive"}`), http.StatusConflict, "remove last owner")
+
+	var idx []listSummary
+	w = as("vic", "GET", "/lists", "")
+	_ = json.Unmarshal(w.Body.Bytes(), &idx)
+	if len(idx) != 1 || idx[0].Role != lists.RoleEditor {
+		t.Fatalf("vic index=%+v", idx)
+	}
+
+	expect(as("vic", "GET", "/lists/audit?id=1", ""), http.StatusForbidden, "non-owner audit")
+	w = as("olive", "GET", "/lists/audit?id=1", "")
+	expect(w, http.StatusOK, "owner audit")
+	var entries []lists.AuditEntry
+	_ = json.Unmarshal(w.Body.Bytes(), &entries)
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action+":"+e.User)
+	}
+	want := "list.create:olive member.invite:ed member.invite:vic member.role:vic member.remove:ed"
+	if got := strings.Join(actions, " "); got != want {
+		t.Fatalf("audit actions = %q\nwant %q", got, want)
+	}
+	if entries[3].OldRole != lists.RoleViewer || entries[3].NewRole != lists.RoleEditor || entries[3].Actor != "olive" {
+		t.Fatalf("role change entry = %+v", entries[3])
+	}
+}
+
+// TestHTTPAPI_Lists_AuditRequired checks that a membership change whose
+// audit entry cannot be written fails and is not saved.
+func TestHTTPAPI_Lists_AuditRequired(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	dir := t.TempDir()
+	ls := service.NewListStore(filepath.Join(dir, "lists.json"))
+	t.Cleanup(func() { _ = ls.Close() })
+	// The audit log's directory is a file, so every write fails.
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	good, broken := http.NewServeMux(), http.NewServeMux()
+	RegisterWith(good, service.SharedStore(&memStore{}), Options{Lists: ls, Audit: lists.NewAuditLog(filepath.Join(dir, "audit.log"))})
+	RegisterWith(broken, service.SharedStore(&memStore{}), Options{Lists: ls, Audit: lists.NewAuditLog(filepath.Join(blocker, "audit.log"))})
+	as := func(mux *http.ServeMux, user, method, path, body string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(UserHeader, user)
+		mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := as(broken, "olive", "POST", "/lists/create", `{"name":"Launch"}`); code != http.StatusInternalServerError {
+		t.Fatalf("create without audit: status=%d", code)
+	}
+	if code := as(good, "olive", "GET", "/lists/get?id=1", ""); code != http.StatusNotFound {
+		t.Fatalf("unaudited create was saved: status=%d", code)
+	}
+	if code := as(good, "olive", "POST", "/lists/create", `{"name":"Launch"}`); code != http.StatusCreated {
+		t.Fatalf("create: status=%d", code)
+	}
+	if code := as(good, "olive", "POST", "/lists/members/invite", `{"list_id":1,"user":"vic","role":"viewer"}`); code != http.StatusOK {
+		t.Fatalf("invite vic: status=%d", code)
+	}
+	for _, tc := range []struct{ path, body string }{
+		{"/lists/members/invite", `{"list_id":1,"user":"ed","role":"editor"}`},
+		{"/lists/members/role", `{"list_id":1,"user":"vic","role":"editor"}`},
+		{"/lists/members/remove", `{"list_id":1,"user":"vic"}`},
+	} {
+		if code := as(broken, "olive", "POST", tc.path, tc.body); code != http.StatusInternalServerError {
+			t.Fatalf("%s without audit: status=%d", tc.path, code)
+		}
+	}
+	if code := as(good, "ed", "GET", "/lists/get?id=1", ""); code != http.StatusNotFound {
+		t.Fatalf("unaudited invite was saved: status=%d", code)
+	}
+	if code := as(good, "vic", "POST", "/lists/items/add", `{"list_id":1,"description":"x"}`); code != http.StatusForbidden {
+		t.Fatalf("vic after unaudited role change and removal: status=%d, want a viewer's 403", code)
+	}
+}
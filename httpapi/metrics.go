@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"todo-app/metrics"
+)
+
+//
+// httpapi/metrics.go (package httpapi)
+// ------------------------------------
+// Request metrics recorded by the logger middleware, and the /metrics route
+// that serves metrics.Default in the Prometheus text format. Routes are
+// labelled by their registered pattern (not the raw path) to keep label
+// cardinality bounded.
+//
+
+var (
+	httpRequests = metrics.Default.NewCounterVec("todo_http_requests_total",
+		"HTTP requests served, by method, route and status code.", "method", "route", "status")
+	httpDuration = metrics.Default.NewHistogramVec("todo_http_request_duration_seconds",
+		"HTTP request latency, by method, route and status code.", nil, "method", "route", "status")
+)
+
+// requestRoute is the pattern that matched r, or "unmatched".
+func requestRoute(r *http.Request) string {
+	if r.Pattern == "" {
+		return "unmatched"
+	}
+	return r.Pattern
+}
+
+// observeRequest records one finished request.
+func observeRequest(r *http.Request, status int, seconds float64) {
+	route := requestRoute(r)
+	code := strconv.Itoa(status)
+	httpRequests.With(r.Method, route, code).Inc()
+	httpDuration.With(r.Method, route, code).Observe(seconds)
+}
+
+// Metrics handler
+func metricsHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	_ = metrics.Default.Write(w)
+}
@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"todo-app/metrics"
+	"todo-app/service"
+)
+
+// TestHTTPAPI_Metrics_RequestAndStoreSeries drives a request through a real
+// ActorStore and checks /metrics exposes request, latency and store series.
+func TestHTTPAPI_Metrics_RequestAndStoreSeries(t *testing.T) {
+	newMuxWithStore(&memStore{}) // silences logs
+	stores := service.NewActorStoreFactory(filepath.Join(t.TempDir(), "todos.json"))
+	t.Cleanup(func() { _ = stores.Close() })
+	mux := http.NewServeMux()
+	RegisterWith(mux, stores, Options{})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(`{"description":"measure me","status":"started"}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("add: status=%d", w.Code)
+	}
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no/such/route/123", nil))
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != metrics.ContentType {
+		t.Fatalf("/metrics status=%d content-type=%q", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`todo_http_requests_total{method="POST",route="/add",status="201"}`,
+		`todo_http_request_duration_seconds_bucket{method="POST",route="/add",status="201",le="+Inf"}`,
+		`todo_actor_queue_wait_seconds_count{op="get"}`,
+		`todo_store_save_duration_seconds_count{result="ok"}`,
+		"todo_store_file_size_bytes ",
+		`todo_store_items{status="started"} `,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics missing %s", want)
+		}
+	}
+	if strings.Contains(body, "file=") || strings.Contains(body, ".json") {
+		t.Errorf("data file paths must not become labels")
+	}
+	if strings.Contains(body, "/no/such/route/123") {
+		t.Errorf("raw paths must not become labels")
+	}
+}
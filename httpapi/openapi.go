@@ -0,0 +1,34 @@
+package httpapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//
+// httpapi/openapi.go (package httpapi)
+// ------------------------------------
+// API documentation: the OpenAPI 3.1 document describing every route
+// registered by RegisterWith, and a small explorer page that renders it and
+// sends requests. Both are embedded in the binary and need no authentication.
+// TestHTTPAPI_OpenAPI_MatchesRoutes fails when routes and openapi.json drift.
+//
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+//go:embed explorer.html
+var explorerPage []byte
+
+// registerDocs wires /openapi.json and /explorer.
+func registerDocs(mux *http.ServeMux) {
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*") // readable by code generators and other origins
+		_, _ = w.Write(openAPISpec)
+	})
+	mux.HandleFunc("/explorer", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(explorerPage)
+	})
+}
@@ -0,0 +1,155 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"todo-app/lists"
+	"todo-app/service"
+	"todo-app/webhook"
+)
+
+// registeredPatterns returns the literal patterns passed to mux.HandleFunc
+// and mux.Handle in this package's non-test sources.
+func registeredPatterns(t *testing.T) []string {
+	t.Helper()
+	fset := token.NewFileSet()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	var out []string
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "HandleFunc" && sel.Sel.Name != "Handle") {
+				return true
+			}
+			if id, ok := sel.X.(*ast.Ident); !ok || id.Name != "mux" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Fatalf("%s: route pattern must be a string literal", fset.Position(call.Pos()))
+			}
+			p, _ := strconv.Unquote(lit.Value)
+			out = append(out, p)
+			return true
+		})
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TestHTTPAPI_OpenAPI_MatchesRoutes fails when a route is registered without
+// being documented in openapi.json, or documented without being registered,
+// and checks that every $ref in the document resolves.
+func TestHTTPAPI_OpenAPI_MatchesRoutes(t *testing.T) {
+	var spec struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components map[string]map[string]json.RawMessage `json:"components"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.1") {
+		t.Fatalf("openapi = %q, want 3.1.x", spec.OpenAPI)
+	}
+
+	// Register with every optional feature enabled so all routes exist.
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	dir := t.TempDir()
+	ls := service.NewListStore(filepath.Join(dir, "lists.json"))
+	t.Cleanup(func() { _ = ls.Close() })
+	mux := http.NewServeMux()
+	RegisterWith(mux, service.SharedStore(&memStore{}), Options{
+		Lists: ls, Audit: lists.NewAuditLog(filepath.Join(dir, "audit.log")),
+		Webhooks: webhook.NewDispatcher(webhook.Config{
+			HooksPath: filepath.Join(dir, "hooks.json"), QueuePath: filepath.Join(dir, "queue.json"),
+		}),
+	})
+
+	routes := registeredPatterns(t)
+	registered := map[string]bool{}
+	for _, p := range routes {
+		// the source scan must agree with what the mux really serves
+		if _, got := mux.Handler(httptest.NewRequest(http.MethodGet, p, nil)); got != p {
+			t.Fatalf("pattern %q found in source but the mux matched %q", p, got)
+		}
+		registered[p] = true
+		if _, ok := spec.Paths[p]; !ok {
+			t.Errorf("route %s is registered but missing from openapi.json", p)
+		}
+	}
+	for p, ops := range spec.Paths {
+		if !registered[p] {
+			t.Errorf("openapi.json documents %s but no such route is registered", p)
+		}
+		for method := range ops {
+			if method != "get" && method != "post" {
+				t.Errorf("%s: unexpected method %q", p, method)
+			}
+		}
+	}
+
+	// Every "$ref": "#/components/<kind>/<name>" must point at something.
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+				if len(parts) != 2 || spec.Components[parts[0]][parts[1]] == nil {
+					t.Errorf("unresolved $ref %q", ref)
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	var doc any
+	_ = json.Unmarshal(openAPISpec, &doc)
+	walk(doc)
+}
+
+// TestHTTPAPI_OpenAPI_Served checks /openapi.json and /explorer need no
+// authentication and have the right content types.
+func TestHTTPAPI_OpenAPI_Served(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterWith(mux, service.SharedStore(&memStore{}), Options{})
+	for path, ctype := range map[string]string{"/openapi.json": "application/json", "/explorer": "text/html"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), ctype) || w.Body.Len() == 0 {
+			t.Fatalf("%s: status=%d type=%q len=%d", path, w.Code, w.Header().Get("Content-Type"), w.Body.Len())
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"todo-app/auth"
+	"todo-app/webhook"
+)
+
+//
+// httpapi/webhooks.go (package httpapi)
+// -------------------------------------
+// Webhook management routes. Hooks belong to the calling user and only fire
+// for that user's item changes; other users' hooks are reported as 404.
+//
+
+// registerWebhooks wires the webhook routes when a Dispatcher is configured.
+func registerWebhooks(mux *http.ServeMux, opts Options) {
+	d := opts.Webhooks
+	mux.HandleFunc("/webhooks", withCtx(logger(authn(opts, auth.ScopeRead, webhooksIndexHandler(d)))))
+	mux.HandleFunc("/webhooks/create", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, webhooksCreateHandler(d))))))
+	mux.HandleFunc("/webhooks/delete", withCtx(logger(authn(opts, auth.ScopeWrite, idempotent(opts.Idempotency, webhooksDeleteHandler(d))))))
+	mux.HandleFunc("/webhooks/deliveries", withCtx(logger(authn(opts, auth.ScopeRead, webhooksDeliveriesHandler(d)))))
+}
+
+// Webhooks index handler (secrets are never listed)
+func webhooksIndexHandler(d *webhook.Dispatcher) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, d.List(currentUser(ctx)))
+	}
+}
+
+// Webhooks create handler; the response is the only time the secret is shown
+func webhooksCreateHandler(d *webhook.Dispatcher) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			URL    string   `json:"url"`
+			Events []string `json:"events"`
+			Secret string   `json:"secret"`
+		}
+		if !decodeBody(ctx, w, r, &req) {
+			return
+		}
+		h, err := webhook.NewHook(currentUser(ctx), req.URL, req.Events, req.Secret)
+		if err != nil {
+			respondErr(ctx, w, http.StatusBadRequest, err)
+			return
+		}
+		if err := d.Create(ctx, h); err != nil {
+			if errors.Is(err, webhook.ErrPrivateTarget) {
+				respondErr(ctx, w, http.StatusBadRequest, err)
+				return
+			}
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, h)
+	}
+}
+
+// Webhooks delete handler
+func webhooksDeleteHandler(d *webhook.Dispatcher) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID string `json:"id"`
+		}
+		if !decodeBody(ctx, w, r, &req) {
+			return
+		}
+		if err := d.Delete(ctx, currentUser(ctx), strings.TrimSpace(req.ID)); err != nil {
+			if errors.Is(err, webhook.ErrNotFound) {
+				respondErr(ctx, w, http.StatusNotFound, err)
+				return
+			}
+			respondErr(ctx, w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]string{"deleted": req.ID})
+	}
+}
+
+// Webhook deliveries handler (delivery log, newest first; ?id= filters by hook)
+func webhooksDeliveriesHandler(d *webhook.Dispatcher) CtxHandler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		user := currentUser(ctx)
+		hookID := strings.TrimSpace(r.URL.Query().Get("id"))
+		if hookID != "" && !hasHook(d, user, hookID) {
+			respondErr(ctx, w, http.StatusNotFound, webhook.ErrNotFound)
+			return
+		}
+		respondJSON(w, http.StatusOK, d.Deliveries(user, hookID))
+	}
+}
+
+func hasHook(d *webhook.Dispatcher, user, id string) bool {
+	for _, h := range d.List(user) {
+		if h.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"todo-app/service"
+	"todo-app/webhook"
+)
+
+// TestHTTPAPI_Webhooks_CRUD covers creating, listing, deleting and reading the
+// delivery log of webhooks, including per-user scoping.
+func TestHTTPAPI_Webhooks_CRUD(t *testing.T) {
+	dir := t.TempDir()
+	d := webhook.NewDispatcher(webhook.Config{
+		HooksPath: filepath.Join(dir, "webhooks.json"),
+		QueuePath: filepath.Join(dir, "webhook_queue.json"),
+	})
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	mux := http.NewServeMux()
+	RegisterWith(mux, service.SharedStore(&memStore{}), Options{Webhooks: d})
+
+	as := func(user, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(UserHeader, user)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := as("alice", http.MethodPost, "/webhooks/create", `{"url":"not a url"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad url: status=%d", w.Code)
+	}
+	if w := as("alice", http.MethodPost, "/webhooks/create", `{"url":"http://169.254.169.254/latest/meta-data/"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("metadata url: status=%d", w.Code)
+	}
+	w := as("alice", http.MethodPost, "/webhooks/create", `{"url":"https://example.com/hook","events":["updated"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status=%d body=%s", w.Code, w.Body)
+	}
+	var h webhook.Hook
+	_ = json.Unmarshal(w.Body.Bytes(), &h)
+	if h.ID == "" || h.Secret == "" {
+		t.Fatalf("create response missing id/secret: %s", w.Body)
+	}
+
+	var listed []webhook.Hook
+	_ = json.Unmarshal(as("alice", http.MethodGet, "/webhooks", "").Body.Bytes(), &listed)
+	if len(listed) != 1 || listed[0].Secret != "" {
+		t.Fatalf("list = %+v (secret must be hidden)", listed)
+	}
+	if body := as("bob", http.MethodGet, "/webhooks", "").Body.String(); strings.TrimSpace(body) != "[]" {
+		t.Fatalf("bob sees alice's hooks: %s", body)
+	}
+	if w := as("bob", http.MethodGet, "/webhooks/deliveries?id="+h.ID, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("bob deliveries: status=%d", w.Code)
+	}
+	if w := as("alice", http.MethodGet, "/webhooks/deliveries?id="+h.ID, ""); w.Code != http.StatusOK {
+		t.Fatalf("alice deliveries: status=%d", w.Code)
+	}
+	if w := as("bob", http.MethodPost, "/webhooks/delete", `{"id":"`+h.ID+`"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("bob delete: status=%d", w.Code)
+	}
+	if w := as("alice", http.MethodPost, "/webhooks/delete", `{"id":"`+h.ID+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("alice delete: status=%d", w.Code)
+	}
+}
@@ -0,0 +1,152 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//
+// idempotency/idempotency.go (package idempotency)
+// ------------------------------------------------
+// Remembers the response to a request sent with an Idempotency-Key header so
+// that client retries (after a timeout, say) replay the first response instead
+// of repeating the mutation. Keys are scoped (per user and route by the HTTP
+// layer) and expire after a configurable window. Entries live in memory, so
+// the window does not survive a restart.
+//
+
+// Header is the request header carrying the client's key.
+const Header = "Idempotency-Key"
+
+// DefaultTTL is how long keys are remembered when no window is configured.
+const DefaultTTL = 24 * time.Hour
+
+// MaxKeyLen bounds the accepted key length.
+const MaxKeyLen = 255
+
+var (
+	// ErrMismatch means the key was already used with a different request.
+	ErrMismatch = errors.New("idempotency key was already used with a different request")
+	// ErrInFlight means the first request with this key has not finished yet.
+	ErrInFlight = errors.New("a request with this idempotency key is still in progress")
+)
+
+// Response is a stored response.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type entry struct {
+	fingerprint string
+	done        bool
+	resp        Response
+	expires     time.Time
+}
+
+// Store holds recent keys and their responses. It is safe for concurrent use.
+type Store struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+// New creates a Store remembering keys for ttl (DefaultTTL when ttl <= 0).
+func New(ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Store{ttl: ttl, now: time.Now, entries: map[string]*entry{}}
+}
+
+// TTL returns the configured window.
+func (s *Store) TTL() time.Duration { return s.ttl }
+
+// ValidateKey checks a client-supplied key.
+func ValidateKey(key string) error {
+	if key == "" || len(key) > MaxKeyLen {
+		return fmt.Errorf("%s must be 1-%d characters", Header, MaxKeyLen)
+	}
+	for _, r := range key {
+		if r < 0x21 || r > 0x7e {
+			return fmt.Errorf("%s must be printable ASCII without spaces", Header)
+		}
+	}
+	return nil
+}
+
+// Fingerprint hashes the parts of a request that must match on replay.
+func Fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%d:", len(p))
+		h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin claims key within scope for a request with fingerprint fp.
+//   - first use: returns (nil, nil); the caller must then Complete or Abort.
+//   - replay of a finished request: returns the stored response.
+//   - different fingerprint: ErrMismatch. Still running: ErrInFlight.
+func (s *Store) Begin(scope, key, fp string) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.sweepLocked(now)
+	id := scope + "\x00" + key
+	if e, ok := s.entries[id]; ok && now.Before(e.expires) {
+		switch {
+		case e.fingerprint != fp:
+			return nil, ErrMismatch
+		case !e.done:
+			return nil, ErrInFlight
+		default:
+			r := e.resp
+			r.Header = e.resp.Header.Clone()
+			return &r, nil
+		}
+	}
+	s.entries[id] = &entry{fingerprint: fp, expires: now.Add(s.ttl)}
+	return nil, nil
+}
+
+// Complete stores the response for a key claimed with Begin.
+func (s *Store) Complete(scope, key string, r Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[scope+"\x00"+key]; ok {
+		e.done, e.resp = true, r
+		e.expires = s.now().Add(s.ttl)
+	}
+}
+
+// Abort forgets a claimed key so the client may retry it (used when the
+// request failed in a way that should not be replayed, e.g. a 5xx).
+func (s *Store) Abort(scope, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, scope+"\x00"+key)
+}
+
+// sweepLocked drops expired entries at most once a minute.
+func (s *Store) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for id, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, id)
+		}
+	}
+}
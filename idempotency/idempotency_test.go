@@ -0,0 +1,57 @@
+package idempotency
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestIdempotency_Store_Lifecycle walks a key through claim, replay,
+// mismatch, in-flight and expiry.
+func TestIdempotency_Store_Lifecycle(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := New(time.Hour)
+	s.now = func() time.Time { return now }
+
+	fp := Fingerprint([]byte("POST"), []byte(`{"a":1}`))
+	if r, err := s.Begin("alice", "k1", fp); r != nil || err != nil {
+		t.Fatalf("first Begin = %v, %v", r, err)
+	}
+	if _, err := s.Begin("alice", "k1", fp); err != ErrInFlight {
+		t.Fatalf("concurrent Begin err = %v, want ErrInFlight", err)
+	}
+	s.Complete("alice", "k1", Response{Status: 201, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte("ok")})
+
+	r, err := s.Begin("alice", "k1", fp)
+	if err != nil || r == nil || r.Status != 201 || string(r.Body) != "ok" {
+		t.Fatalf("replay = %+v, %v", r, err)
+	}
+	if _, err := s.Begin("alice", "k1", Fingerprint([]byte("POST"), []byte(`{"a":2}`))); err != ErrMismatch {
+		t.Fatalf("different payload err = %v, want ErrMismatch", err)
+	}
+	if r, err := s.Begin("bob", "k1", fp); r != nil || err != nil {
+		t.Fatalf("keys must be scoped; bob got %v, %v", r, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if r, err := s.Begin("alice", "k1", fp); r != nil || err != nil {
+		t.Fatalf("expired key should be claimable again; got %v, %v", r, err)
+	}
+	s.Abort("alice", "k1")
+	if r, err := s.Begin("alice", "k1", fp); r != nil || err != nil {
+		t.Fatalf("aborted key should be claimable again; got %v, %v", r, err)
+	}
+}
+
+// TestIdempotency_ValidateKey rejects empty, long and non-printable keys.
+func TestIdempotency_ValidateKey(t *testing.T) {
+	for _, bad := range []string{"", strings.Repeat("x", MaxKeyLen+1), "has space", "tab\t"} {
+		if ValidateKey(bad) == nil {
+			t.Fatalf("ValidateKey(%q) accepted", bad)
+		}
+	}
+	if err := ValidateKey("3f1c-retry_ok.1"); err != nil {
+		t.Fatalf("valid key rejected: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package lists
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"todo-app/trace"
+)
+
+//
+// lists/audit.go (package lists)
+// ------------------------------
+// Append-only audit log of permission changes, one JSON object per line.
+//
+
+// Audit actions.
+const (
+	ActionCreate = "list.create"
+	ActionInvite = "member.invite"
+	ActionRole   = "member.role"
+	ActionRemove = "member.remove"
+)
+
+// AuditEntry records one permission change.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	TraceID string    `json:"trace_id,omitempty"`
+	Actor   string    `json:"actor"`
+	ListID  int       `json:"list_id"`
+	Action  string    `json:"action"`
+	User    string    `json:"user"`
+	OldRole Role      `json:"old_role,omitempty"`
+	NewRole Role      `json:"new_role,omitempty"`
+}
+
+// AuditLog appends entries to a JSON-lines file. It is safe for concurrent use.
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewAuditLog returns a log writing to path (created on first Record).
+func NewAuditLog(path string) *AuditLog { return &AuditLog{path: path} }
+
+// Record appends e, filling in Time and TraceID when unset.
+func (a *AuditLog) Record(ctx context.Context, e AuditEntry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.TraceID == "" {
+		e.TraceID, _ = trace.From(ctx)
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Entries returns the logged entries for listID (all lists when listID is 0).
+func (a *AuditLog) Entries(listID int) ([]AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, err := os.Open(a.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []AuditEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	out := []AuditEntry{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		if listID == 0 || e.ListID == listID {
+			out = append(out, e)
+		}
+	}
+	return out, sc.Err()
+}
@@ -0,0 +1,236 @@
+package lists
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"todo-app/todo"
+)
+
+//
+// lists/lists.go (package lists)
+// ------------------------------
+// Shared lists: a named set of todo.Items plus the users who may see or change
+// it. Like package todo this is pure domain logic; no I/O or logging.
+//
+
+// Role is a member's permission level on a shared list.
+type Role string
+
+const (
+	RoleOwner  Role = "owner"  // everything, including membership changes
+	RoleEditor Role = "editor" // read and change items
+	RoleViewer Role = "viewer" // read only
+)
+
+// Validate ensures the role is one of the allowed values.
+func (r Role) Validate() error {
+	switch r {
+	case RoleOwner, RoleEditor, RoleViewer:
+		return nil
+	default:
+		return fmt.Errorf("invalid role: %q (allowed: %q, %q, %q)", r, RoleOwner, RoleEditor, RoleViewer)
+	}
+}
+
+// CanEdit reports whether the role may change items.
+func (r Role) CanEdit() bool { return r == RoleOwner || r == RoleEditor }
+
+// CanManage reports whether the role may change membership.
+func (r Role) CanManage() bool { return r == RoleOwner }
+
+var (
+	// ErrNotFound is returned for unknown lists and for lists the caller is not a member of.
+	ErrNotFound = errors.New("no such list")
+	// ErrForbidden is returned when the caller's role does not allow the action.
+	ErrForbidden = errors.New("insufficient role")
+	// ErrLastOwner is returned when a change would leave a list without an owner.
+	ErrLastOwner = errors.New("a list must keep at least one owner")
+)
+
+// Member is one user's role on a list.
+type Member struct {
+	User string `json:"user"`
+	Role Role   `json:"role"`
+}
+
+// List is a shared to-do list.
+type List struct {
+	ID        int         `json:"id"`
+	Name      string      `json:"name"`
+	Members   []Member    `json:"members"`
+	Items     []todo.Item `json:"items"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// RoleOf returns user's role on l, or false if user is not a member.
+func (l List) RoleOf(user string) (Role, bool) {
+	for _, m := range l.Members {
+		if m.User == user {
+			return m.Role, true
+		}
+	}
+	return "", false
+}
+
+// owners counts the members with RoleOwner.
+func (l List) owners() int {
+	n := 0
+	for _, m := range l.Members {
+		if m.Role == RoleOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// Clone returns a deep copy so callers cannot alias another list's slices.
+func (l List) Clone() List {
+	l.Members = append([]Member(nil), l.Members...)
+	l.Items = append([]todo.Item(nil), l.Items...)
+	return l
+}
+
+// Create appends a new list owned by owner and returns the updated slice plus the list.
+func Create(all []List, name, owner string) ([]List, List, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return all, List{}, errors.New("list name cannot be empty")
+	}
+	if owner == "" {
+		return all, List{}, errors.New("list owner cannot be empty")
+	}
+	next := 0
+	for _, l := range all {
+		if l.ID > next {
+			next = l.ID
+		}
+	}
+	l := List{
+		ID:        next + 1,
+		Name:      name,
+		Members:   []Member{{User: owner, Role: RoleOwner}},
+		Items:     []todo.Item{},
+		CreatedAt: time.Now(),
+	}
+	return append(all, l), l, nil
+}
+
+// Find returns the list with id as seen by user. Non-members get ErrNotFound
+// so that list ids do not leak to outsiders.
+func Find(all []List, id int, user string) (List, Role, error) {
+	for _, l := range all {
+		if l.ID == id {
+			role, ok := l.RoleOf(user)
+			if !ok {
+				return List{}, "", ErrNotFound
+			}
+			return l, role, nil
+		}
+	}
+	return List{}, "", ErrNotFound
+}
+
+// VisibleTo returns the lists user is a member of.
+func VisibleTo(all []List, user string) []List {
+	out := []List{}
+	for _, l := range all {
+		if _, ok := l.RoleOf(user); ok {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// replace swaps in l for the list with the same id.
+func replace(all []List, l List) []List {
+	for i := range all {
+		if all[i].ID == l.ID {
+			all[i] = l
+		}
+	}
+	return all
+}
+
+// UpdateItems applies fn to the items of list id on behalf of actor, who must
+// be allowed to edit. It returns the updated slice.
+func UpdateItems(all []List, id int, actor string, fn func([]todo.Item) ([]todo.Item, error)) ([]List, error) {
+	l, role, err := Find(all, id, actor)
+	if err != nil {
+		return all, err
+	}
+	if !role.CanEdit() {
+		return all, ErrForbidden
+	}
+	l = l.Clone()
+	items, err := fn(l.Items)
+	if err != nil {
+		return all, err
+	}
+	l.Items = items
+	return replace(all, l), nil
+}
+
+// SetMember adds user to list id with role, or changes their role if already
+// a member. Only owners may do this. The previous role ("" if none) is returned.
+func SetMember(all []List, id int, actor, user string, role Role) ([]List, Role, error) {
+	if err := role.Validate(); err != nil {
+		return all, "", err
+	}
+	if strings.TrimSpace(user) == "" {
+		return all, "", errors.New("member user cannot be empty")
+	}
+	l, actorRole, err := Find(all, id, actor)
+	if err != nil {
+		return all, "", err
+	}
+	if !actorRole.CanManage() {
+		return all, "", ErrForbidden
+	}
+	l = l.Clone()
+	prev, _ := l.RoleOf(user)
+	found := false
+	for i := range l.Members {
+		if l.Members[i].User == user {
+			l.Members[i].Role = role
+			found = true
+		}
+	}
+	if !found {
+		l.Members = append(l.Members, Member{User: user, Role: role})
+	}
+	if l.owners() == 0 {
+		return all, "", ErrLastOwner
+	}
+	return replace(all, l), prev, nil
+}
+
+// RemoveMember removes user from list id. Owners may remove anyone; any member
+// may remove themself. The removed member's role is returned.
+func RemoveMember(all []List, id int, actor, user string) ([]List, Role, error) {
+	l, actorRole, err := Find(all, id, actor)
+	if err != nil {
+		return all, "", err
+	}
+	if actor != user && !actorRole.CanManage() {
+		return all, "", ErrForbidden
+	}
+	l = l.Clone()
+	prev, ok := l.RoleOf(user)
+	if !ok {
+		return all, "", fmt.Errorf("%q is not a member of list %d", user, id)
+	}
+	kept := l.Members[:0]
+	for _, m := range l.Members {
+		if m.User != user {
+			kept = append(kept, m)
+		}
+	}
+	l.Members = kept
+	if l.owners() == 0 {
+		return all, "", ErrLastOwner
+	}
+	return replace(all, l), prev, nil
+}
@@ -0,0 +1,106 @@
+package lists
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"todo-app/todo"
+)
+
+// seedList creates one list owned by "olive" with an editor and a viewer.
+func seedList(t *testing.T) []List {
+	t.Helper()
+	all, l, err := Create(nil, "Project X", "olive")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	all, _, err = SetMember(all, l.ID, "olive", "ed", RoleEditor)
+	if err != nil {
+		t.Fatalf("SetMember(ed): %v", err)
+	}
+	all, _, err = SetMember(all, l.ID, "olive", "vic", RoleViewer)
+	if err != nil {
+		t.Fatalf("SetMember(vic): %v", err)
+	}
+	return all
+}
+
+// TestLists_RolesGateItemChanges verifies editors can change items while
+// viewers and outsiders cannot.
+func TestLists_RolesGateItemChanges(t *testing.T) {
+	all := seedList(t)
+	add := func(items []todo.Item) ([]todo.Item, error) {
+		items, _, err := todo.Add(items, "task", todo.StatusNotStarted)
+		return items, err
+	}
+
+	all, err := UpdateItems(all, 1, "ed", add)
+	if err != nil {
+		t.Fatalf("editor UpdateItems: %v", err)
+	}
+	if _, err := UpdateItems(all, 1, "vic", add); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("viewer err=%v want ErrForbidden", err)
+	}
+	if _, err := UpdateItems(all, 1, "mallory", add); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("outsider err=%v want ErrNotFound", err)
+	}
+	l, role, err := Find(all, 1, "vic")
+	if err != nil || role != RoleViewer || len(l.Items) != 1 {
+		t.Fatalf("Find(vic) = %+v, %q, %v", l, role, err)
+	}
+}
+
+// TestLists_MembershipRules covers owner-only management, self-removal and
+// the last-owner guard.
+func TestLists_MembershipRules(t *testing.T) {
+	all := seedList(t)
+
+	if _, _, err := SetMember(all, 1, "ed", "zed", RoleViewer); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("editor invite err=%v want ErrForbidden", err)
+	}
+	if _, _, err := SetMember(all, 1, "olive", "olive", RoleEditor); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("demote last owner err=%v want ErrLastOwner", err)
+	}
+	if _, _, err := RemoveMember(all, 1, "olive", "olive"); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("remove last owner err=%v want ErrLastOwner", err)
+	}
+	if _, _, err := RemoveMember(all, 1, "vic", "ed"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("viewer removing editor err=%v want ErrForbidden", err)
+	}
+
+	all, prev, err := RemoveMember(all, 1, "vic", "vic")
+	if err != nil || prev != RoleViewer {
+		t.Fatalf("self-remove = %q, %v", prev, err)
+	}
+	all, prev, err = SetMember(all, 1, "olive", "ed", RoleOwner)
+	if err != nil || prev != RoleEditor {
+		t.Fatalf("promote = %q, %v", prev, err)
+	}
+	if got := VisibleTo(all, "vic"); len(got) != 0 {
+		t.Fatalf("removed member still sees %d lists", len(got))
+	}
+	if err := Role("admin").Validate(); err == nil {
+		t.Fatalf("Validate(admin) should fail")
+	}
+}
+
+// TestLists_AuditLogRoundTrip writes entries and reads them back by list.
+func TestLists_AuditLogRoundTrip(t *testing.T) {
+	a := NewAuditLog(filepath.Join(t.TempDir(), "sub", "audit.log"))
+	ctx := context.Background()
+	_ = a.Record(ctx, AuditEntry{Actor: "olive", ListID: 1, Action: ActionInvite, User: "ed", NewRole: RoleEditor})
+	_ = a.Record(ctx, AuditEntry{Actor: "olive", ListID: 2, Action: ActionCreate, User: "olive", NewRole: RoleOwner})
+
+	got, err := a.Entries(1)
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(got) != 1 || got[0].User != "ed" || got[0].Time.IsZero() {
+		t.Fatalf("Entries(1) = %+v", got)
+	}
+	if all, _ := a.Entries(0); len(all) != 2 {
+		t.Fatalf("Entries(0) len=%d want 2", len(all))
+	}
+}
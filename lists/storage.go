@@ -0,0 +1,57 @@
+package lists
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+//
+// lists/storage.go (package lists)
+// --------------------------------
+// JSON persistence for shared lists, mirroring todo/storage.go.
+//
+
+// Save writes all lists to path as pretty-printed JSON, creating the parent directory.
+func Save(ctx context.Context, all []List, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		slog.ErrorContext(ctx, "failed to create output directory", "error", err, "path", path)
+		return err
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal lists", "error", err, "path", path)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		slog.ErrorContext(ctx, "failed to save lists", "error", err, "path", path)
+		return err
+	}
+	slog.InfoContext(ctx, "lists saved", "path", path, "count", len(all))
+	return nil
+}
+
+// Load reads lists from path. A missing or empty file is an empty slice.
+func Load(ctx context.Context, path string) ([]List, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []List{}, nil
+		}
+		slog.ErrorContext(ctx, "failed to read file", "error", err, "path", path)
+		return nil, err
+	}
+	if len(b) == 0 {
+		return []List{}, nil
+	}
+	var all []List
+	if err := json.Unmarshal(b, &all); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal JSON", "error", err, "path", path)
+		return nil, err
+	}
+	return all, nil
+}
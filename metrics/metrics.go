@@ -0,0 +1,362 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//
+// metrics/metrics.go (package metrics)
+// ------------------------------------
+// A deliberately small, dependency-free take on Prometheus instrumentation:
+// labelled counters, gauges and histograms held in a Registry, written out in
+// the Prometheus text exposition format (version 0.0.4).
+//
+// Packages declare their metrics as package-level variables on Default, the
+// same way they log through slog's default logger:
+//
+//	var saves = metrics.Default.NewCounterVec("todo_saves_total", "Saves.", "result")
+//	saves.With("ok").Inc()
+//
+
+// ContentType is the exposition format's media type.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefBuckets are the default histogram buckets, in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default is the process-wide registry served at /metrics.
+var Default = NewRegistry()
+
+// Registry holds metric families.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+// family is one named metric with all its label combinations.
+type family interface {
+	write(w io.Writer, name string) error
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry { return &Registry{families: map[string]family{}} }
+
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.families[name]; dup {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.families[name] = f
+}
+
+// Write writes every metric in the text exposition format, sorted by name.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for n := range r.families {
+		names = append(names, n)
+	}
+	fams := make([]family, len(names))
+	sort.Strings(names)
+	for i, n := range names {
+		fams[i] = r.families[n]
+	}
+	r.mu.Unlock()
+	for i, f := range fams {
+		if err := f.write(w, names[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vec is the label handling shared by every metric type.
+type vec[T any] struct {
+	help   string
+	kind   string
+	labels []string
+	newT   func() *T
+
+	mu     sync.Mutex
+	series map[string]*T
+	values map[string][]string
+}
+
+func newVec[T any](help, kind string, labels []string, newT func() *T) *vec[T] {
+	return &vec[T]{help: help, kind: kind, labels: labels, newT: newT, series: map[string]*T{}, values: map[string][]string{}}
+}
+
+// with returns the series for the given label values, creating it on first use.
+func (v *vec[T]) with(values []string) *T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for %d labels", len(values), len(v.labels)))
+	}
+	key := strings.Join(values, "\xff")
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = v.newT()
+		v.series[key] = s
+		v.values[key] = append([]string(nil), values...)
+	}
+	return s
+}
+
+// each visits series in a stable order.
+func (v *vec[T]) each(fn func(labels string, s *T) error) error {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	type pair struct {
+		labels string
+		s      *T
+	}
+	pairs := make([]pair, len(keys))
+	for i, k := range keys {
+		pairs[i] = pair{formatLabels(v.labels, v.values[k]), v.series[k]}
+	}
+	v.mu.Unlock()
+	for _, p := range pairs {
+		if err := fn(p.labels, p.s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *vec[T]) header(w io.Writer, name string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(v.help), name, v.kind)
+	return err
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	mu sync.Mutex
+	v  float64
+}
+
+// Inc adds one.
+func (c *Counter) Inc() { c.Add(1) }
+
+// Add adds d, which must not be negative.
+func (c *Counter) Add(d float64) {
+	if d < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.mu.Lock()
+	c.v += d
+	c.mu.Unlock()
+}
+
+// Value returns the current count.
+func (c *Counter) Value() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.v
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct{ *vec[Counter] }
+
+// NewCounterVec registers a counter family.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	cv := &CounterVec{newVec(help, "counter", labels, func() *Counter { return &Counter{} })}
+	r.register(name, cv)
+	return cv
+}
+
+// With returns the counter for the label values (in declaration order).
+func (cv *CounterVec) With(values ...string) *Counter { return cv.with(values) }
+
+func (cv *CounterVec) write(w io.Writer, name string) error {
+	if err := cv.header(w, name); err != nil {
+		return err
+	}
+	return cv.each(func(labels string, c *Counter) error {
+		_, err := fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(c.Value()))
+		return err
+	})
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	mu sync.Mutex
+	v  float64
+}
+
+// Set replaces the value.
+func (g *Gauge) Set(v float64) {
+	g.mu.Lock()
+	g.v = v
+	g.mu.Unlock()
+}
+
+// Add changes the value by d.
+func (g *Gauge) Add(d float64) {
+	g.mu.Lock()
+	g.v += d
+	g.mu.Unlock()
+}
+
+// Value returns the current value.
+func (g *Gauge) Value() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.v
+}
+
+// GaugeVec is a gauge partitioned by labels.
+type GaugeVec struct{ *vec[Gauge] }
+
+// NewGaugeVec registers a gauge family.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	gv := &GaugeVec{newVec(help, "gauge", labels, func() *Gauge { return &Gauge{} })}
+	r.register(name, gv)
+	return gv
+}
+
+// With returns the gauge for the label values (in declaration order).
+func (gv *GaugeVec) With(values ...string) *Gauge { return gv.with(values) }
+
+func (gv *GaugeVec) write(w io.Writer, name string) error {
+	if err := gv.header(w, name); err != nil {
+		return err
+	}
+	return gv.each(func(labels string, g *Gauge) error {
+		_, err := fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(g.Value()))
+		return err
+	})
+}
+
+// Histogram counts observations into cumulative buckets.
+type Histogram struct {
+	upper []float64
+
+	mu     sync.Mutex
+	counts []uint64 // per bucket, not cumulative; last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// Observe records one value.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v) // first bucket with upper >= v
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct {
+	*vec[Histogram]
+	buckets []float64
+}
+
+// NewHistogramVec registers a histogram family; nil buckets means DefBuckets.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	hv := &HistogramVec{buckets: b}
+	hv.vec = newVec(help, "histogram", labels, func() *Histogram {
+		return &Histogram{upper: b, counts: make([]uint64, len(b)+1)}
+	})
+	r.register(name, hv)
+	return hv
+}
+
+// With returns the histogram for the label values (in declaration order).
+func (hv *HistogramVec) With(values ...string) *Histogram { return hv.with(values) }
+
+func (hv *HistogramVec) write(w io.Writer, name string) error {
+	if err := hv.header(w, name); err != nil {
+		return err
+	}
+	return hv.each(func(labels string, h *Histogram) error {
+		h.mu.Lock()
+		counts := append([]uint64(nil), h.counts...)
+		sum, count := h.sum, h.count
+		h.mu.Unlock()
+
+		var cum uint64
+		for i, upper := range h.upper {
+			cum += counts[i]
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(labels, "le", formatFloat(upper)), cum); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(labels, "le", "+Inf"), count); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", name, labels, formatFloat(sum), name, labels, count)
+		return err
+	})
+}
+
+// formatLabels renders {a="x",b="y"}, or "" without labels.
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", n, escapeLabel(values[i]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// withLabel appends one more label to an already formatted label set.
+func withLabel(labels, name, value string) string {
+	pair := fmt.Sprintf("%s=\"%s\"", name, value)
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
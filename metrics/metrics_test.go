@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMetrics_Exposition checks the text format for every metric type,
+// including label escaping and cumulative histogram buckets.
+func TestMetrics_Exposition(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("t_requests_total", "Requests served.", "route", "status")
+	g := r.NewGaugeVec("t_items", "Items by status.", "status")
+	h := r.NewHistogramVec("t_latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	plain := r.NewCounterVec("t_plain_total", "No labels.")
+
+	c.With("/add", "201").Inc()
+	c.With("/add", "201").Add(2)
+	c.With(`we"ird\`, "500").Inc()
+	g.With("started").Set(4)
+	g.With("started").Add(-1)
+	for _, v := range []float64{0.05, 0.1, 0.5, 3} {
+		h.With("/add").Observe(v)
+	}
+	plain.With().Inc()
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP t_items Items by status.
+# TYPE t_items gauge
+t_items{status="started"} 3
+# HELP t_latency_seconds Latency.
+# TYPE t_latency_seconds histogram
+t_latency_seconds_bucket{route="/add",le="0.1"} 2
+t_latency_seconds_bucket{route="/add",le="1"} 3
+t_latency_seconds_bucket{route="/add",le="+Inf"} 4
+t_latency_seconds_sum{route="/add"} 3.65
+t_latency_seconds_count{route="/add"} 4
+# HELP t_plain_total No labels.
+# TYPE t_plain_total counter
+t_plain_total 1
+# HELP t_requests_total Requests served.
+# TYPE t_requests_total counter
+t_requests_total{route="/add",status="201"} 3
+t_requests_total{route="we\"ird\\",status="500"} 1
+`
+	if b.String() != want {
+		t.Fatalf("exposition mismatch:\n--- got\n%s--- want\n%s", b.String(), want)
+	}
+}
+
+// TestMetrics_Misuse panics on programming errors rather than emitting
+// malformed output.
+func TestMetrics_Misuse(t *testing.T) {
+	r := NewRegistry()
+	cv := r.NewCounterVec("x_total", "x", "a")
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("%s did not panic", name)
+			}
+		}()
+		fn()
+	}
+	mustPanic("duplicate", func() { r.NewCounterVec("x_total", "again") })
+	mustPanic("label count", func() { cv.With("a", "b") })
+	mustPanic("negative add", func() { cv.With("a").Add(-1) })
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//
+// ratelimit/ratelimit.go (package ratelimit)
+// ------------------------------------------
+// Token buckets keyed by an arbitrary string (client IP, API key id, ...).
+// Each key may burst up to Burst requests and then refills at Rate requests
+// per second. Idle buckets are swept so the map does not grow without bound.
+//
+
+// Limiter holds one bucket per key. A nil *Limiter allows everything.
+type Limiter struct {
+	rate  float64 // tokens per second
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New returns a limiter refilling rate tokens per second up to burst.
+// burst < 1 is treated as 1.
+func New(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: rate, burst: float64(burst), now: time.Now, buckets: map[string]*bucket{}}
+}
+
+// Parse builds a Limiter from "rate[:burst]", e.g. "10" or "10:20" (burst
+// defaults to twice the rate). "0" or "off" disables limiting (nil Limiter).
+func Parse(spec string) (*Limiter, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "0" || strings.EqualFold(spec, "off") {
+		return nil, nil
+	}
+	rateStr, burstStr, hasBurst := strings.Cut(spec, ":")
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate <= 0 {
+		return nil, fmt.Errorf("invalid rate limit %q: want requests per second like 10 or 10:20", spec)
+	}
+	burst := int(math.Ceil(rate * 2))
+	if hasBurst {
+		if burst, err = strconv.Atoi(burstStr); err != nil || burst < 1 {
+			return nil, fmt.Errorf("invalid rate limit burst in %q", spec)
+		}
+	}
+	return New(rate, burst), nil
+}
+
+// Allow takes a token for key. When none is left it returns false and how
+// long until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweepLocked(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweepLocked forgets buckets that have been idle long enough to be full.
+func (l *Limiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for k, b := range l.buckets {
+		if now.Sub(b.last) > full {
+			delete(l.buckets, k)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRateLimit_BurstRefillAndKeys checks burst, Retry-After style waits,
+// refill over time and that keys have independent buckets.
+func TestRateLimit_BurstRefillAndKeys(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := New(2, 3) // 2/s, burst 3
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d within burst rejected", i+1)
+		}
+	}
+	ok, wait := l.Allow("a")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("over burst: ok=%v wait=%v, want false 500ms", ok, wait)
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Fatalf("key b should have its own bucket")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatalf("token should have refilled")
+	}
+
+	var nilLimiter *Limiter
+	if ok, _ := nilLimiter.Allow("x"); !ok {
+		t.Fatalf("nil limiter must allow")
+	}
+}
+
+// TestRateLimit_Parse covers the rate[:burst] syntax.
+func TestRateLimit_Parse(t *testing.T) {
+	if l, err := Parse("off"); err != nil || l != nil {
+		t.Fatalf("off = %v, %v", l, err)
+	}
+	l, err := Parse("5")
+	if err != nil || l.rate != 5 || l.burst != 10 {
+		t.Fatalf("5 = %+v, %v", l, err)
+	}
+	if l, err = Parse("0.5:1"); err != nil || l.rate != 0.5 || l.burst != 1 {
+		t.Fatalf("0.5:1 = %+v, %v", l, err)
+	}
+	for _, bad := range []string{"x", "-1", "5:0", "5:y"} {
+		if _, err := Parse(bad); err == nil {
+			t.Fatalf("Parse(%q) accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+//
+// repl/editor.go (package repl)
+// -----------------------------
+// A small line editor for a terminal in raw mode: cursor movement, Emacs
+// style kill keys, history on Up/Down, and Tab completion. It only needs an
+// io.Reader of keystrokes and an io.Writer that understands basic ANSI
+// escapes, so tests drive it with byte strings.
+//
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl+C.
+var ErrInterrupted = errors.New("interrupted")
+
+// maxHistory bounds the history kept in memory and on disk.
+const maxHistory = 500
+
+// Key codes handled by the editor.
+const (
+	keyCtrlA     = 0x01
+	keyCtrlB     = 0x02
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyCtrlE     = 0x05
+	keyCtrlF     = 0x06
+	keyCtrlH     = 0x08
+	keyTab       = 0x09
+	keyLF        = 0x0a
+	keyCtrlK     = 0x0b
+	keyCR        = 0x0d
+	keyCtrlN     = 0x0e
+	keyCtrlP     = 0x10
+	keyCtrlU     = 0x15
+	keyCtrlW     = 0x17
+	keyEsc       = 0x1b
+	keyBackspace = 0x7f
+)
+
+// Editor reads lines with editing. The zero value works; set Complete to
+// enable Tab completion.
+type Editor struct {
+	// Complete returns candidate completions of the line left of the cursor,
+	// each being the whole completed text.
+	Complete func(line string) []string
+
+	history []string
+}
+
+// History returns the remembered lines, oldest first.
+func (e *Editor) History() []string { return append([]string(nil), e.history...) }
+
+// SetHistory replaces the remembered lines (e.g. loaded from a file).
+func (e *Editor) SetHistory(lines []string) {
+	if len(lines) > maxHistory {
+		lines = lines[len(lines)-maxHistory:]
+	}
+	e.history = append([]string(nil), lines...)
+}
+
+// addHistory remembers line unless it is blank or repeats the last entry.
+func (e *Editor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[1:]
+	}
+}
+
+// lineState is the line being edited.
+type lineState struct {
+	w      io.Writer
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+// refresh redraws the prompt and buffer and places the cursor.
+func (l *lineState) refresh() {
+	fmt.Fprintf(l.w, "\r%s%s\x1b[K", l.prompt, string(l.buf))
+	if back := len(l.buf) - l.pos; back > 0 {
+		fmt.Fprintf(l.w, "\x1b[%dD", back)
+	}
+}
+
+func (l *lineState) set(s string) {
+	l.buf = []rune(s)
+	l.pos = len(l.buf)
+}
+
+func (l *lineState) insert(r rune) {
+	l.buf = append(l.buf[:l.pos], append([]rune{r}, l.buf[l.pos:]...)...)
+	l.pos++
+}
+
+// deleteRange removes buf[from:to] and leaves the cursor at from.
+func (l *lineState) deleteRange(from, to int) {
+	l.buf = append(l.buf[:from], l.buf[to:]...)
+	l.pos = from
+}
+
+// ReadLine shows prompt and edits one line from r (a terminal in raw mode).
+// It returns io.EOF for Ctrl+D on an empty line and ErrInterrupted for Ctrl+C.
+func (e *Editor) ReadLine(r *bufio.Reader, w io.Writer, prompt string) (string, error) {
+	l := &lineState{w: w, prompt: prompt}
+	// hist indexes e.history while browsing; len(e.history) is the new line.
+	hist, draft := len(e.history), ""
+	l.refresh()
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(l.buf) > 0 {
+				break
+			}
+			return "", err
+		}
+		switch c {
+		case keyCR, keyLF:
+			fmt.Fprint(w, "\r\n")
+			line := string(l.buf)
+			e.addHistory(line)
+			return line, nil
+		case keyCtrlC:
+			fmt.Fprint(w, "^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(l.buf) == 0 {
+				fmt.Fprint(w, "\r\n")
+				return "", io.EOF
+			}
+			if l.pos < len(l.buf) {
+				l.deleteRange(l.pos, l.pos+1)
+			}
+		case keyBackspace, keyCtrlH:
+			if l.pos > 0 {
+				l.deleteRange(l.pos-1, l.pos)
+			}
+		case keyCtrlA:
+			l.pos = 0
+		case keyCtrlE:
+			l.pos = len(l.buf)
+		case keyCtrlB:
+			l.pos = max(l.pos-1, 0)
+		case keyCtrlF:
+			l.pos = min(l.pos+1, len(l.buf))
+		case keyCtrlK:
+			l.buf = l.buf[:l.pos]
+		case keyCtrlU:
+			l.deleteRange(0, l.pos)
+		case keyCtrlW:
+			from := l.pos
+			for from > 0 && unicode.IsSpace(l.buf[from-1]) {
+				from--
+			}
+			for from > 0 && !unicode.IsSpace(l.buf[from-1]) {
+				from--
+			}
+			l.deleteRange(from, l.pos)
+		case keyCtrlP, keyCtrlN:
+			hist, draft = e.browse(l, hist, draft, c == keyCtrlP)
+		case keyTab:
+			e.complete(l)
+		case keyEsc:
+			e.escape(r, l, &hist, &draft)
+		default:
+			if unicode.IsPrint(c) {
+				l.insert(c)
+			}
+		}
+		l.refresh()
+	}
+	fmt.Fprint(w, "\r\n")
+	return string(l.buf), nil
+}
+
+// escape handles ANSI sequences: arrows, Home/End and Delete.
+func (e *Editor) escape(r *bufio.Reader, l *lineState, hist *int, draft *string) {
+	b, err := r.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return
+	}
+	code, err := r.ReadByte()
+	if err != nil {
+		return
+	}
+	if code >= '0' && code <= '9' { // ESC [ n ~
+		if t, err := r.ReadByte(); err != nil || t != '~' {
+			return
+		}
+		switch code {
+		case '1', '7':
+			l.pos = 0
+		case '4', '8':
+			l.pos = len(l.buf)
+		case '3':
+			if l.pos < len(l.buf) {
+				l.deleteRange(l.pos, l.pos+1)
+			}
+		}
+		return
+	}
+	switch code {
+	case 'A':
+		*hist, *draft = e.browse(l, *hist, *draft, true)
+	case 'B':
+		*hist, *draft = e.browse(l, *hist, *draft, false)
+	case 'C':
+		l.pos = min(l.pos+1, len(l.buf))
+	case 'D':
+		l.pos = max(l.pos-1, 0)
+	case 'H':
+		l.pos = 0
+	case 'F':
+		l.pos = len(l.buf)
+	}
+}
+
+// browse moves through history. The unfinished line is kept as draft and
+// comes back after the newest entry.
+func (e *Editor) browse(l *lineState, hist int, draft string, older bool) (int, string) {
+	if hist == len(e.history) {
+		draft = string(l.buf)
+	}
+	switch {
+	case older && hist > 0:
+		hist--
+	case !older && hist < len(e.history):
+		hist++
+	default:
+		return hist, draft
+	}
+	if hist == len(e.history) {
+		l.set(draft)
+	} else {
+		l.set(e.history[hist])
+	}
+	return hist, draft
+}
+
+// complete applies Tab at the end of the line: a single candidate is taken
+// (plus a space), several extend the line to their common prefix or, when
+// that adds nothing, are listed below the prompt.
+func (e *Editor) complete(l *lineState) {
+	if e.Complete == nil || l.pos != len(l.buf) {
+		return
+	}
+	line := string(l.buf)
+	cands := e.Complete(line)
+	switch len(cands) {
+	case 0:
+		return
+	case 1:
+		l.set(cands[0] + " ")
+		return
+	}
+	prefix := cands[0]
+	for _, c := range cands[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(prefix) > len(line) {
+		l.set(prefix)
+		return
+	}
+	words := make([]string, len(cands))
+	for i, c := range cands {
+		words[i] = c[strings.LastIndexByte(c, ' ')+1:]
+	}
+	fmt.Fprintf(l.w, "\r\n%s\r\n", strings.Join(words, "  "))
+}
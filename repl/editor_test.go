@@ -0,0 +1,86 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// readLine feeds keys to e and returns the line and error.
+func readLine(e *Editor, keys string) (string, string, error) {
+	var out strings.Builder
+	line, err := e.ReadLine(bufio.NewReader(strings.NewReader(keys)), &out, "> ")
+	return line, out.String(), err
+}
+
+// TestREPL_Editor_Editing covers insertion, cursor movement and kill keys.
+func TestREPL_Editor_Editing(t *testing.T) {
+	cases := []struct{ keys, want string }{
+		{"hello\r", "hello"},
+		{"helo\x1b[D\x1b[Dl\r", "hello"},                // left twice, insert
+		{"hellox\x7f\r", "hello"},                       // backspace
+		{"world\x01hello \r", "hello world"},            // Ctrl+A then type
+		{"hello world\x17\r", "hello "},                 // Ctrl+W
+		{"hello world\x01\x0b\r", ""},                   // Ctrl+A, Ctrl+K
+		{"abc\x1b[D\x15\r", "c"},                        // Ctrl+U kills left of cursor
+		{"abc\x01\x1b[3~\r", "bc"},                      // Delete key
+		{"abc\x01\x04\x1b[F!\r", "bc!"},                 // Ctrl+D deletes, End key
+		{"héllo\x1b[D\x1b[D\x1b[D\x1b[D\x7f\r", "éllo"}, // runes, not bytes
+	}
+	for _, tc := range cases {
+		got, _, err := readLine(&Editor{}, tc.keys)
+		if err != nil || got != tc.want {
+			t.Fatalf("keys %q: got %q, %v; want %q", tc.keys, got, err, tc.want)
+		}
+	}
+}
+
+// TestREPL_Editor_HistoryAndExit covers Up/Down history, Ctrl+D and Ctrl+C.
+func TestREPL_Editor_HistoryAndExit(t *testing.T) {
+	e := &Editor{}
+	for _, l := range []string{"add one\r", "list\r", "list\r", "\r"} {
+		_, _, _ = readLine(e, l)
+	}
+	if h := e.History(); len(h) != 2 {
+		t.Fatalf("history = %q, want duplicates and blanks skipped", h)
+	}
+	if got, _, _ := readLine(e, "\x1b[A\x1b[A\r"); got != "add one" {
+		t.Fatalf("Up Up = %q", got)
+	}
+	if got, _, _ := readLine(e, "dra\x1b[A\x1b[Bft\r"); got != "draft" {
+		t.Fatalf("Up then Down should restore the draft, got %q", got)
+	}
+	if _, _, err := readLine(e, "\x04"); !errors.Is(err, io.EOF) {
+		t.Fatalf("Ctrl+D on empty line = %v, want EOF", err)
+	}
+	if _, _, err := readLine(e, "abc\x03"); !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("Ctrl+C = %v, want ErrInterrupted", err)
+	}
+}
+
+// TestREPL_Editor_TabCompletion covers single, common-prefix and listed
+// completions.
+func TestREPL_Editor_TabCompletion(t *testing.T) {
+	words := []string{"delete", "done", "add"}
+	e := &Editor{Complete: func(line string) []string {
+		var out []string
+		for _, w := range words {
+			if strings.HasPrefix(w, line) {
+				out = append(out, w)
+			}
+		}
+		return out
+	}}
+	if got, _, _ := readLine(e, "a\t\r"); got != "add " {
+		t.Fatalf("single completion = %q", got)
+	}
+	if got, out, _ := readLine(e, "d\t\r"); got != "d" || !strings.Contains(out, "delete  done") {
+		t.Fatalf("ambiguous completion = %q, output %q", got, out)
+	}
+	words = []string{"started", "start"}
+	if got, _, _ := readLine(e, "s\t\r"); got != "start" {
+		t.Fatalf("common prefix = %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+package repl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"todo-app/term"
+)
+
+//
+// repl/repl.go (package repl)
+// ---------------------------
+// The read-eval-print loop. On a terminal it switches to raw mode and reads
+// lines with Editor (history, Tab completion); otherwise (pipes, files,
+// unsupported platforms) it reads plain lines. Lines are read on a separate
+// goroutine so a cancelled ctx (SIGTERM, or Ctrl+C outside raw mode) ends
+// the loop even while waiting for input.
+//
+
+// Prompt is shown before each command.
+const Prompt = "todo> "
+
+// Run executes commands from in until quit, Ctrl+D, Ctrl+C or ctx is done.
+// History is loaded from and saved to historyPath when it is non-empty.
+func Run(ctx context.Context, sess *Session, in *os.File, out io.Writer, historyPath string) error {
+	ed := &Editor{Complete: func(line string) []string { return sess.Complete(ctx, line) }}
+	if historyPath != "" {
+		ed.SetHistory(loadHistory(historyPath))
+	}
+
+	restore, err := term.MakeRaw(int(in.Fd()))
+	raw := err == nil
+	if raw {
+		defer restore()
+	}
+
+	type result struct {
+		line string
+		err  error
+	}
+	next := make(chan struct{})
+	results := make(chan result, 1)
+	go func() {
+		br := bufio.NewReader(in)
+		for range next {
+			var r result
+			if raw {
+				r.line, r.err = ed.ReadLine(br, out, Prompt)
+			} else {
+				r.line, r.err = readPlain(br)
+			}
+			results <- r
+		}
+	}()
+	defer close(next)
+
+	if !raw {
+		fmt.Fprintln(out, `Type "help" for commands.`)
+	} else {
+		fmt.Fprintln(out, `Type "help" for commands; Tab completes, Up/Down recall history.`)
+	}
+	for {
+		if !raw {
+			fmt.Fprint(out, Prompt)
+		}
+		next <- struct{}{}
+		var r result
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(out)
+			return saveHistory(historyPath, ed)
+		case r = <-results:
+		}
+		if r.err != nil {
+			if errors.Is(r.err, io.EOF) || errors.Is(r.err, ErrInterrupted) {
+				return saveHistory(historyPath, ed)
+			}
+			return r.err
+		}
+		if !raw {
+			ed.addHistory(r.line)
+		}
+		if err := sess.Execute(ctx, r.line); err != nil {
+			if errors.Is(err, errQuit) {
+				return saveHistory(historyPath, ed)
+			}
+			fmt.Fprintf(out, "error: %v\n", err)
+		}
+	}
+}
+
+// readPlain reads one line without editing; the history still records it.
+func readPlain(br *bufio.Reader) (string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// loadHistory reads one entry per line; a missing file is empty history.
+func loadHistory(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil || strings.TrimSpace(string(data)) == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+}
+
+// saveHistory writes the editor's history to path (nothing when path is "").
+func saveHistory(path string, ed *Editor) error {
+	h := ed.History()
+	if path == "" || len(h) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(h, "\n")+"\n"), 0o644)
+}
@@ -0,0 +1,77 @@
+package repl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pipeInput returns a read end that yields input and then EOF.
+func pipeInput(t *testing.T, input string) *os.File {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %v", err)
+	}
+	go func() {
+		_, _ = w.WriteString(input)
+		_ = w.Close()
+	}()
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+// TestREPL_Run_PlainInputAndHistory runs commands from a pipe (no raw mode),
+// reports errors without stopping, stops on quit and saves history.
+func TestREPL_Run_PlainInputAndHistory(t *testing.T) {
+	s, store, sessOut := newSession(t)
+	var out bytes.Buffer
+	hist := filepath.Join(t.TempDir(), "out", "repl_history")
+	in := pipeInput(t, "add Buy milk\nbogus\nlist\nquit\nadd never\n")
+
+	if err := Run(context.Background(), s, in, &out, hist); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(sessOut.String(), "added #1 Buy milk") || !strings.Contains(out.String(), `error: unknown command "bogus"`) {
+		t.Fatalf("output:\n%s\n%s", sessOut.String(), out.String())
+	}
+	if list, _ := store.Load(context.Background()); len(list) != 1 {
+		t.Fatalf("commands after quit ran: %+v", list)
+	}
+	data, err := os.ReadFile(hist)
+	if err != nil || string(data) != "add Buy milk\nbogus\nlist\nquit\n" {
+		t.Fatalf("history file = %q, %v", data, err)
+	}
+}
+
+// TestREPL_Run_EOFAndCancel ends on end of input and on a cancelled context
+// while waiting for a line.
+func TestREPL_Run_EOFAndCancel(t *testing.T) {
+	s, _, _ := newSession(t)
+	if err := Run(context.Background(), s, pipeInput(t, "help\n"), &bytes.Buffer{}, ""); err != nil {
+		t.Fatalf("Run until EOF: %v", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close(); _ = w.Close() })
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Run(ctx, s, r, &bytes.Buffer{}, "") }()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run after cancel: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Run did not return after ctx was cancelled")
+	}
+}
@@ -0,0 +1,246 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+//
+// repl/session.go (package repl)
+// ------------------------------
+// A Session executes REPL commands against one service.Store kept open for
+// the whole session. Every change goes through service.Update and pushes the
+// items as they were before it, so `undo` can put them back.
+//
+
+// maxUndo bounds how many changes `undo` can revert.
+const maxUndo = 100
+
+// errQuit is returned by Execute for quit/exit.
+var errQuit = errors.New("quit")
+
+// Commands lists the REPL commands in help order; completion uses it too.
+var Commands = []string{"add", "list", "start", "done", "edit", "delete", "undo", "help", "quit"}
+
+// idCommands take an item id as their first argument.
+var idCommands = map[string]bool{"start": true, "done": true, "edit": true, "delete": true}
+
+const helpText = `Commands:
+  add <description>        add an item (not started)
+  list [all|open|started|completed]
+                           show items (default: all)
+  start <id>               mark an item started
+  done <id>                mark an item completed
+  edit <id> <description>  change an item's description
+  delete <id>              delete an item
+  undo                     revert the last change made in this session
+  help                     show this help
+  quit                     leave (also Ctrl+D or Ctrl+C)
+`
+
+// Session runs commands against store, writing results to out.
+type Session struct {
+	store service.Store
+	out   io.Writer
+	undo  [][]todo.Item
+}
+
+// NewSession returns a session on store.
+func NewSession(store service.Store, out io.Writer) *Session {
+	return &Session{store: store, out: out}
+}
+
+// Execute runs one command line. Errors are meant for the user; errQuit
+// ends the session.
+func (s *Session) Execute(ctx context.Context, line string) error {
+	cmd, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToLower(cmd) {
+	case "":
+		return nil
+	case "help", "?":
+		fmt.Fprint(s.out, helpText)
+		return nil
+	case "quit", "exit":
+		return errQuit
+	case "list", "ls":
+		return s.list(ctx, rest)
+	case "add":
+		var added todo.Item
+		err := s.change(ctx, func(list []todo.Item) ([]todo.Item, error) {
+			var err error
+			list, added, err = todo.Add(list, rest, todo.StatusNotStarted)
+			return list, err
+		})
+		if err == nil {
+			fmt.Fprintf(s.out, "added #%d %s\n", added.ID, added.Description)
+		}
+		return err
+	case "start", "done":
+		st := todo.StatusStarted
+		if strings.EqualFold(cmd, "done") {
+			st = todo.StatusCompleted
+		}
+		id, _, err := parseID(cmd, rest)
+		if err != nil {
+			return err
+		}
+		err = s.change(ctx, func(list []todo.Item) ([]todo.Item, error) {
+			return todo.UpdateStatus(list, id, st)
+		})
+		if err == nil {
+			fmt.Fprintf(s.out, "#%d is %s\n", id, st)
+		}
+		return err
+	case "edit":
+		id, desc, err := parseID(cmd, rest)
+		if err != nil {
+			return err
+		}
+		err = s.change(ctx, func(list []todo.Item) ([]todo.Item, error) {
+			return todo.UpdateDescription(list, id, desc)
+		})
+		if err == nil {
+			fmt.Fprintf(s.out, "#%d updated\n", id)
+		}
+		return err
+	case "delete", "rm":
+		id, _, err := parseID(cmd, rest)
+		if err != nil {
+			return err
+		}
+		err = s.change(ctx, func(list []todo.Item) ([]todo.Item, error) {
+			return todo.Delete(list, id)
+		})
+		if err == nil {
+			fmt.Fprintf(s.out, "deleted #%d\n", id)
+		}
+		return err
+	case "undo":
+		return s.revert(ctx)
+	default:
+		return fmt.Errorf("unknown command %q (type help)", cmd)
+	}
+}
+
+// parseID reads "<id> [rest]" for cmd.
+func parseID(cmd, args string) (int, string, error) {
+	idStr, rest, _ := strings.Cut(args, " ")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return 0, "", fmt.Errorf("usage: %s <id>", cmd)
+	}
+	return id, strings.TrimSpace(rest), nil
+}
+
+// change applies fn as one store update and remembers the previous items.
+func (s *Session) change(ctx context.Context, fn func([]todo.Item) ([]todo.Item, error)) error {
+	var before []todo.Item
+	err := service.Update(ctx, s.store, func(list []todo.Item) ([]todo.Item, error) {
+		before = append([]todo.Item(nil), list...)
+		return fn(list)
+	})
+	if err != nil {
+		return err
+	}
+	s.undo = append(s.undo, before)
+	if len(s.undo) > maxUndo {
+		s.undo = s.undo[1:]
+	}
+	return nil
+}
+
+// revert restores the items saved by the latest change.
+func (s *Session) revert(ctx context.Context) error {
+	if len(s.undo) == 0 {
+		return errors.New("nothing to undo")
+	}
+	prev := s.undo[len(s.undo)-1]
+	err := service.Update(ctx, s.store, func([]todo.Item) ([]todo.Item, error) {
+		return append([]todo.Item(nil), prev...), nil
+	})
+	if err != nil {
+		return err
+	}
+	s.undo = s.undo[:len(s.undo)-1]
+	fmt.Fprintln(s.out, "undone")
+	return nil
+}
+
+// list prints items, optionally only those with one status ("open" means
+// not completed).
+func (s *Session) list(ctx context.Context, filter string) error {
+	items, err := s.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	filter = strings.ToLower(filter)
+	keep := func(it todo.Item) bool { return true }
+	switch filter {
+	case "", "all":
+	case "open":
+		keep = func(it todo.Item) bool { return it.Status != todo.StatusCompleted }
+	default:
+		st := todo.Status(filter)
+		if err := st.Validate(); err != nil {
+			return fmt.Errorf("list: %w", err)
+		}
+		keep = func(it todo.Item) bool { return it.Status == st }
+	}
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDESCRIPTION\tSTATUS\tCREATED")
+	for _, it := range items {
+		if keep(it) {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", it.ID, it.Description, it.Status, it.CreatedAt.Format(time.RFC3339))
+		}
+	}
+	return w.Flush()
+}
+
+// Complete returns the completions of line: command names for the first
+// word, item ids after an id-taking command, and list filters after list.
+// Each candidate is the whole completed line.
+func (s *Session) Complete(ctx context.Context, line string) []string {
+	cmd, arg, hasArg := strings.Cut(line, " ")
+	var words []string
+	switch {
+	case !hasArg:
+		words = Commands
+	case idCommands[cmd] && !strings.Contains(arg, " "):
+		items, err := s.store.Load(ctx)
+		if err != nil {
+			return nil
+		}
+		for _, it := range items {
+			words = append(words, strconv.Itoa(it.ID))
+		}
+		sort.Strings(words)
+	case cmd == "list" && !strings.Contains(arg, " "):
+		words = []string{"all", "open", "started", "completed"}
+	default:
+		return nil
+	}
+	prefix := line
+	if hasArg {
+		prefix, line = arg, cmd+" "
+	} else {
+		line = ""
+	}
+	var out []string
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			out = append(out, line+w)
+		}
+	}
+	return out
+}
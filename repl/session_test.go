@@ -0,0 +1,107 @@
+package repl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+// newSession returns a session on a fresh ActorStore and its output buffer.
+func newSession(t *testing.T) (*Session, *service.ActorStore, *bytes.Buffer) {
+	t.Helper()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	store := service.NewActorStore(filepath.Join(t.TempDir(), "todos.json"))
+	t.Cleanup(func() { _ = store.Close() })
+	var out bytes.Buffer
+	return NewSession(store, &out), store, &out
+}
+
+// TestREPL_Session_CommandsAndUndo walks through every command and undoes
+// changes in reverse order.
+func TestREPL_Session_CommandsAndUndo(t *testing.T) {
+	s, store, out := newSession(t)
+	ctx := context.Background()
+	exec := func(line string) {
+		t.Helper()
+		if err := s.Execute(ctx, line); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+	}
+	items := func() []todo.Item {
+		list, _ := store.Load(ctx)
+		return list
+	}
+
+	exec("add Buy milk")
+	exec("add Write report")
+	exec("start 1")
+	exec("done 2")
+	exec("edit 1 Buy oat milk")
+	if got := items(); got[0].Description != "Buy oat milk" || got[0].Status != todo.StatusStarted || got[1].Status != todo.StatusCompleted {
+		t.Fatalf("after edits: %+v", got)
+	}
+	out.Reset()
+	exec("list open")
+	if !strings.Contains(out.String(), "Buy oat milk") || strings.Contains(out.String(), "Write report") {
+		t.Fatalf("list open:\n%s", out)
+	}
+	exec("delete 2")
+	if len(items()) != 1 {
+		t.Fatalf("delete did not remove #2: %+v", items())
+	}
+
+	exec("undo") // delete
+	exec("undo") // edit
+	if got := items(); len(got) != 2 || got[0].Description != "Buy milk" {
+		t.Fatalf("after two undos: %+v", got)
+	}
+	for i := 0; i < 4; i++ {
+		exec("undo")
+	}
+	if len(items()) != 0 {
+		t.Fatalf("undoing everything should leave no items: %+v", items())
+	}
+	if err := s.Execute(ctx, "undo"); err == nil {
+		t.Fatalf("undo with nothing left should fail")
+	}
+
+	for _, bad := range []string{"start", "done x", "edit 9 nope", "frobnicate", "list bogus", "add "} {
+		if err := s.Execute(ctx, bad); err == nil {
+			t.Fatalf("%q should fail", bad)
+		}
+	}
+	if err := s.Execute(ctx, "quit"); err != errQuit {
+		t.Fatalf("quit = %v", err)
+	}
+}
+
+// TestREPL_Session_Complete completes command names, ids and list filters.
+func TestREPL_Session_Complete(t *testing.T) {
+	s, _, _ := newSession(t)
+	ctx := context.Background()
+	for _, d := range []string{"a", "b", "c"} {
+		_ = s.Execute(ctx, "add "+d)
+	}
+	cases := map[string][]string{
+		"d":       {"done", "delete"},
+		"ad":      {"add"},
+		"done ":   {"done 1", "done 2", "done 3"},
+		"edit 2":  {"edit 2"},
+		"list st": {"list started"},
+		"add x":   nil,
+		"edit 1 ": nil,
+	}
+	for line, want := range cases {
+		if got := s.Complete(ctx, line); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Complete(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
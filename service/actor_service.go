@@ -2,10 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"todo-app/todo"
+	"todo-app/trace"
 )
 
 // ActorStore is a concurrency-safe implementation of Store, using the
@@ -14,8 +21,11 @@ import (
 // that writes are applied one-at-a-time.
 //
 // Zero shared mutable state is exposed; callers interact via messages.
+// Every committed Save is also published as created/updated/deleted events
+// (see Subscribe), with a bounded buffer for resuming subscribers.
 type ActorStore struct {
-	path string
+	path     string
+	onCommit func(context.Context, []Event) // see CommitFunc; may be nil
 
 	cmds chan any
 	quit chan struct{}
@@ -24,36 +34,125 @@ type ActorStore struct {
 // NewActorStore spins up the actor and loads the initial snapshot from disk.
 // Use Close() to stop the background goroutine.
 func NewActorStore(path string) *ActorStore {
+	return newActorStore(path, nil)
+}
+
+// newActorStore is NewActorStore with a function called with the events of
+// every commit before it is acknowledged.
+func newActorStore(path string, onCommit func(context.Context, []Event)) *ActorStore {
 	s := &ActorStore{
-		path: path,
-		cmds: make(chan any),
-		quit: make(chan struct{}),
+		path:     path,
+		onCommit: onCommit,
+		cmds:     make(chan any),
+		quit:     make(chan struct{}),
 	}
 	go s.loop()
 	return s
 }
 
+// errStoreClosed is returned by calls made after Close.
+var errStoreClosed = errors.New("store closed")
+
 // internal message types
 type (
 	getReq struct {
 		ctx   context.Context
+		sent  time.Time
 		reply chan []todo.Item
 	}
 
 	setReq struct {
 		ctx   context.Context
+		sent  time.Time
 		list  []todo.Item
 		reply chan error
 	}
 
+	updateReq struct {
+		ctx   context.Context
+		sent  time.Time
+		fn    func([]todo.Item) ([]todo.Item, error)
+		reply chan error
+	}
+
+	pingReq struct {
+		reply chan struct{}
+	}
+
 	stopReq struct {
+		reply chan error
+	}
+
+	subReq struct {
+		lastID uint64
+		resume bool
+		reply  chan subResp
+	}
+
+	subResp struct {
+		id      int
+		ch      chan Event
+		backlog []Event
+		gap     bool
+	}
+
+	unsubReq struct {
+		id int
+	}
+
+	dropSubsReq struct {
 		done chan struct{}
 	}
 )
 
 func (s *ActorStore) loop() {
-	// private, goroutine-owned state
+	// private, goroutine-owned state; dirty means snapshot differs from disk
+	// because the last Save failed
 	var snapshot []todo.Item
+	var dirty bool
+
+	// change feed state: event ids start from the wall clock so they keep
+	// increasing across restarts and stale Last-Event-IDs read as gaps.
+	var (
+		lastEventID = uint64(time.Now().UnixMicro())
+		revision    uint64
+		ring        = newEventRing(eventBufferSize)
+		subs        = map[int]chan Event{}
+		nextSub     int
+	)
+	dropSubs := func() {
+		for id, ch := range subs {
+			close(ch)
+			delete(subs, id)
+		}
+	}
+	defer dropSubs()
+	// publish sends what changed between two committed snapshots to
+	// subscribers, and all of it to onCommit.
+	publish := func(ctx context.Context, before, after []todo.Item) {
+		revision++
+		now := time.Now()
+		events := diffItems(before, after)
+		for i := range events {
+			lastEventID++
+			events[i].ID, events[i].Revision, events[i].Time = lastEventID, revision, now
+			ev := events[i]
+			ring.push(ev)
+			for id, ch := range subs {
+				select {
+				case ch <- ev:
+				default:
+					// too far behind: drop it; the client resumes via Last-Event-ID
+					close(ch)
+					delete(subs, id)
+				}
+			}
+		}
+		if s.onCommit != nil && len(events) > 0 {
+			s.onCommit(ctx, events)
+		}
+	}
+
 	// load once at startup; treat missing file as empty list
 	{
 		ctx := context.Background()
@@ -63,6 +162,7 @@ func (s *ActorStore) loop() {
 			list = []todo.Item{}
 		}
 		snapshot = cloneList(list)
+		recordSnapshot(s.path, snapshot)
 	}
 
 	for {
@@ -71,16 +171,89 @@ func (s *ActorStore) loop() {
 			switch m := msg.(type) {
 			case getReq:
 				// return a copy to avoid races with callers
+				observeQueueWait("get", m.sent)
+				_, span := trace.Start(m.ctx, "ActorStore.get",
+					"actor.queue_wait_ms", time.Since(m.sent).Milliseconds(), "todo.count", len(snapshot),
+				)
 				m.reply <- cloneList(snapshot)
+				span.End()
 
 			case setReq:
 				// replace in-memory snapshot then persist to disk
+				ctx, span := trace.Start(m.ctx, "ActorStore.set",
+					"actor.queue_wait_ms", time.Since(m.sent).Milliseconds(), "todo.count", len(m.list),
+					"file.path", s.path,
+				)
+				observeQueueWait("set", m.sent)
+				before := snapshot
 				snapshot = cloneList(m.list)
-				err := todo.Save(m.ctx, snapshot, s.path)
+				start := time.Now()
+				err := todo.Save(ctx, snapshot, s.path)
+				observeSave(start, err)
+				dirty = err != nil
+				span.SetError(err)
+				span.End()
+				if err == nil {
+					publish(ctx, before, snapshot)
+					recordSnapshot(s.path, snapshot)
+				}
 				m.reply <- err
 
-			case stopReq:
+			case updateReq:
+				// read-modify-write in one step; commit only once it is on disk
+				ctx, span := trace.Start(m.ctx, "ActorStore.update",
+					"actor.queue_wait_ms", time.Since(m.sent).Milliseconds(), "file.path", s.path,
+				)
+				observeQueueWait("update", m.sent)
+				next, err := m.fn(cloneList(snapshot))
+				if err == nil {
+					start := time.Now()
+					err = todo.Save(ctx, next, s.path)
+					observeSave(start, err)
+					if err == nil {
+						dirty = false
+						before := snapshot
+						snapshot = cloneList(next)
+						publish(ctx, before, snapshot)
+						recordSnapshot(s.path, snapshot)
+					}
+				}
+				span.SetError(err)
+				span.End()
+				m.reply <- err
+
+			case subReq:
+				resp := subResp{id: nextSub, ch: make(chan Event, subscriberBuffer)}
+				nextSub++
+				if m.resume {
+					backlog, ok := ring.since(m.lastID, lastEventID)
+					resp.backlog, resp.gap = backlog, !ok
+				}
+				subs[resp.id] = resp.ch
+				m.reply <- resp
+
+			case unsubReq:
+				if ch, ok := subs[m.id]; ok {
+					close(ch)
+					delete(subs, m.id)
+				}
+
+			case pingReq:
+				close(m.reply)
+
+			case dropSubsReq:
+				dropSubs()
 				close(m.done)
+
+			case stopReq:
+				// Every acknowledged Save already hit disk (todo.Save fsyncs);
+				// only a snapshot whose last Save failed still needs writing.
+				var err error
+				if dirty {
+					err = todo.Save(context.Background(), snapshot, s.path)
+				}
+				slog.Info("actor: store closed", "path", s.path, "count", len(snapshot), "flushed", err == nil)
+				m.reply <- err
 				return
 			}
 		case <-s.quit:
@@ -100,7 +273,9 @@ func cloneList(in []todo.Item) []todo.Item {
 func (s *ActorStore) Load(ctx context.Context) ([]todo.Item, error) {
 	reply := make(chan []todo.Item, 1)
 	select {
-	case s.cmds <- getReq{ctx: ctx, reply: reply}:
+	case s.cmds <- getReq{ctx: ctx, sent: time.Now(), reply: reply}:
+	case <-s.quit:
+		return nil, errStoreClosed
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
@@ -117,7 +292,29 @@ func (s *ActorStore) Load(ctx context.Context) ([]todo.Item, error) {
 func (s *ActorStore) Save(ctx context.Context, list []todo.Item) error {
 	reply := make(chan error, 1)
 	select {
-	case s.cmds <- setReq{ctx: ctx, list: cloneList(list), reply: reply}:
+	case s.cmds <- setReq{ctx: ctx, sent: time.Now(), list: cloneList(list), reply: reply}:
+	case <-s.quit:
+		return errStoreClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Update applies fn to the current items and persists the result as a single
+// serialized write (see Updater). The snapshot only changes if fn succeeds and
+// the file was written.
+func (s *ActorStore) Update(ctx context.Context, fn func([]todo.Item) ([]todo.Item, error)) error {
+	reply := make(chan error, 1)
+	select {
+	case s.cmds <- updateReq{ctx: ctx, sent: time.Now(), fn: fn, reply: reply}:
+	case <-s.quit:
+		return errStoreClosed
 	case <-ctx.Done():
 		return ctx.Err()
 	}
@@ -129,17 +326,99 @@ func (s *ActorStore) Save(ctx context.Context, list []todo.Item) error {
 	}
 }
 
-// Close stops the actor gracefully.
-func (s *ActorStore) Close() {
+// Ping checks that the actor goroutine is alive and processing messages.
+func (s *ActorStore) Ping(ctx context.Context) error {
+	reply := make(chan struct{})
+	select {
+	case s.cmds <- pingReq{reply: reply}:
+	case <-s.quit:
+		return errStoreClosed
+	case <-ctx.Done():
+		return fmt.Errorf("store did not accept a ping: %w", ctx.Err())
+	}
+	select {
+	case <-reply:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("store did not answer a ping: %w", ctx.Err())
+	}
+}
+
+// CheckWritable verifies the next Save can write the data file: the
+// directory must accept new files and an existing file must open for writing.
+func (s *ActorStore) CheckWritable() error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".writecheck-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	if err := os.Remove(name); err != nil {
+		return err
+	}
+	if f, err := os.OpenFile(s.path, os.O_WRONLY, 0); err == nil {
+		return f.Close()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Subscribe registers for change events; see Publisher.
+func (s *ActorStore) Subscribe(ctx context.Context, lastID uint64, resume bool) (Subscription, error) {
+	reply := make(chan subResp, 1)
+	select {
+	case s.cmds <- subReq{lastID: lastID, resume: resume, reply: reply}:
+	case <-s.quit:
+		return Subscription{}, errStoreClosed
+	case <-ctx.Done():
+		return Subscription{}, ctx.Err()
+	}
+	resp := <-reply
+	var once sync.Once
+	return Subscription{
+		Backlog: resp.backlog,
+		Gap:     resp.gap,
+		Events:  resp.ch,
+		Cancel: func() {
+			once.Do(func() {
+				select {
+				case s.cmds <- unsubReq{id: resp.id}:
+				case <-s.quit:
+				}
+			})
+		},
+	}, nil
+}
+
+// CloseSubscribers ends every active subscription (their Events channels
+// are closed) without stopping the store. Used when the server shuts down so
+// long-lived streams let the HTTP server drain.
+func (s *ActorStore) CloseSubscribers() {
 	done := make(chan struct{})
 	select {
-	case s.cmds <- stopReq{done: done}:
-	case <-time.After(100 * time.Millisecond):
-		// actor might be unresponsive; fall back to best-effort quit
+	case s.cmds <- dropSubsReq{done: done}:
+		<-done
+	case <-s.quit:
 	}
+}
+
+// Close stops the actor once it has finished the request in progress and
+// reports the final flush: nil means every item it holds is on disk. If the
+// last Save had failed, Close retries writing the snapshot once and returns
+// that error. Calls after the first return an error without blocking.
+func (s *ActorStore) Close() error {
+	reply := make(chan error, 1)
 	select {
-	case <-done:
-	case <-time.After(150 * time.Millisecond):
+	case s.cmds <- stopReq{reply: reply}:
+	case <-s.quit:
+		return errStoreClosed
 	}
+	err := <-reply
 	close(s.quit)
+	return err
 }
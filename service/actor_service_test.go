@@ -66,3 +66,89 @@ func TestService_ActorStore_ConcurrentLoadAndSave(t *testing.T) {
 		t.Fatalf("expected 2 items, got %d", len(list))
 	}
 }
+
+// TestService_ActorStore_UpdateIsSerialized runs many concurrent
+// read-modify-write Updates and checks none are lost, and that a failing fn
+// leaves the items untouched.
+func TestService_ActorStore_UpdateIsSerialized(t *testing.T) {
+	ctx := context.Background()
+	st := NewActorStore(filepath.Join(t.TempDir(), "todos.json"))
+	defer st.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(ctx, st, func(list []todo.Item) ([]todo.Item, error) {
+				list, _, err := todo.Add(list, "x", todo.StatusNotStarted)
+				return list, err
+			})
+			if err != nil {
+				t.Errorf("Update: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	err := st.Update(ctx, func(list []todo.Item) ([]todo.Item, error) {
+		return nil, os.ErrInvalid
+	})
+	if err != os.ErrInvalid {
+		t.Fatalf("Update err = %v", err)
+	}
+	list, _ := st.Load(ctx)
+	if len(list) != 20 {
+		t.Fatalf("got %d items, want 20 (lost updates or failed fn applied)", len(list))
+	}
+}
+
+// TestService_ActorStore_PingAndCheckWritable covers the readiness helpers.
+func TestService_ActorStore_PingAndCheckWritable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	st := NewActorStore(filepath.Join(t.TempDir(), "sub", "todos.json"))
+	if err := st.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if err := st.CheckWritable(); err != nil {
+		t.Fatalf("CheckWritable: %v", err)
+	}
+	st.Close()
+	if err := st.Ping(ctx); err == nil {
+		t.Fatalf("Ping after Close should fail")
+	}
+}
+
+// TestService_ActorStore_CloseFlushesAfterFailedSave checks that Close
+// retries writing a snapshot whose Save failed and reports the outcome.
+func TestService_ActorStore_CloseFlushesAfterFailedSave(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "data")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(blocker, "todos.json") // parent is a file: saves fail
+	st := NewActorStore(path)
+
+	list, _, _ := todo.Add(nil, "keep me", todo.StatusNotStarted)
+	if err := st.Save(ctx, list); err == nil {
+		t.Fatalf("Save under a file should fail")
+	}
+	_ = os.Remove(blocker) // disk "recovers" before shutdown
+
+	if err := st.Close(); err != nil {
+		t.Fatalf("Close should flush the pending snapshot: %v", err)
+	}
+	got, err := todo.Load(ctx, path)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("after Close: %v items, err=%v", got, err)
+	}
+	if err := st.Close(); err == nil {
+		t.Fatalf("second Close should report the store is closed")
+	}
+	if _, err := st.Load(ctx); err == nil {
+		t.Fatalf("Load after Close should fail instead of blocking")
+	}
+}
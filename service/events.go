@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"todo-app/todo"
+)
+
+// EventType says what happened to an item.
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+// Event is one change to one item, as committed by a Save.
+// ID increases with every event and is what SSE clients send back as
+// Last-Event-ID; Revision identifies the Save that produced the event
+// (one Save can produce several events).
+type Event struct {
+	ID       uint64    `json:"id"`
+	Type     EventType `json:"type"`
+	Revision uint64    `json:"revision"`
+	Item     todo.Item `json:"item"`
+	Time     time.Time `json:"time"`
+}
+
+// Subscription delivers events to one subscriber.
+type Subscription struct {
+	// Backlog holds buffered events after the requested id, oldest first.
+	Backlog []Event
+	// Gap is true when the requested id is no longer (or not yet) in the
+	// buffer, so some events were missed and the client should reload.
+	Gap bool
+	// Events receives new events. It is closed when the subscriber falls too
+	// far behind, when Cancel is called, or when the store shuts down.
+	Events <-chan Event
+	// Cancel unsubscribes; it is safe to call more than once.
+	Cancel func()
+}
+
+// Publisher is implemented by stores that can stream their changes.
+type Publisher interface {
+	// Subscribe starts a subscription. With resume set, buffered events after
+	// lastID are returned as the backlog.
+	Subscribe(ctx context.Context, lastID uint64, resume bool) (Subscription, error)
+}
+
+// CommitFunc receives the events of one committed Save of user's store. It
+// runs on the store's goroutine before the Save is acknowledged, so it sees
+// every event in order; it must not call back into the store.
+type CommitFunc func(ctx context.Context, user string, events []Event)
+
+// CommitNotifier is implemented by factories that report every commit of
+// every user's store, unlike a Subscription, which drops slow readers.
+type CommitNotifier interface {
+	// OnCommit sets the function called after each commit; nil removes it.
+	OnCommit(fn CommitFunc)
+}
+
+// eventBufferSize bounds how many events are kept for Last-Event-ID replay.
+const eventBufferSize = 256
+
+// subscriberBuffer is how far a subscriber may fall behind before it is dropped.
+const subscriberBuffer = 64
+
+// eventRing is a fixed-size buffer of the most recent events.
+type eventRing struct {
+	buf   []Event
+	start int // index of the oldest event
+	n     int
+}
+
+func newEventRing(size int) *eventRing { return &eventRing{buf: make([]Event, size)} }
+
+func (r *eventRing) push(e Event) {
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = e
+		r.n++
+		return
+	}
+	r.buf[r.start] = e
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// since returns the buffered events with ID > id, and whether id could be
+// resumed from (false when events between id and the buffer were dropped).
+func (r *eventRing) since(id, latest uint64) ([]Event, bool) {
+	if id == latest {
+		return nil, true
+	}
+	if id > latest || r.n == 0 {
+		return nil, false
+	}
+	oldest := r.buf[r.start].ID
+	if id+1 < oldest {
+		// Some events were evicted; hand back what we have anyway.
+		return r.collect(id), false
+	}
+	return r.collect(id), true
+}
+
+func (r *eventRing) collect(id uint64) []Event {
+	var out []Event
+	for i := 0; i < r.n; i++ {
+		e := r.buf[(r.start+i)%len(r.buf)]
+		if e.ID > id {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// diffItems compares two snapshots and returns one event per changed item
+// (IDs and Revision left for the caller to fill in).
+func diffItems(before, after []todo.Item) []Event {
+	old := make(map[int]todo.Item, len(before))
+	for _, it := range before {
+		old[it.ID] = it
+	}
+	var out []Event
+	seen := make(map[int]bool, len(after))
+	for _, it := range after {
+		seen[it.ID] = true
+		prev, existed := old[it.ID]
+		switch {
+		case !existed:
+			out = append(out, Event{Type: EventCreated, Item: it})
+		case !sameItem(prev, it):
+			out = append(out, Event{Type: EventUpdated, Item: it})
+		}
+	}
+	for _, it := range before {
+		if !seen[it.ID] {
+			out = append(out, Event{Type: EventDeleted, Item: it})
+		}
+	}
+	return out
+}
+
+// sameItem compares items by their persisted form so new fields are covered
+// automatically.
+func sameItem(a, b todo.Item) bool {
+	ab, err1 := json.Marshal(a)
+	bb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(ab, bb)
+}
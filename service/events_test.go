@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"todo-app/todo"
+)
+
+// recvEvent waits briefly for the next event on ch.
+func recvEvent(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		if !ok {
+			t.Fatalf("events channel closed")
+		}
+		return ev
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for event")
+	}
+	return Event{}
+}
+
+// TestService_ActorStore_PublishesChanges verifies created/updated/deleted
+// events, their ordering and revision numbers.
+func TestService_ActorStore_PublishesChanges(t *testing.T) {
+	ctx := context.Background()
+	st := NewActorStore(filepath.Join(t.TempDir(), "todos.json"))
+	defer st.Close()
+
+	sub, err := st.Subscribe(ctx, 0, false)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Cancel()
+
+	list, _, _ := todo.Add(nil, "a", todo.StatusNotStarted)
+	_ = st.Save(ctx, list)
+	list, _ = todo.UpdateStatus(list, 1, todo.StatusStarted)
+	_ = st.Save(ctx, list)
+	_ = st.Save(ctx, nil)
+
+	c, u, d := recvEvent(t, sub.Events), recvEvent(t, sub.Events), recvEvent(t, sub.Events)
+	if c.Type != EventCreated || u.Type != EventUpdated || d.Type != EventDeleted {
+		t.Fatalf("types = %s,%s,%s", c.Type, u.Type, d.Type)
+	}
+	if !(c.ID < u.ID && u.ID < d.ID) || c.Revision != 1 || d.Revision != 3 {
+		t.Fatalf("ids/revisions out of order: %+v %+v %+v", c, u, d)
+	}
+	if u.Item.Status != todo.StatusStarted {
+		t.Fatalf("updated event carries stale item: %+v", u.Item)
+	}
+}
+
+// TestService_ActorStore_ResumeAndGap checks Last-Event-ID style resumption
+// from the buffer, and gap detection for unknown ids.
+func TestService_ActorStore_ResumeAndGap(t *testing.T) {
+	ctx := context.Background()
+	st := NewActorStore(filepath.Join(t.TempDir(), "todos.json"))
+	defer st.Close()
+
+	first, _ := st.Subscribe(ctx, 0, false)
+	var list []todo.Item
+	for i := 0; i < 3; i++ {
+		list, _, _ = todo.Add(list, "x", todo.StatusNotStarted)
+		_ = st.Save(ctx, list)
+	}
+	e1 := recvEvent(t, first.Events)
+	first.Cancel()
+
+	resumed, err := st.Subscribe(ctx, e1.ID, true)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer resumed.Cancel()
+	if resumed.Gap || len(resumed.Backlog) != 2 || resumed.Backlog[0].ID != e1.ID+1 {
+		t.Fatalf("resume backlog=%+v gap=%v", resumed.Backlog, resumed.Gap)
+	}
+
+	stale, _ := st.Subscribe(ctx, 42, true)
+	defer stale.Cancel()
+	if !stale.Gap {
+		t.Fatalf("unknown id should report a gap")
+	}
+}
+
+// TestService_ActorStore_SubscribersClosedOnShutdown verifies Cancel,
+// CloseSubscribers and Close all close the events channel.
+func TestService_ActorStore_SubscribersClosedOnShutdown(t *testing.T) {
+	ctx := context.Background()
+	st := NewActorStore(filepath.Join(t.TempDir(), "todos.json"))
+
+	closed := func(ch <-chan Event) bool {
+		select {
+		case _, ok := <-ch:
+			return !ok
+		case <-time.After(time.Second):
+			return false
+		}
+	}
+
+	a, _ := st.Subscribe(ctx, 0, false)
+	a.Cancel()
+	a.Cancel() // idempotent
+	if !closed(a.Events) {
+		t.Fatalf("Cancel did not close the channel")
+	}
+
+	b, _ := st.Subscribe(ctx, 0, false)
+	st.CloseSubscribers()
+	if !closed(b.Events) {
+		t.Fatalf("CloseSubscribers did not close the channel")
+	}
+
+	c, _ := st.Subscribe(ctx, 0, false)
+	st.Close()
+	if !closed(c.Events) {
+		t.Fatalf("Close did not close the channel")
+	}
+	c.Cancel() // must not block after Close
+	if _, err := st.Subscribe(ctx, 0, false); err == nil {
+		t.Fatalf("Subscribe after Close should fail")
+	}
+}
+
+// TestService_EventRing_Bounded checks the replay buffer evicts oldest first.
+func TestService_EventRing_Bounded(t *testing.T) {
+	r := newEventRing(3)
+	for id := uint64(1); id <= 5; id++ {
+		r.push(Event{ID: id})
+	}
+	got, ok := r.since(3, 5)
+	if !ok || len(got) != 2 || got[0].ID != 4 {
+		t.Fatalf("since(3) = %+v, %v", got, ok)
+	}
+	if _, ok := r.since(1, 5); ok {
+		t.Fatalf("since(1) should report evicted events")
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"todo-app/todo"
+)
+
+// DefaultUser owns the original single-user data file. Requests that carry no
+// identity (dev mode without a header, or the plain CLI) act as this user.
+const DefaultUser = "default"
+
+// userIDPattern keeps user ids safe to use as a directory name.
+var userIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// ValidateUserID reports whether id can be used to partition storage.
+func ValidateUserID(id string) error {
+	if !userIDPattern.MatchString(id) || id == "." || id == ".." {
+		return fmt.Errorf("invalid user id %q (use 1-64 letters, digits, '.', '_' or '-')", id)
+	}
+	return nil
+}
+
+// StoreFactory hands out the Store holding a single user's items.
+// Implementations must never return one user's Store for another user.
+type StoreFactory interface {
+	For(ctx context.Context, userID string) (Store, error)
+}
+
+// ProjectRegistry is implemented by factories whose users keep a registry
+// of named lists (todo.Projects) beside their data file. Writes into a list
+// go through its CheckWritable, as in the CLI.
+type ProjectRegistry interface {
+	ProjectsFor(ctx context.Context, userID string) (todo.Projects, error)
+}
+
+// sharedFactory returns the same Store for every user.
+type sharedFactory struct{ store Store }
+
+// SharedStore adapts a single Store into a StoreFactory that ignores the user.
+// It exists for single-user setups and tests; it provides no isolation.
+func SharedStore(s Store) StoreFactory { return sharedFactory{store: s} }
+
+func (f sharedFactory) For(ctx context.Context, userID string) (Store, error) {
+	return f.store, nil
+}
+
+// ActorStoreFactory lazily starts one ActorStore per user. The default user
+// keeps basePath (so existing data stays where it was); every other user gets
+// <dir(basePath)>/users/<id>/<base(basePath)>.
+type ActorStoreFactory struct {
+	basePath string
+
+	mu     sync.Mutex
+	stores map[string]*ActorStore
+
+	commitMu sync.RWMutex
+	onCommit CommitFunc
+}
+
+// NewActorStoreFactory creates a factory rooted at basePath.
+func NewActorStoreFactory(basePath string) *ActorStoreFactory {
+	return &ActorStoreFactory{basePath: basePath, stores: map[string]*ActorStore{}}
+}
+
+// PathFor returns the data file used for userID.
+func (f *ActorStoreFactory) PathFor(userID string) string {
+	if userID == DefaultUser {
+		return f.basePath
+	}
+	dir, file := filepath.Split(f.basePath)
+	return filepath.Join(dir, "users", userID, file)
+}
+
+// For returns (starting if necessary) the ActorStore for userID.
+func (f *ActorStoreFactory) For(ctx context.Context, userID string) (Store, error) {
+	if err := ValidateUserID(userID); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if st, ok := f.stores[userID]; ok {
+		return st, nil
+	}
+	st := newActorStore(f.PathFor(userID), func(ctx context.Context, events []Event) {
+		f.commitMu.RLock()
+		fn := f.onCommit
+		f.commitMu.RUnlock()
+		if fn != nil {
+			fn(ctx, userID, events)
+		}
+	})
+	f.stores[userID] = st
+	return st, nil
+}
+
+// OnCommit implements CommitNotifier for every store, started or not.
+func (f *ActorStoreFactory) OnCommit(fn CommitFunc) {
+	f.commitMu.Lock()
+	defer f.commitMu.Unlock()
+	f.onCommit = fn
+}
+
+// ProjectsFor loads the named lists of userID from beside their data file
+// (todo.ProjectsPath); a user without a registry has just the default list.
+func (f *ActorStoreFactory) ProjectsFor(ctx context.Context, userID string) (todo.Projects, error) {
+	if err := ValidateUserID(userID); err != nil {
+		return todo.Projects{}, err
+	}
+	return todo.LoadProjects(ctx, todo.ProjectsPath(f.PathFor(userID)))
+}
+
+// Ping checks that the default user's store (started if necessary) answers.
+func (f *ActorStoreFactory) Ping(ctx context.Context) error {
+	st, err := f.For(ctx, DefaultUser)
+	if err != nil {
+		return err
+	}
+	return st.(*ActorStore).Ping(ctx)
+}
+
+// CheckWritable checks that the default user's data file can be written.
+func (f *ActorStoreFactory) CheckWritable(ctx context.Context) error {
+	st, err := f.For(ctx, DefaultUser)
+	if err != nil {
+		return err
+	}
+	return st.(*ActorStore).CheckWritable()
+}
+
+// CloseSubscribers ends the change-event subscriptions of every store.
+func (f *ActorStoreFactory) CloseSubscribers() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, st := range f.stores {
+		st.CloseSubscribers()
+	}
+}
+
+// Close stops every store the factory started and returns their combined
+// flush errors (nil when all data is on disk).
+func (f *ActorStoreFactory) Close() error {
+	f.mu.Lock()
+	stores := f.stores
+	f.stores = map[string]*ActorStore{}
+	f.mu.Unlock()
+	var errs []error
+	for user, st := range stores {
+		if err := st.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing store for %q: %w", user, err))
+		}
+	}
+	return errors.Join(errs...)
+}
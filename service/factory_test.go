@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"todo-app/todo"
+)
+
+// TestService_ActorStoreFactory_IsolatesUsers verifies each user gets a
+// distinct store and file, and that the default user keeps the base path.
+func TestService_ActorStoreFactory_IsolatesUsers(t *testing.T) {
+	ctx := context.Background()
+	base := filepath.Join(t.TempDir(), "todos.json")
+	f := NewActorStoreFactory(base)
+	defer f.Close()
+
+	if got := f.PathFor(DefaultUser); got != base {
+		t.Fatalf("PathFor(default)=%q want %q", got, base)
+	}
+	if got, want := f.PathFor("alice"), filepath.Join(filepath.Dir(base), "users", "alice", "todos.json"); got != want {
+		t.Fatalf("PathFor(alice)=%q want %q", got, want)
+	}
+
+	alice, err := f.For(ctx, "alice")
+	if err != nil {
+		t.Fatalf("For(alice): %v", err)
+	}
+	bob, err := f.For(ctx, "bob")
+	if err != nil {
+		t.Fatalf("For(bob): %v", err)
+	}
+	again, _ := f.For(ctx, "alice")
+	if again != alice {
+		t.Fatalf("For(alice) returned a different store the second time")
+	}
+
+	if err := alice.Save(ctx, []todo.Item{{ID: 1, Description: "a", Status: todo.StatusStarted, CreatedAt: time.Now()}}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	list, _ := bob.Load(ctx)
+	if len(list) != 0 {
+		t.Fatalf("bob sees %d items", len(list))
+	}
+	onDisk, err := todo.Load(ctx, f.PathFor("alice"))
+	if err != nil || len(onDisk) != 1 {
+		t.Fatalf("alice file: %v items, err=%v", len(onDisk), err)
+	}
+}
+
+// TestService_ValidateUserID rejects ids that could escape the data dir.
+func TestService_ValidateUserID(t *testing.T) {
+	for _, ok := range []string{"alice", "bob-2", "a.b_c", DefaultUser} {
+		if err := ValidateUserID(ok); err != nil {
+			t.Fatalf("ValidateUserID(%q) = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"", ".", "..", "../x", "a/b", `a\\b`, ".hidden", "has space"} {
+		if err := ValidateUserID(bad); err == nil {
+			t.Fatalf("ValidateUserID(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"todo-app/lists"
+	"todo-app/trace"
+)
+
+// ListStore owns the shared lists file using the same actor pattern as
+// ActorStore: one goroutine holds the state, and every change is a
+// read-modify-write applied in that goroutine so concurrent permission
+// changes cannot overwrite each other.
+type ListStore struct {
+	path string
+
+	cmds chan any
+	quit chan struct{}
+}
+
+// NewListStore starts the actor and loads the initial lists from path.
+// Use Close() to stop the background goroutine.
+func NewListStore(path string) *ListStore {
+	s := &ListStore{
+		path: path,
+		cmds: make(chan any),
+		quit: make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// internal message types
+type (
+	listsGetReq struct {
+		ctx   context.Context
+		reply chan []lists.List
+	}
+
+	listsUpdateReq struct {
+		ctx   context.Context
+		fn    func([]lists.List) ([]lists.List, error)
+		reply chan error
+	}
+)
+
+func (s *ListStore) loop() {
+	var snapshot []lists.List
+	{
+		all, err := lists.Load(context.Background(), s.path)
+		if err != nil {
+			slog.Warn("lists actor: initial load failed; starting empty", "error", err, "path", s.path)
+			all = []lists.List{}
+		}
+		snapshot = cloneLists(all)
+	}
+
+	for {
+		select {
+		case msg := <-s.cmds:
+			switch m := msg.(type) {
+			case listsGetReq:
+				m.reply <- cloneLists(snapshot)
+
+			case listsUpdateReq:
+				ctx, span := trace.Start(m.ctx, "ListStore.update", "file.path", s.path)
+				next, err := m.fn(cloneLists(snapshot))
+				if err == nil {
+					// only commit the new state once it is on disk
+					if err = lists.Save(ctx, next, s.path); err == nil {
+						snapshot = cloneLists(next)
+					}
+				}
+				span.SetError(err)
+				span.End()
+				m.reply <- err
+
+			case stopReq:
+				// updates only commit after a successful save; nothing to flush
+				m.reply <- nil
+				return
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+func cloneLists(in []lists.List) []lists.List {
+	out := make([]lists.List, len(in))
+	for i := range in {
+		out[i] = in[i].Clone()
+	}
+	return out
+}
+
+// All returns a copy of every shared list.
+func (s *ListStore) All(ctx context.Context) ([]lists.List, error) {
+	reply := make(chan []lists.List, 1)
+	select {
+	case s.cmds <- listsGetReq{ctx: ctx, reply: reply}:
+	case <-s.quit:
+		return nil, errStoreClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case all := <-reply:
+		return all, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Update runs fn against a private copy of all lists inside the actor and,
+// if fn succeeds, persists and adopts its result. fn must not retain the slice.
+func (s *ListStore) Update(ctx context.Context, fn func([]lists.List) ([]lists.List, error)) error {
+	reply := make(chan error, 1)
+	select {
+	case s.cmds <- listsUpdateReq{ctx: ctx, fn: fn, reply: reply}:
+	case <-s.quit:
+		return errStoreClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the actor once it has finished the update in progress.
+// Calls after the first return an error without blocking.
+func (s *ListStore) Close() error {
+	reply := make(chan error, 1)
+	select {
+	case s.cmds <- stopReq{reply: reply}:
+	case <-s.quit:
+		return errStoreClosed
+	}
+	err := <-reply
+	close(s.quit)
+	return err
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"todo-app/lists"
+	"todo-app/todo"
+)
+
+// TestService_ListStore_ConcurrentUpdates verifies that concurrent
+// read-modify-write updates are serialized and none are lost.
+func TestService_ListStore_ConcurrentUpdates(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "lists.json")
+	st := NewListStore(path)
+	defer st.Close()
+
+	if err := st.Update(ctx, func(all []lists.List) ([]lists.List, error) {
+		all, _, err := lists.Create(all, "shared", "olive")
+		return all, err
+	}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := st.Update(ctx, func(all []lists.List) ([]lists.List, error) {
+				return lists.UpdateItems(all, 1, "olive", func(items []todo.Item) ([]todo.Item, error) {
+					items, _, err := todo.Add(items, "x", todo.StatusNotStarted)
+					return items, err
+				})
+			})
+			if err != nil {
+				t.Errorf("Update: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	onDisk, err := lists.Load(ctx, path)
+	if err != nil {
+		t.Fatalf("lists.Load: %v", err)
+	}
+	if len(onDisk) != 1 || len(onDisk[0].Items) != 20 {
+		t.Fatalf("expected 20 items on disk, got %+v", onDisk)
+	}
+}
+
+// TestService_ListStore_FailedUpdateIsDiscarded checks that an update
+// returning an error leaves the stored state untouched.
+func TestService_ListStore_FailedUpdateIsDiscarded(t *testing.T) {
+	ctx := context.Background()
+	st := NewListStore(filepath.Join(t.TempDir(), "lists.json"))
+	defer st.Close()
+
+	boom := errors.New("boom")
+	err := st.Update(ctx, func(all []lists.List) ([]lists.List, error) {
+		all, _, _ = lists.Create(all, "half-made", "olive")
+		return all, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Update err=%v want boom", err)
+	}
+	all, _ := st.All(ctx)
+	if len(all) != 0 {
+		t.Fatalf("failed update was committed: %+v", all)
+	}
+}
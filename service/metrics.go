@@ -0,0 +1,76 @@
+package service
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"todo-app/metrics"
+	"todo-app/todo"
+)
+
+// ActorStore metrics. The store gauges are totals over every data file this
+// process has loaded: a label per file would be a series per user, and would
+// publish user ids through the paths.
+var (
+	actorQueueWait = metrics.Default.NewHistogramVec("todo_actor_queue_wait_seconds",
+		"Time a request waited before the ActorStore goroutine picked it up, by operation.",
+		[]float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1}, "op")
+	storeSaveDuration = metrics.Default.NewHistogramVec("todo_store_save_duration_seconds",
+		"Time spent writing the data file, by result.", nil, "result")
+	storeFileSize = metrics.Default.NewGaugeVec("todo_store_file_size_bytes",
+		"Total size of the data files after their last load or save.")
+	storeItems = metrics.Default.NewGaugeVec("todo_store_items",
+		"Items in all stores, by status.", "status")
+)
+
+// storeTotals keeps each file's last snapshot so the gauges can be totals.
+var storeTotals = struct {
+	sync.Mutex
+	size  map[string]int64
+	items map[string]map[todo.Status]int
+}{size: map[string]int64{}, items: map[string]map[todo.Status]int{}}
+
+// observeQueueWait records how long a message sat in the actor's queue.
+func observeQueueWait(op string, sent time.Time) {
+	actorQueueWait.With(op).Observe(time.Since(sent).Seconds())
+}
+
+// observeSave records a save's duration and outcome.
+func observeSave(start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	storeSaveDuration.With(result).Observe(time.Since(start).Seconds())
+}
+
+// recordSnapshot updates the file size and item count totals with path's
+// current state.
+func recordSnapshot(path string, list []todo.Item) {
+	counts := map[todo.Status]int{todo.StatusNotStarted: 0, todo.StatusStarted: 0, todo.StatusCompleted: 0}
+	for _, it := range list {
+		counts[it.Status]++
+	}
+	storeTotals.Lock()
+	defer storeTotals.Unlock()
+	if fi, err := os.Stat(path); err == nil {
+		storeTotals.size[path] = fi.Size()
+	}
+	storeTotals.items[path] = counts
+
+	var size int64
+	for _, n := range storeTotals.size {
+		size += n
+	}
+	storeFileSize.With().Set(float64(size))
+	totals := map[todo.Status]int{}
+	for _, c := range storeTotals.items {
+		for st, n := range c {
+			totals[st] += n
+		}
+	}
+	for st, n := range totals {
+		storeItems.With(string(st)).Set(float64(n))
+	}
+}
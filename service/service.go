@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"todo-app/todo"
+	"todo-app/trace"
 )
 
 // Store abstracts persistence for to-do lists.
@@ -15,6 +16,31 @@ type Store interface {
 	Save(ctx context.Context, list []todo.Item) error
 }
 
+// Updater is implemented by stores that can apply a read-modify-write as one
+// serialized write, so concurrent writers cannot interleave with it.
+type Updater interface {
+	// Update passes a copy of the items to fn and saves its result. Nothing is
+	// written when fn returns an error.
+	Update(ctx context.Context, fn func([]todo.Item) ([]todo.Item, error)) error
+}
+
+// Update applies fn through store's Updater when it has one, and otherwise
+// falls back to Load then Save (which is not atomic against other writers).
+func Update(ctx context.Context, store Store, fn func([]todo.Item) ([]todo.Item, error)) error {
+	if u, ok := store.(Updater); ok {
+		return u.Update(ctx, fn)
+	}
+	list, err := store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	next, err := fn(list)
+	if err != nil {
+		return err
+	}
+	return store.Save(ctx, next)
+}
+
 // FileStore implements Store backed by a JSON file on disk.
 type FileStore struct {
 	// OutPath is the JSON file path.
@@ -38,23 +64,31 @@ func (f *FileStore) ensureOutPath() string {
 
 func (f *FileStore) Load(ctx context.Context) ([]todo.Item, error) {
 	path := f.ensureOutPath()
+	ctx, span := trace.Start(ctx, "FileStore.Load", "file.path", path)
+	defer span.End()
 	list, err := todo.Load(ctx, path)
 	if err != nil {
+		span.SetError(err)
 		slog.ErrorContext(ctx, "load failed", "error", err, "path", path)
 		return nil, err
 	}
+	span.SetAttr("todo.count", len(list))
 	return list, nil
 }
 
 func (f *FileStore) Save(ctx context.Context, list []todo.Item) error {
 	path := f.ensureOutPath()
+	ctx, span := trace.Start(ctx, "FileStore.Save", "file.path", path, "todo.count", len(list))
+	defer span.End()
 	// Ensure directory exists (robust even if todo.WriteJSON already does this)
 	if dir := filepath.Dir(path); dir != "" && dir != "." {
 		if err := os.MkdirAll(dir, 0o755); err != nil {
+			span.SetError(err)
 			return err
 		}
 	}
 	if err := todo.Save(ctx, list, path); err != nil {
+		span.SetError(err)
 		slog.ErrorContext(ctx, "save failed", "error", err, "path", path)
 		return err
 	}
@@ -0,0 +1,11 @@
+//go:build linux
+
+package term
+
+import "syscall"
+
+// termios ioctl requests on Linux.
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
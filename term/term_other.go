@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package term
+
+import "errors"
+
+// errUnsupported is returned where termios is not available; callers fall
+// back to plain line input or refuse to start.
+var errUnsupported = errors.New("raw terminal mode is not supported on this platform")
+
+// MakeRaw is unsupported here.
+func MakeRaw(fd int) (func(), error) { return nil, errUnsupported }
+
+// Size is unsupported here.
+func Size(fd int) (width, height int, err error) { return 0, 0, errUnsupported }
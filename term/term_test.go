@@ -0,0 +1,24 @@
+package term
+
+import (
+	"os"
+	"testing"
+)
+
+// TestTerm_NotATerminal checks that pipes are rejected, which is how
+// callers detect that they should fall back to plain input.
+func TestTerm_NotATerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if restore, err := MakeRaw(int(r.Fd())); err == nil {
+		restore()
+		t.Fatalf("MakeRaw on a pipe succeeded")
+	}
+	if _, _, err := Size(int(r.Fd())); err == nil {
+		t.Fatalf("Size on a pipe succeeded")
+	}
+}
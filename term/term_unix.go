@@ -0,0 +1,58 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package term
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+//
+// term/term_unix.go (package term)
+// --------------------------------
+// Raw terminal mode and window size through termios ioctls, so line editors
+// and full-screen views see every key. Output processing (OPOST) stays on,
+// so "\n" still starts a new line for ordinary output. The ioctl request
+// numbers are per OS (term_linux.go, term_bsd.go).
+//
+
+// MakeRaw switches the terminal on fd to raw input and returns a function
+// that restores the previous settings. It fails when fd is not a terminal.
+func MakeRaw(fd int) (func(), error) {
+	var old syscall.Termios
+	if err := ioctl(fd, ioctlGetTermios, unsafe.Pointer(&old)); err != nil {
+		return nil, err
+	}
+	raw := old
+	raw.Iflag &^= syscall.BRKINT | syscall.ICRNL | syscall.INPCK | syscall.ISTRIP | syscall.IXON
+	raw.Cflag |= syscall.CS8
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.IEXTEN | syscall.ISIG
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+	return func() { _ = ioctl(fd, ioctlSetTermios, unsafe.Pointer(&old)) }, nil
+}
+
+// winsize mirrors struct winsize from <sys/ioctl.h>.
+type winsize struct {
+	Row, Col       uint16
+	Xpixel, Ypixel uint16
+}
+
+// Size returns the terminal's width and height in cells.
+func Size(fd int) (width, height int, err error) {
+	var ws winsize
+	if err := ioctl(fd, syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+func ioctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
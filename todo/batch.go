@@ -0,0 +1,137 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//
+// todo/batch.go (package todo)
+// ----------------------------
+// Batches of create/update/delete operations applied to a list in one go.
+// Atomic batches stop at the first failure and leave the list untouched;
+// non-atomic batches apply every operation that succeeds and report the rest.
+//
+
+// OpKind names a batch operation.
+type OpKind string
+
+const (
+	OpCreate OpKind = "create"
+	OpUpdate OpKind = "update"
+	OpDelete OpKind = "delete"
+)
+
+// MaxBatchOps bounds the number of operations in one batch.
+const MaxBatchOps = 1000
+
+// ErrSkipped marks operations not attempted because an atomic batch failed.
+var ErrSkipped = errors.New("not applied: another operation in the atomic batch failed")
+
+// Op is one operation. Create uses Description, Status (default "not started")
+// and List; update uses ID plus Description and/or Status; delete uses ID.
+type Op struct {
+	Op          OpKind `json:"op"`
+	ID          int    `json:"id,omitempty"`
+	Description string `json:"description,omitempty"`
+	Status      Status `json:"status,omitempty"`
+	List        string `json:"list,omitempty"`
+}
+
+// OpResult is the outcome of one operation. Item is the created or updated
+// item, or the deleted one.
+type OpResult struct {
+	Index int    `json:"index"`
+	Op    OpKind `json:"op"`
+	Item  *Item  `json:"item,omitempty"`
+	Err   error  `json:"-"`
+}
+
+// ApplyOp applies a single operation and returns the affected item.
+func ApplyOp(list []Item, op Op, opts ...Option) ([]Item, Item, error) {
+	switch op.Op {
+	case OpCreate:
+		st := op.Status
+		if strings.TrimSpace(string(st)) == "" {
+			st = StatusNotStarted
+		}
+		return AddToList(list, strings.TrimSpace(op.List), op.Description, st, opts...)
+
+	case OpUpdate:
+		if strings.TrimSpace(op.Description) == "" && op.Status == "" {
+			return list, Item{}, invalidf("update needs a description or a status")
+		}
+		var err error
+		if strings.TrimSpace(op.Description) != "" {
+			if list, err = UpdateDescription(list, op.ID, op.Description, opts...); err != nil {
+				return list, Item{}, err
+			}
+		}
+		if op.Status != "" {
+			if list, err = UpdateStatus(list, op.ID, op.Status); err != nil {
+				return list, Item{}, err
+			}
+		}
+		for _, it := range list {
+			if it.ID == op.ID {
+				return list, it, nil
+			}
+		}
+		return list, Item{}, notFoundError(op.ID)
+
+	case OpDelete:
+		for _, it := range list {
+			if it.ID == op.ID {
+				list, err := Delete(list, op.ID)
+				return list, it, err
+			}
+		}
+		return list, Item{}, notFoundError(op.ID)
+
+	default:
+		return list, Item{}, invalidf("unknown op %q (use create, update or delete)", op.Op)
+	}
+}
+
+// ApplyBatch applies ops in order and returns the new list and one result per
+// op. When atomic is set and an op fails, the original list is returned with
+// the failing op's error (other ops report ErrSkipped) and a non-nil error.
+// The input slice is never modified.
+func ApplyBatch(list []Item, ops []Op, atomic bool, opts ...Option) ([]Item, []OpResult, error) {
+	if len(ops) == 0 {
+		return list, nil, invalidf("batch has no operations")
+	}
+	if len(ops) > MaxBatchOps {
+		return list, nil, invalidf("batch has %d operations; the limit is %d", len(ops), MaxBatchOps)
+	}
+	work := append([]Item(nil), list...)
+	results := make([]OpResult, len(ops))
+	var firstErr error
+	for i, op := range ops {
+		results[i] = OpResult{Index: i, Op: op.Op}
+		if firstErr != nil && atomic {
+			results[i].Err = ErrSkipped
+			continue
+		}
+		next, item, err := ApplyOp(append([]Item(nil), work...), op, opts...)
+		if err != nil {
+			results[i].Err = err
+			if firstErr == nil {
+				firstErr = fmt.Errorf("op %d (%s): %w", i, op.Op, err)
+			}
+			continue
+		}
+		work = next
+		results[i].Item = &item
+	}
+	if atomic && firstErr != nil {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err, results[i].Item = ErrSkipped, nil
+			}
+		}
+		return list, results, firstErr
+	}
+	return work, results, nil
+}
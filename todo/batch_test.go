@@ -0,0 +1,67 @@
+package todo
+
+import (
+	"errors"
+	"testing"
+)
+
+func seed(t *testing.T) []Item {
+	t.Helper()
+	var list []Item
+	for _, d := range []string{"a", "b", "c"} {
+		list, _, _ = Add(list, d, StatusNotStarted)
+	}
+	return list
+}
+
+// TestTodo_ApplyBatch_NonAtomic applies what it can and reports the rest.
+func TestTodo_ApplyBatch_NonAtomic(t *testing.T) {
+	list := seed(t)
+	ops := []Op{
+		{Op: OpCreate, Description: "d", List: "work"},
+		{Op: OpUpdate, ID: 1, Status: StatusCompleted},
+		{Op: OpDelete, ID: 99},
+		{Op: OpDelete, ID: 2},
+		{Op: "explode"},
+	}
+	next, res, err := ApplyBatch(list, ops, false)
+	if err != nil {
+		t.Fatalf("ApplyBatch: %v", err)
+	}
+	if len(next) != 3 || next[0].Status != StatusCompleted || next[2].Description != "d" || next[2].List != "work" {
+		t.Fatalf("next = %+v", next)
+	}
+	if !errors.Is(res[2].Err, ErrNotFound) || res[4].Err == nil || res[3].Item.ID != 2 {
+		t.Fatalf("results = %+v", res)
+	}
+	if len(list) != 3 || list[0].Status != StatusNotStarted {
+		t.Fatalf("input list was modified: %+v", list)
+	}
+}
+
+// TestTodo_ApplyBatch_Atomic leaves the list untouched on any failure.
+func TestTodo_ApplyBatch_Atomic(t *testing.T) {
+	list := seed(t)
+	ops := []Op{
+		{Op: OpUpdate, ID: 1, Description: "changed"},
+		{Op: OpUpdate, ID: 2, Status: "bogus"},
+		{Op: OpDelete, ID: 3},
+	}
+	next, res, err := ApplyBatch(list, ops, true)
+	if err == nil {
+		t.Fatalf("atomic batch with a bad op should fail")
+	}
+	if next[0].Description != "a" || len(next) != 3 {
+		t.Fatalf("atomic failure changed the list: %+v", next)
+	}
+	if !errors.Is(res[0].Err, ErrSkipped) || errors.Is(res[1].Err, ErrSkipped) || !errors.Is(res[2].Err, ErrSkipped) {
+		t.Fatalf("results = %+v", res)
+	}
+
+	if _, _, err := ApplyBatch(list, nil, true); err == nil {
+		t.Fatalf("empty batch accepted")
+	}
+	if _, _, err := ApplyBatch(list, make([]Op, MaxBatchOps+1), false); err == nil {
+		t.Fatalf("oversized batch accepted")
+	}
+}
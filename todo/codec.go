@@ -0,0 +1,190 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//
+// todo/codec.go (package todo)
+// ----------------------------
+// Interchange formats for moving items between tools. A Codec encodes items
+// to and decodes them from one format; codecs register themselves by name
+// and file extension so the CLI (-format, or the file's extension) and the
+// API (?format=) pick them the same way. Decoded items are records, not yet
+// part of any list: Import validates them and gives them new ids.
+//
+// Each format carries a subset of the Item fields. Round trips keep every
+// field a format supports:
+//
+//	json      everything
+//	csv, tsv  everything
+//	markdown  description, status, priority, due date, list
+//	todotxt   description, status, priority, list, and the created,
+//	          completed and due dates (whole days)
+//	ical      description, status, priority A-I, list, recurrence, the due
+//	          date, and the created and completed times (whole seconds)
+//
+// todo.txt-style +project and @context tags are words of the description in
+// every format, so they always survive; see Item.Projects and Item.Contexts.
+//
+
+// Codec reads and writes items in one format.
+type Codec interface {
+	// Name is the format's name for -format and ?format=, e.g. "csv".
+	Name() string
+	// ContentType is the MIME type used when serving the format.
+	ContentType() string
+	Encode(w io.Writer, items []Item) error
+	Decode(r io.Reader) ([]Item, error)
+}
+
+var (
+	codecs     = map[string]Codec{}
+	codecByExt = map[string]Codec{}
+)
+
+// RegisterCodec makes c available by its name and by each file extension
+// (".csv"). A later registration for the same name or extension wins.
+func RegisterCodec(c Codec, exts ...string) {
+	codecs[c.Name()] = c
+	for _, ext := range exts {
+		codecByExt[strings.ToLower(ext)] = c
+	}
+}
+
+// CodecNames lists the registered formats, sorted.
+func CodecNames() []string {
+	names := make([]string, 0, len(codecs))
+	for n := range codecs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CodecFor returns the codec called name (case-insensitive; "md",
+// "todo.txt" and "ics" are accepted too).
+func CodecFor(name string) (Codec, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "md":
+		name = "markdown"
+	case "todo.txt", "txt":
+		name = "todotxt"
+	case "ics", "icalendar":
+		name = "ical"
+	}
+	if c, ok := codecs[name]; ok {
+		return c, nil
+	}
+	return nil, invalidf("unknown format %q (use %s)", name, strings.Join(CodecNames(), ", "))
+}
+
+// CodecForFile picks a codec by the extension of path, if one is registered.
+func CodecForFile(path string) (Codec, bool) {
+	c, ok := codecByExt[strings.ToLower(filepath.Ext(path))]
+	return c, ok
+}
+
+// Import appends records (as decoded by a Codec) to list with new ids. Each
+// keeps its status, priority, dates and list; a non-empty target list
+// overrides the list, and a zero CreatedAt becomes now. Nothing is added
+// unless every record is valid.
+func Import(list []Item, records []Item, target string, opts ...Option) ([]Item, []Item, error) {
+	c, now := newChecks(opts), time.Now()
+	next := append([]Item(nil), list...)
+	added := make([]Item, 0, len(records))
+	for i, rec := range records {
+		it, err := c.importRecord(rec, target, now)
+		if err != nil {
+			return list, nil, invalidf("item %d: %w", i+1, err)
+		}
+		it.ID = getNextID(next)
+		next = append(next, it)
+		added = append(added, it)
+	}
+	return next, added, nil
+}
+
+// importRecord validates rec and fills in defaults.
+func (c checks) importRecord(rec Item, target string, now time.Time) (Item, error) {
+	it := rec
+	it.Description = strings.TrimSpace(it.Description)
+	if err := c.checkDescription(it.Description); err != nil {
+		return Item{}, err
+	}
+	if it.Status == "" {
+		it.Status = StatusNotStarted
+	}
+	if err := it.Status.Validate(); err != nil {
+		return Item{}, err
+	}
+	it.Status = Status(strings.ToLower(string(it.Status)))
+	var err error
+	if it.Priority, err = ValidatePriority(it.Priority); err != nil {
+		return Item{}, err
+	}
+	if it.Recurrence, err = ValidateRecurrence(it.Recurrence); err != nil {
+		return Item{}, err
+	}
+	if target != "" {
+		it.List = target
+	}
+	if it.List == DefaultList {
+		it.List = ""
+	}
+	if it.List != "" {
+		if err := validateListName(it.List); err != nil {
+			return Item{}, err
+		}
+	}
+	if it.CreatedAt.IsZero() {
+		it.CreatedAt = now
+	}
+	switch {
+	case it.Status != StatusCompleted:
+		it.CompletedAt = time.Time{}
+	case it.CompletedAt.IsZero():
+		it.CompletedAt = now
+	}
+	if !it.Due.IsZero() {
+		it.Due = DueDate(it.Due)
+	}
+	return it, nil
+}
+
+// jsonCodec is the export format: an indented JSON array of items.
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string        { return "json" }
+func (jsonCodec) ContentType() string { return "application/json" }
+
+func (jsonCodec) Encode(w io.Writer, items []Item) error {
+	if items == nil {
+		items = []Item{}
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func (jsonCodec) Decode(r io.Reader) ([]Item, error) {
+	items := []Item{}
+	if err := json.NewDecoder(r).Decode(&items); err != nil && !errors.Is(err, io.EOF) {
+		return nil, invalidf("json: %w", err)
+	}
+	return items, nil
+}
+
+func init() {
+	RegisterCodec(jsonCodec{}, ".json")
+}
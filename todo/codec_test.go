@@ -0,0 +1,139 @@
+package todo
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// codecSample covers every field, with tags, each status, and items in two
+// lists plus the default one.
+func codecSample() []Item {
+	created := time.Date(2024, 5, 1, 9, 30, 15, 0, time.UTC)
+	done := time.Date(2024, 5, 4, 18, 0, 0, 0, time.UTC)
+	due := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	return []Item{
+		{ID: 1, Description: "Call the plumber +house @phone", Status: StatusNotStarted, CreatedAt: created, Priority: "A", Due: due},
+		{ID: 2, Description: "Write report, draft 2", Status: StatusStarted, CreatedAt: created, List: "work", Priority: "B"},
+		{ID: 3, Description: `Buy "oat" milk @shop`, Status: StatusCompleted, CreatedAt: created, List: "home", CompletedAt: done, Priority: "C", Due: due, Recurrence: "FREQ=WEEKLY;BYDAY=SA"},
+		{ID: 4, Description: "Plan trip", Status: StatusNotStarted, CreatedAt: created, List: "home"},
+	}
+}
+
+// supported keeps the fields a format can carry, as documented in codec.go.
+func supported(format string, items []Item) []Item {
+	out := make([]Item, len(items))
+	for i, it := range items {
+		switch format {
+		case "markdown":
+			it = Item{Description: it.Description, Status: it.Status, Priority: it.Priority, Due: it.Due, List: it.List}
+		case "todotxt":
+			it = Item{Description: it.Description, Status: it.Status, Priority: it.Priority, Due: it.Due, List: it.List,
+				CreatedAt: DueDate(it.CreatedAt), CompletedAt: zeroOrDate(it.CompletedAt)}
+		case "ical":
+			it.CreatedAt = it.CreatedAt.Truncate(time.Second)
+			it.CompletedAt = it.CompletedAt.Truncate(time.Second)
+		}
+		out[i] = it
+	}
+	return out
+}
+
+func zeroOrDate(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return DueDate(t)
+}
+
+// TestTodo_Codec_RoundTrip encodes and decodes the sample with every
+// registered codec and checks no supported field is lost.
+func TestTodo_Codec_RoundTrip(t *testing.T) {
+	for _, name := range CodecNames() {
+		t.Run(name, func(t *testing.T) {
+			c, err := CodecFor(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := c.Encode(&buf, codecSample()); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			got, err := c.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("Decode: %v\n%s", err, buf.String())
+			}
+			if want := supported(name, codecSample()); !reflect.DeepEqual(got, want) {
+				t.Fatalf("round trip lost data:\n got %+v\nwant %+v\nencoded:\n%s", got, want, buf.String())
+			}
+			// A second trip is stable.
+			var again bytes.Buffer
+			if err := c.Encode(&again, got); err != nil || again.String() != buf.String() {
+				t.Fatalf("re-encoding changed the output (err=%v):\n%s\nvs\n%s", err, again.String(), buf.String())
+			}
+			if empty, err := c.Decode(strings.NewReader("")); err != nil || len(empty) != 0 {
+				t.Fatalf("empty input: %v, %v", empty, err)
+			}
+		})
+	}
+}
+
+// TestTodo_Codec_Lookup checks names, aliases and file extensions.
+func TestTodo_Codec_Lookup(t *testing.T) {
+	for alias, want := range map[string]string{"CSV": "csv", "md": "markdown", "todo.txt": "todotxt", "tsv": "tsv", "json": "json"} {
+		if c, err := CodecFor(alias); err != nil || c.Name() != want {
+			t.Errorf("CodecFor(%q) = %v, %v; want %s", alias, c, err, want)
+		}
+	}
+	if _, err := CodecFor("xml"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("CodecFor(xml) err = %v", err)
+	}
+	for path, want := range map[string]string{"a/b.CSV": "csv", "todo.txt": "todotxt", "TODO.md": "markdown", "x.json": "json"} {
+		if c, ok := CodecForFile(path); !ok || c.Name() != want {
+			t.Errorf("CodecForFile(%q) = %v, %v; want %s", path, c, ok, want)
+		}
+	}
+	if _, ok := CodecForFile("notes"); ok {
+		t.Error("CodecForFile without an extension found a codec")
+	}
+}
+
+// TestTodo_Import checks ids, defaults, the target list override and that
+// one bad record leaves the list untouched.
+func TestTodo_Import(t *testing.T) {
+	list, _, _ := Add(nil, "existing", StatusNotStarted)
+	due := time.Date(2024, 5, 10, 15, 0, 0, 0, time.FixedZone("x", 3600))
+	records := []Item{
+		{ID: 99, Description: "  from csv  ", Priority: "b", Due: due, List: "default"},
+		{Description: "done", Status: "Completed", List: "home"},
+	}
+	next, added, err := Import(list, records, "")
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(next) != 3 || len(added) != 2 || added[0].ID != 2 || added[1].ID != 3 {
+		t.Fatalf("ids: %+v", added)
+	}
+	a, b := added[0], added[1]
+	if a.Description != "from csv" || a.Status != StatusNotStarted || a.Priority != "B" || a.List != "" ||
+		!a.Due.Equal(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)) || a.CreatedAt.IsZero() || !a.CompletedAt.IsZero() {
+		t.Fatalf("first record: %+v", a)
+	}
+	if b.Status != StatusCompleted || b.CompletedAt.IsZero() || b.List != "home" {
+		t.Fatalf("second record: %+v", b)
+	}
+
+	_, added, _ = Import(list, records, "work")
+	if added[0].List != "work" || added[1].List != "work" {
+		t.Fatalf("target list not applied: %+v", added)
+	}
+
+	bad := append(records, Item{Description: "x", Priority: "AA"})
+	got, _, err := Import(list, bad, "")
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "item 3") || len(got) != 1 {
+		t.Fatalf("bad record: err=%v, list=%+v", err, got)
+	}
+}
@@ -0,0 +1,162 @@
+package todo
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// todo/csv.go (package todo)
+// --------------------------
+// CSV and TSV codecs for spreadsheets. The first row names the columns;
+// decoding matches them by name in any order and ignores unknown ones, so a
+// sheet can be reordered or gain notes columns. Only description is required.
+//
+
+// CSVColumns are the columns written, in order.
+var CSVColumns = []string{"id", "description", "status", "created_at", "list", "priority", "due", "completed_at", "recurrence"}
+
+// CSVCodec is the CSV codec (Comma ',') or, with Comma '\t', TSV.
+type CSVCodec struct {
+	Comma rune
+}
+
+func (c CSVCodec) Name() string {
+	if c.Comma == '\t' {
+		return "tsv"
+	}
+	return "csv"
+}
+
+func (c CSVCodec) ContentType() string {
+	if c.Comma == '\t' {
+		return "text/tab-separated-values; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func (c CSVCodec) writer(w io.Writer) *csv.Writer {
+	cw := csv.NewWriter(w)
+	if c.Comma != 0 {
+		cw.Comma = c.Comma
+	}
+	return cw
+}
+
+func (c CSVCodec) Encode(w io.Writer, items []Item) error {
+	cw := c.writer(w)
+	_ = cw.Write(CSVColumns)
+	for _, it := range items {
+		_ = cw.Write([]string{
+			strconv.Itoa(it.ID), it.Description, string(it.Status), formatTime(it.CreatedAt),
+			it.List, it.Priority, formatDate(it.Due), formatTime(it.CompletedAt), it.Recurrence,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (c CSVCodec) Decode(r io.Reader) ([]Item, error) {
+	cr := csv.NewReader(r)
+	if c.Comma != 0 {
+		cr.Comma = c.Comma
+	}
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return []Item{}, nil
+	}
+	if err != nil {
+		return nil, invalidf("%s: %w", c.Name(), err)
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	if _, ok := col["description"]; !ok {
+		return nil, invalidf("%s: no description column in the header", c.Name())
+	}
+
+	items := []Item{}
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, invalidf("%s: %w", c.Name(), err)
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		it := Item{
+			Description: field("description"),
+			Status:      Status(strings.ToLower(field("status"))),
+			List:        field("list"),
+			Priority:    field("priority"),
+			Recurrence:  field("recurrence"),
+		}
+		if s := field("id"); s != "" {
+			if it.ID, err = strconv.Atoi(s); err != nil {
+				return nil, invalidf("%s: line %d: invalid id %q", c.Name(), line, s)
+			}
+		}
+		for _, f := range []struct {
+			name string
+			dst  *time.Time
+		}{{"created_at", &it.CreatedAt}, {"due", &it.Due}, {"completed_at", &it.CompletedAt}} {
+			if *f.dst, err = parseTime(field(f.name)); err != nil {
+				return nil, invalidf("%s: line %d: %s: %w", c.Name(), line, f.name, err)
+			}
+		}
+		if !it.Due.IsZero() {
+			it.Due = DueDate(it.Due)
+		}
+		items = append(items, it)
+	}
+}
+
+// formatTime writes t as RFC 3339, or "" for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// formatDate writes the date of t (YYYY-MM-DD), or "" for the zero time.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+// parseTime reads an RFC 3339 time or a YYYY-MM-DD date (midnight UTC);
+// "" is the zero time.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, invalidf("invalid date %q (want YYYY-MM-DD or RFC 3339)", s)
+	}
+	return t, nil
+}
+
+func init() {
+	RegisterCodec(CSVCodec{}, ".csv")
+	RegisterCodec(CSVCodec{Comma: '\t'}, ".tsv")
+}
@@ -0,0 +1,44 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTodo_CSV_Decode reads a sheet with reordered, missing and extra
+// columns, a byte order mark and dates in both accepted forms.
+func TestTodo_CSV_Decode(t *testing.T) {
+	in := "\ufeffNotes,Status,Description,due,created_at\n" +
+		"call first,started,\"Call mum, then dad\",2024-05-10,2024-05-01T09:30:00Z\n" +
+		",,Plain,,\n"
+	items, err := CSVCodec{}.Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %+v", items)
+	}
+	first := items[0]
+	if first.Description != "Call mum, then dad" || first.Status != StatusStarted ||
+		!first.Due.Equal(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)) ||
+		!first.CreatedAt.Equal(time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Fatalf("first = %+v", first)
+	}
+	if items[1].Description != "Plain" || items[1].Status != "" {
+		t.Fatalf("second = %+v", items[1])
+	}
+}
+
+// TestTodo_CSV_Errors checks a missing description column and a bad date
+// are validation errors naming the line.
+func TestTodo_CSV_Errors(t *testing.T) {
+	if _, err := (CSVCodec{}).Decode(strings.NewReader("id,status\n1,started\n")); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("no description column: err=%v", err)
+	}
+	_, err := CSVCodec{Comma: '\t'}.Decode(strings.NewReader("description\tdue\nok\t2024-05-10\nbad\tsoon\n"))
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "tsv: line 3: due") {
+		t.Fatalf("bad date: err=%v", err)
+	}
+}
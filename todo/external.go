@@ -0,0 +1,239 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+//
+// todo/external.go (package todo)
+// -------------------------------
+// Importers for other tools' exports (Trello boards, Todoist projects,
+// GitHub issues). Unlike a Codec a Source only reads, and every record it
+// returns carries the task's id in its tool. ImportExternal remembers those
+// ids in ExternalIDs, kept beside the data file, so importing a newer
+// export of the same board again only adds the tasks that are new.
+//
+
+// ExternalRecord is one task read from another tool's export.
+type ExternalRecord struct {
+	// Ref identifies the task in its tool: a Trello card id, a GitHub issue
+	// URL. Records without a Ref are always imported.
+	Ref  string
+	Item Item
+}
+
+// Source reads the export files of one tool.
+type Source interface {
+	// Name is the source's name for -format, e.g. "trello".
+	Name() string
+	Decode(r io.Reader) ([]ExternalRecord, error)
+}
+
+var sources = map[string]Source{}
+
+// RegisterSource makes s available by its name. A later registration for
+// the same name wins.
+func RegisterSource(s Source) {
+	sources[s.Name()] = s
+}
+
+// SourceNames lists the registered sources, sorted.
+func SourceNames() []string {
+	names := make([]string, 0, len(sources))
+	for n := range sources {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SourceFor returns the source called name (case-insensitive), if any.
+func SourceFor(name string) (Source, bool) {
+	s, ok := sources[strings.ToLower(strings.TrimSpace(name))]
+	return s, ok
+}
+
+// ExternalIDs maps each source's refs to the items imported from them:
+// {"trello": {"5f2b...": {"id": 12, "created_at": "..."}}}.
+type ExternalIDs map[string]map[string]ExternalItem
+
+// ExternalItem identifies the item a ref was imported as. Ids are reused
+// once the highest one is deleted, so the creation time is kept too: an
+// item with the id but another creation time is a different item.
+type ExternalItem struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Lookup returns the item imported from ref, if any.
+func (ids ExternalIDs) Lookup(source, ref string) (ExternalItem, bool) {
+	ei, ok := ids[source][ref]
+	return ei, ok
+}
+
+// in reports whether the item ei names is still in list.
+func (ei ExternalItem) in(list []Item) bool {
+	for _, it := range list {
+		if it.ID == ei.ID {
+			return it.CreatedAt.Equal(ei.CreatedAt)
+		}
+	}
+	return false
+}
+
+// ExternalImport is the outcome of ImportExternal.
+type ExternalImport struct {
+	// List is the list with the new items appended.
+	List []Item
+	// Added are the new items, with their ids.
+	Added []Item
+	// Skipped are the records imported before (or repeated in the export);
+	// Item.ID is the id of the item they already are.
+	Skipped []ExternalRecord
+	// IDs is a copy of the ids given to ImportExternal plus the new refs.
+	IDs ExternalIDs
+}
+
+// ImportExternal appends the records read by source to list, as Import
+// does for target, skipping records whose Ref was imported before and
+// whose item is still in list (same id and creation time). Nothing is
+// added unless every new record is valid. ids is not modified.
+func ImportExternal(list []Item, source string, records []ExternalRecord, ids ExternalIDs, target string) (ExternalImport, error) {
+	res := ExternalImport{IDs: ExternalIDs{}, List: append([]Item(nil), list...), Added: []Item{}}
+	for s, refs := range ids {
+		res.IDs[s] = make(map[string]ExternalItem, len(refs))
+		for ref, ei := range refs {
+			res.IDs[s][ref] = ei
+		}
+	}
+	if res.IDs[source] == nil {
+		res.IDs[source] = map[string]ExternalItem{}
+	}
+
+	now := time.Now()
+	for i, rec := range records {
+		if ei, ok := res.IDs.Lookup(source, rec.Ref); ok && rec.Ref != "" && ei.in(res.List) {
+			rec.Item.ID = ei.ID
+			res.Skipped = append(res.Skipped, rec)
+			continue
+		}
+		it, err := newChecks(nil).importRecord(rec.Item, target, now)
+		if err != nil {
+			name := rec.Ref
+			if name == "" {
+				name = "item " + strconv.Itoa(i+1)
+			}
+			return ExternalImport{List: list, IDs: ids}, invalidf("%s: %s: %w", source, name, err)
+		}
+		it.ID = getNextID(res.List)
+		res.List = append(res.List, it)
+		res.Added = append(res.Added, it)
+		if rec.Ref != "" {
+			res.IDs[source][rec.Ref] = ExternalItem{ID: it.ID, CreatedAt: it.CreatedAt}
+		}
+	}
+	return res, nil
+}
+
+// ExternalIDsPath is where the external ids for the data file at dataPath
+// are kept: out/todos.json -> out/todos.external.json.
+func ExternalIDsPath(dataPath string) string {
+	return strings.TrimSuffix(dataPath, filepath.Ext(dataPath)) + ".external.json"
+}
+
+// LoadExternalIDs reads the ids at path; a missing file means none.
+func LoadExternalIDs(ctx context.Context, path string) (ExternalIDs, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ExternalIDs{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := ExternalIDs{}
+	if err := json.Unmarshal(b, &ids); err != nil {
+		slog.ErrorContext(ctx, "failed to parse external ids", "error", err, "path", path)
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SaveExternalIDs writes ids to path atomically.
+func SaveExternalIDs(ctx context.Context, ids ExternalIDs, path string) error {
+	if err := ensureParentDir(path); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ids, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0o644); err != nil {
+		slog.ErrorContext(ctx, "failed to save external ids", "error", err, "path", path)
+		return err
+	}
+	return nil
+}
+
+// labelPriorities maps label words to priorities: "P0", "urgent",
+// "priority: high" and "High priority" are priority labels, not tags.
+var labelPriorities = map[string]string{
+	"p0": "A", "p1": "B", "p2": "C", "p3": "D",
+	"critical": "A", "urgent": "A", "blocker": "A",
+	"high": "B", "medium": "C", "normal": "C", "low": "D",
+}
+
+// labelTags turns the labels of a card or issue into a priority (the
+// highest priority label) and @context tags for the rest, e.g. "good
+// first issue" -> "@good-first-issue". Labels without a name are dropped.
+func labelTags(labels []string) (priority string, tags []string) {
+	for _, l := range labels {
+		tag := slug(l)
+		if tag == "" {
+			continue
+		}
+		key := strings.Trim(strings.ReplaceAll("-"+tag+"-", "-priority-", "-"), "-")
+		if p, ok := labelPriorities[key]; ok {
+			if priority == "" || p < priority {
+				priority = p
+			}
+			continue
+		}
+		tags = append(tags, "@"+tag)
+	}
+	return priority, tags
+}
+
+// slug lower-cases s and joins its words with "-".
+func slug(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+// withTags appends tags that are not yet words of desc.
+func withTags(desc string, tags []string) string {
+	have := map[string]bool{}
+	for _, w := range strings.Fields(desc) {
+		have[strings.ToLower(w)] = true
+	}
+	for _, t := range tags {
+		if !have[t] {
+			desc += " " + t
+			have[t] = true
+		}
+	}
+	return desc
+}
@@ -0,0 +1,133 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTodo_ImportExternal imports records twice and checks the second run
+// only adds the new one, that deleted items come back, and that ids given
+// in are left alone.
+func TestTodo_ImportExternal(t *testing.T) {
+	list := []Item{{ID: 1, Description: "Already here", Status: StatusNotStarted}}
+	records := []ExternalRecord{
+		{Ref: "a", Item: Item{Description: "Card A", Status: StatusStarted}},
+		{Ref: "b", Item: Item{Description: "Card B"}},
+		{Ref: "a", Item: Item{Description: "Card A again"}},
+		{Item: Item{Description: "No ref"}},
+	}
+	ids := ExternalIDs{"github": {"#1": {ID: 1}}}
+	res, err := ImportExternal(list, "trello", records, ids, "work")
+	if err != nil {
+		t.Fatalf("ImportExternal: %v", err)
+	}
+	if len(res.Added) != 3 || len(res.List) != 4 || len(res.Skipped) != 1 || res.Skipped[0].Item.ID != 2 {
+		t.Fatalf("first import: added %+v, skipped %+v", res.Added, res.Skipped)
+	}
+	if a := res.Added[0]; a.ID != 2 || a.List != "work" || a.Status != StatusStarted || a.CreatedAt.IsZero() {
+		t.Fatalf("Card A: %+v", a)
+	}
+	want := ExternalIDs{"github": {"#1": {ID: 1}}, "trello": {
+		"a": {ID: 2, CreatedAt: res.Added[0].CreatedAt},
+		"b": {ID: 3, CreatedAt: res.Added[1].CreatedAt},
+	}}
+	if !reflect.DeepEqual(res.IDs, want) || len(ids) != 1 {
+		t.Fatalf("ids: %v (given: %v)", res.IDs, ids)
+	}
+
+	// Card B was deleted since: it is imported again, A is skipped.
+	list, _ = Delete(res.List, 3)
+	records = append(records, ExternalRecord{Ref: "c", Item: Item{Description: "Card C"}})
+	again, err := ImportExternal(list, "trello", records, res.IDs, "work")
+	if err != nil {
+		t.Fatalf("ImportExternal again: %v", err)
+	}
+	var added []string
+	for _, it := range again.Added {
+		added = append(added, it.Description)
+	}
+	if strings.Join(added, ",") != "Card B,No ref,Card C" || len(again.Skipped) != 2 {
+		t.Fatalf("second import: added %v, skipped %+v", added, again.Skipped)
+	}
+
+	_, err = ImportExternal(list, "trello", []ExternalRecord{{Ref: "x", Item: Item{Description: " "}}}, nil, "")
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "trello: x:") {
+		t.Fatalf("invalid record: %v", err)
+	}
+}
+
+// TestTodo_ImportExternal_ReusedID deletes an imported item and adds an
+// unrelated one that gets its id: re-importing brings the task back instead
+// of taking the new item for it.
+func TestTodo_ImportExternal_ReusedID(t *testing.T) {
+	created := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+	records := []ExternalRecord{{Ref: "#7", Item: Item{Description: "Fix the login", CreatedAt: created}}}
+	res, err := ImportExternal(nil, "github", records, nil, "")
+	if err != nil || len(res.Added) != 1 || res.Added[0].ID != 1 {
+		t.Fatalf("first import: %v %+v", err, res.Added)
+	}
+	list, _ := Delete(res.List, 1)
+	list, added, err := Add(list, "Unrelated groceries", StatusNotStarted)
+	if err != nil || added.ID != 1 {
+		t.Fatalf("add: %v %+v", err, added)
+	}
+
+	again, err := ImportExternal(list, "github", records, res.IDs, "")
+	if err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if len(again.Added) != 1 || len(again.Skipped) != 0 || again.Added[0].Description != "Fix the login" || again.Added[0].ID != 2 {
+		t.Fatalf("re-import: added %+v, skipped %+v", again.Added, again.Skipped)
+	}
+	if ei, _ := again.IDs.Lookup("github", "#7"); ei.ID != 2 || !ei.CreatedAt.Equal(created) {
+		t.Fatalf("mapping after re-import: %+v", ei)
+	}
+	// And once more: now it is there.
+	if third, err := ImportExternal(again.List, "github", records, again.IDs, ""); err != nil || len(third.Added) != 0 || len(third.Skipped) != 1 {
+		t.Fatalf("third import: %v %+v", err, third.Added)
+	}
+}
+
+// TestTodo_ExternalIDs_SaveLoad round-trips the ids file beside a data file.
+func TestTodo_ExternalIDs_SaveLoad(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	ctx := context.Background()
+	path := ExternalIDsPath(filepath.Join(t.TempDir(), "todos.json"))
+	if filepath.Base(path) != "todos.external.json" {
+		t.Fatalf("ExternalIDsPath: %s", path)
+	}
+	if ids, err := LoadExternalIDs(ctx, path); err != nil || len(ids) != 0 {
+		t.Fatalf("missing file: %v %v", ids, err)
+	}
+	want := ExternalIDs{"trello": {"5f2b": {ID: 3, CreatedAt: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)}}}
+	if err := SaveExternalIDs(ctx, want, path); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadExternalIDs(ctx, path); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("LoadExternalIDs: %v %v", got, err)
+	}
+}
+
+// TestTodo_LabelTags checks priority labels and tag names.
+func TestTodo_LabelTags(t *testing.T) {
+	p, tags := labelTags([]string{"bug", "Priority: High", "good first issue", "", "P0"})
+	if p != "A" || strings.Join(tags, " ") != "@bug @good-first-issue" {
+		t.Fatalf("labelTags: %q %v", p, tags)
+	}
+	if got := withTags("Fix @bug", tags); got != "Fix @bug @good-first-issue" {
+		t.Fatalf("withTags: %q", got)
+	}
+	if names := SourceNames(); strings.Join(names, ",") != "github,todoist,trello" {
+		t.Fatalf("SourceNames: %v", names)
+	}
+	if _, ok := SourceFor(" Trello "); !ok {
+		t.Fatal("SourceFor is not case-insensitive")
+	}
+}
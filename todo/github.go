@@ -0,0 +1,99 @@
+package todo
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// todo/github.go (package todo)
+// -----------------------------
+// GitHub issues as listed by the gh CLI:
+//
+//	gh issue list --state all --limit 1000 \
+//	  --json number,title,state,labels,createdAt,closedAt,url,milestone
+//
+// Open issues are not started, or started with an "in progress" label;
+// closed ones are completed at closedAt. A milestone's due date becomes
+// the due date, and labels become a priority or @context tags (see
+// labelTags). The issue URL is the ref, so issues from several
+// repositories can go into one list. Pull requests (gh pr list) read the
+// same way, MERGED counting as closed.
+//
+
+// GitHubSource reads `gh issue list --json` output.
+type GitHubSource struct{}
+
+func (GitHubSource) Name() string { return "github" }
+
+// githubIssue is the part of an issue the importer uses.
+type githubIssue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+	ClosedAt  time.Time `json:"closedAt"`
+	Labels    []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Milestone *struct {
+		DueOn time.Time `json:"dueOn"`
+	} `json:"milestone"`
+}
+
+// githubStartedLabels mark an open issue as started.
+var githubStartedLabels = map[string]bool{"in-progress": true, "doing": true, "wip": true, "started": true}
+
+func (s GitHubSource) Decode(r io.Reader) ([]ExternalRecord, error) {
+	var issues []githubIssue
+	if err := json.NewDecoder(r).Decode(&issues); err != nil {
+		return nil, invalidf("github: %w (want the JSON array written by gh issue list --json)", err)
+	}
+	records := make([]ExternalRecord, 0, len(issues))
+	for _, is := range issues {
+		ref := is.URL
+		if ref == "" && is.Number > 0 {
+			ref = "#" + strconv.Itoa(is.Number)
+		}
+		labels := []string{}
+		started := false
+		for _, l := range is.Labels {
+			if githubStartedLabels[slug(l.Name)] {
+				started = true
+				continue
+			}
+			labels = append(labels, l.Name)
+		}
+		priority, tags := labelTags(labels)
+		it := Item{
+			Description: withTags(strings.TrimSpace(is.Title), tags),
+			Status:      StatusNotStarted,
+			Priority:    priority,
+			CreatedAt:   is.CreatedAt,
+		}
+		switch strings.ToUpper(is.State) {
+		case "CLOSED", "MERGED":
+			it.Status = StatusCompleted
+			it.CompletedAt = is.ClosedAt
+		case "OPEN", "":
+			if started {
+				it.Status = StatusStarted
+			}
+		default:
+			return nil, invalidf("github: %s: unknown state %q", ref, is.State)
+		}
+		if is.Milestone != nil && !is.Milestone.DueOn.IsZero() {
+			it.Due = DueDate(is.Milestone.DueOn)
+		}
+		records = append(records, ExternalRecord{Ref: ref, Item: it})
+	}
+	return records, nil
+}
+
+func init() {
+	RegisterSource(GitHubSource{})
+}
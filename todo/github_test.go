@@ -0,0 +1,53 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const githubSample = `[
+  {"number": 12, "title": "Crash on empty config", "state": "OPEN",
+   "url": "https://github.com/acme/app/issues/12", "createdAt": "2024-04-02T10:00:00Z", "closedAt": null,
+   "labels": [{"id": "1", "name": "bug", "color": "d73a4a"}, {"id": "2", "name": "priority: high"}, {"id": "3", "name": "in progress"}],
+   "milestone": {"number": 1, "title": "v1.2", "dueOn": "2024-06-30T07:00:00Z"}},
+  {"number": 9, "title": "Document the API", "state": "CLOSED",
+   "url": "https://github.com/acme/app/issues/9", "createdAt": "2024-03-01T08:00:00Z", "closedAt": "2024-03-15T17:30:00Z",
+   "labels": [{"name": "good first issue"}], "milestone": null},
+  {"number": 3, "title": "Add dark mode", "state": "OPEN", "createdAt": "2024-02-01T08:00:00Z", "labels": []}
+]`
+
+// TestTodo_GitHub_Decode reads gh issue list output: states, labels,
+// milestone due dates and refs.
+func TestTodo_GitHub_Decode(t *testing.T) {
+	recs, err := GitHubSource{}.Decode(strings.NewReader(githubSample))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("got %d records: %+v", len(recs), recs)
+	}
+	crash := recs[0]
+	if crash.Ref != "https://github.com/acme/app/issues/12" || crash.Item.Description != "Crash on empty config @bug" ||
+		crash.Item.Status != StatusStarted || crash.Item.Priority != "B" ||
+		!crash.Item.Due.Equal(time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)) ||
+		!crash.Item.CreatedAt.Equal(time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("issue 12: %+v", crash)
+	}
+	docs := recs[1].Item
+	if docs.Status != StatusCompleted || !docs.CompletedAt.Equal(time.Date(2024, 3, 15, 17, 30, 0, 0, time.UTC)) ||
+		docs.Description != "Document the API @good-first-issue" {
+		t.Fatalf("issue 9: %+v", docs)
+	}
+	if recs[2].Ref != "#3" || recs[2].Item.Status != StatusNotStarted {
+		t.Fatalf("issue 3: %+v", recs[2])
+	}
+
+	if _, err := (GitHubSource{}).Decode(strings.NewReader(`{"number": 1}`)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("not an array: %v", err)
+	}
+	if _, err := (GitHubSource{}).Decode(strings.NewReader(`[{"number": 1, "title": "x", "state": "LOCKED"}]`)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("unknown state: %v", err)
+	}
+}
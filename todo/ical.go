@@ -0,0 +1,340 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//
+// todo/ical.go (package todo)
+// ---------------------------
+// iCalendar (RFC 5545) VTODO components, so calendar and task apps can show
+// the items and so tasks exported from them can be imported. Each item is
+// one VTODO:
+//
+//	SUMMARY      the description
+//	STATUS       NEEDS-ACTION, IN-PROCESS or COMPLETED
+//	CREATED      CreatedAt; DTSTAMP repeats the latest of it and COMPLETED
+//	DUE          the due date (VALUE=DATE)
+//	COMPLETED    CompletedAt
+//	PRIORITY     1-9 for A-I (J-Z are written as 9)
+//	RRULE        Recurrence, with DTSTART on the due (else created) date
+//	UID          todo-<id>@todo-app
+//	X-TODO-LIST  the list, when not the default one
+//
+// Decoding also understands what other tools write: CANCELLED counts as
+// completed, a COMPLETED time completes the item, DUE and COMPLETED may be
+// local times with a TZID, and components other than VTODO are skipped.
+//
+
+const (
+	icalProdID    = "-//todo-app//todo-app//EN"
+	icalUIDSuffix = "@todo-app"
+	icalListProp  = "X-TODO-LIST"
+	icalStamp     = "20060102T150405Z"
+	icalLocal     = "20060102T150405"
+	icalDate      = "20060102"
+	icalLineLimit = 75 // octets per line before folding
+)
+
+// ICalCodec is the iCalendar VTODO codec.
+type ICalCodec struct{}
+
+func (ICalCodec) Name() string        { return "ical" }
+func (ICalCodec) ContentType() string { return "text/calendar; charset=utf-8" }
+
+func (ICalCodec) Encode(w io.Writer, items []Item) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) { writeFolded(bw, s) }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:" + icalProdID)
+	for _, it := range items {
+		line("BEGIN:VTODO")
+		line(fmt.Sprintf("UID:todo-%d%s", it.ID, icalUIDSuffix))
+		stamp := it.CreatedAt
+		if it.CompletedAt.After(stamp) {
+			stamp = it.CompletedAt
+		}
+		if !stamp.IsZero() {
+			line("DTSTAMP:" + stamp.UTC().Format(icalStamp))
+		}
+		if !it.CreatedAt.IsZero() {
+			line("CREATED:" + it.CreatedAt.UTC().Format(icalStamp))
+		}
+		line("SUMMARY:" + icalEscape(it.Description))
+		line("STATUS:" + icalStatus(it.Status))
+		if p := icalPriority(it.Priority); p != 0 {
+			line("PRIORITY:" + strconv.Itoa(p))
+		}
+		if !it.Due.IsZero() {
+			line("DUE;VALUE=DATE:" + it.Due.Format(icalDate))
+		}
+		if it.Recurrence != "" {
+			start := it.Due
+			if start.IsZero() {
+				start = it.CreatedAt
+			}
+			if !start.IsZero() {
+				line("DTSTART;VALUE=DATE:" + start.Format(icalDate))
+			}
+			line("RRULE:" + it.Recurrence)
+		}
+		if !it.CompletedAt.IsZero() {
+			line("COMPLETED:" + it.CompletedAt.UTC().Format(icalStamp))
+		}
+		if it.List != "" && it.List != DefaultList {
+			line(icalListProp + ":" + icalEscape(it.List))
+		}
+		line("END:VTODO")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+func (ICalCodec) Decode(r io.Reader) ([]Item, error) {
+	items := []Item{}
+	var cur *Item
+	depth := 0 // components nested inside the current VTODO, e.g. VALARM
+	lines, err := unfoldICal(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		name, params, value, ok := parseICalLine(l.text)
+		if !ok {
+			if strings.TrimSpace(l.text) == "" {
+				continue
+			}
+			return nil, invalidf("ical: line %d: not a content line", l.n)
+		}
+		switch {
+		case name == "BEGIN" && cur == nil && strings.EqualFold(value, "VTODO"):
+			cur = &Item{Status: StatusNotStarted}
+			continue
+		case name == "BEGIN" && cur != nil:
+			depth++
+			continue
+		case name == "END" && cur != nil && depth > 0:
+			depth--
+			continue
+		case name == "END" && cur != nil:
+			if cur.Description == "" {
+				return nil, invalidf("ical: line %d: VTODO without a SUMMARY", l.n)
+			}
+			items = append(items, *cur)
+			cur = nil
+			continue
+		}
+		if cur == nil || depth > 0 {
+			continue
+		}
+		if err := setICalProp(cur, name, params, value); err != nil {
+			return nil, invalidf("ical: line %d: %s: %w", l.n, name, err)
+		}
+	}
+	if cur != nil {
+		return nil, invalidf("ical: VTODO not closed with END:VTODO")
+	}
+	return items, nil
+}
+
+// setICalProp copies one VTODO property into it; unknown ones are ignored.
+func setICalProp(it *Item, name string, params map[string]string, value string) error {
+	var err error
+	switch name {
+	case "SUMMARY":
+		it.Description = strings.TrimSpace(icalUnescape(value))
+	case "STATUS":
+		switch strings.ToUpper(value) {
+		case "NEEDS-ACTION":
+			if it.CompletedAt.IsZero() {
+				it.Status = StatusNotStarted
+			}
+		case "IN-PROCESS":
+			if it.CompletedAt.IsZero() {
+				it.Status = StatusStarted
+			}
+		case "COMPLETED", "CANCELLED":
+			it.Status = StatusCompleted
+		default:
+			return invalidf("unknown status %q", value)
+		}
+	case "CREATED":
+		it.CreatedAt, err = parseICalTime(value, params)
+	case "COMPLETED":
+		if it.CompletedAt, err = parseICalTime(value, params); err == nil {
+			it.Status = StatusCompleted
+		}
+	case "DUE":
+		var due time.Time
+		if due, err = parseICalTime(value, params); err == nil {
+			it.Due = DueDate(due)
+		}
+	case "PRIORITY":
+		n, convErr := strconv.Atoi(strings.TrimSpace(value))
+		if convErr != nil || n < 0 || n > 9 {
+			return invalidf("invalid priority %q (want 0-9)", value)
+		}
+		it.Priority = ""
+		if n > 0 {
+			it.Priority = string(rune('A' + n - 1))
+		}
+	case "RRULE":
+		it.Recurrence, err = ValidateRecurrence(value)
+	case "UID":
+		// Our own UIDs give back the id; other tools' UIDs are not ids.
+		if rest, ok := strings.CutPrefix(value, "todo-"); ok {
+			if id, ok := strings.CutSuffix(rest, icalUIDSuffix); ok {
+				it.ID, _ = strconv.Atoi(id)
+			}
+		}
+	case icalListProp:
+		it.List = icalUnescape(value)
+	}
+	return err
+}
+
+// icalStatus maps a Status onto the VTODO STATUS values.
+func icalStatus(s Status) string {
+	switch s {
+	case StatusStarted:
+		return "IN-PROCESS"
+	case StatusCompleted:
+		return "COMPLETED"
+	}
+	return "NEEDS-ACTION"
+}
+
+// icalPriority maps A-I onto 1-9 and the rest of the alphabet onto 9;
+// 0 means no priority.
+func icalPriority(p string) int {
+	if p == "" {
+		return 0
+	}
+	return min(int(p[0]-'A')+1, 9)
+}
+
+// parseICalTime reads a DATE or DATE-TIME value: UTC ("...Z"), local to the
+// TZID parameter, or floating (taken as UTC).
+func parseICalTime(value string, params map[string]string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	loc := time.UTC
+	if tz := params["TZID"]; tz != "" {
+		if l, err := time.LoadLocation(strings.Trim(tz, `"`)); err == nil {
+			loc = l
+		}
+	}
+	for _, layout := range []string{icalStamp, icalLocal, icalDate} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, invalidf("invalid date %q (want YYYYMMDD or YYYYMMDDTHHMMSSZ)", value)
+}
+
+// icalEscape escapes TEXT values; line breaks become spaces, as in the other
+// line-based formats.
+func icalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`).Replace(oneLine(s))
+}
+
+// icalUnescape undoes icalEscape and the "\n" other tools write.
+func icalUnescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// writeFolded writes one content line ending in CRLF, folding it into
+// 75-octet pieces without splitting a UTF-8 sequence.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := icalLineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = icalLineLimit - 1 // the leading space counts
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+// icalLine is an unfolded content line and the line number it started on.
+type icalLine struct {
+	n    int
+	text string
+}
+
+// unfoldICal splits r into content lines, joining folded continuations.
+func unfoldICal(r io.Reader) ([]icalLine, error) {
+	var lines []icalLine
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		text := strings.TrimSuffix(sc.Text(), "\r")
+		if n == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if len(text) > 0 && (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		lines = append(lines, icalLine{n: n, text: text})
+	}
+	return lines, sc.Err()
+}
+
+// parseICalLine splits `NAME;PARAM=x;PARAM="y:z":value`. Names and parameter
+// names are upper-cased.
+func parseICalLine(s string) (name string, params map[string]string, value string, ok bool) {
+	quoted := false
+	colon := -1
+	for i := 0; i < len(s) && colon < 0; i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+	}
+	if colon <= 0 {
+		return "", nil, "", false
+	}
+	head, value := s[:colon], s[colon+1:]
+	parts := strings.Split(head, ";")
+	name = strings.ToUpper(strings.TrimSpace(parts[0]))
+	params = map[string]string{}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(strings.TrimSpace(k))] = v
+	}
+	return name, params, value, name != ""
+}
+
+func init() {
+	RegisterCodec(ICalCodec{}, ".ics")
+}
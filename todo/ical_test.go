@@ -0,0 +1,146 @@
+package todo
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// TestTodo_ICal_Decode reads a calendar as other apps write it: CRLF and
+// folded lines, escapes, TZID times, an alarm inside the VTODO, events to
+// skip, and a cancelled task.
+func TestTodo_ICal_Decode(t *testing.T) {
+	src := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Mozilla.org/NONSGML Mozilla Calendar V1.1//EN",
+		"BEGIN:VEVENT",
+		"UID:event-1",
+		"SUMMARY:Not a task",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:4f1c-9a@example.com",
+		"CREATED:20240501T093015Z",
+		"SUMMARY:Renew passport\\, visa\\; and ",
+		" insurance",
+		"PRIORITY:2",
+		"DUE;TZID=Europe/Berlin:20240610T013000",
+		"RRULE:FREQ=YEARLY",
+		"STATUS:IN-PROCESS",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:Should not become the summary",
+		"END:VALARM",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:todo-7@todo-app",
+		"SUMMARY:Old plan",
+		"STATUS:CANCELLED",
+		"PRIORITY:0",
+		"X-TODO-LIST:home",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"SUMMARY:Finished",
+		"COMPLETED:20240504T180000Z",
+		"STATUS:NEEDS-ACTION",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+	got, err := ICalCodec{}.Decode(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	want := []Item{
+		{Description: "Renew passport, visa; and insurance", Status: StatusStarted, Priority: "B",
+			CreatedAt: time.Date(2024, 5, 1, 9, 30, 15, 0, time.UTC), Due: day(2024, 6, 10), Recurrence: "FREQ=YEARLY"},
+		{ID: 7, Description: "Old plan", Status: StatusCompleted, List: "home"},
+		{Description: "Finished", Status: StatusCompleted, CompletedAt: time.Date(2024, 5, 4, 18, 0, 0, 0, time.UTC)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d items: %+v", len(got), got)
+	}
+	for i := range want {
+		// Compare times by instant; the TZID due date keeps Berlin's date.
+		g, w := got[i], want[i]
+		if !g.CreatedAt.Equal(w.CreatedAt) || !g.CompletedAt.Equal(w.CompletedAt) || !g.Due.Equal(w.Due) {
+			t.Errorf("item %d times: %+v\nwant %+v", i, g, w)
+		}
+		g.CreatedAt, g.CompletedAt, g.Due = w.CreatedAt, w.CompletedAt, w.Due
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("item %d: %+v\nwant %+v", i, g, w)
+		}
+	}
+
+	for name, bad := range map[string]string{
+		"no summary":   "BEGIN:VTODO\r\nUID:x\r\nEND:VTODO\r\n",
+		"bad due":      "BEGIN:VTODO\r\nSUMMARY:x\r\nDUE:tomorrow\r\nEND:VTODO\r\n",
+		"bad rrule":    "BEGIN:VTODO\r\nSUMMARY:x\r\nRRULE:EVERY=DAY\r\nEND:VTODO\r\n",
+		"bad priority": "BEGIN:VTODO\r\nSUMMARY:x\r\nPRIORITY:high\r\nEND:VTODO\r\n",
+		"unclosed":     "BEGIN:VTODO\r\nSUMMARY:x\r\n",
+		"garbage":      "BEGIN:VTODO\r\nthis is not ical\r\nEND:VTODO\r\n",
+	} {
+		if _, err := (ICalCodec{}).Decode(strings.NewReader(bad)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: err = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+// TestTodo_ICal_Encode checks the properties written, CRLF line ends and
+// folding of long lines on rune boundaries.
+func TestTodo_ICal_Encode(t *testing.T) {
+	long := strings.Repeat("Überprüfung ", 12) + "done"
+	items := []Item{
+		{ID: 3, Description: "Water plants, weekly", Status: StatusNotStarted, Priority: "K",
+			CreatedAt: time.Date(2024, 5, 1, 9, 30, 15, 500, time.UTC), Due: day(2024, 5, 4), Recurrence: "FREQ=WEEKLY"},
+		{ID: 4, Description: long, Status: StatusCompleted, List: "work",
+			CreatedAt: day(2024, 5, 1), CompletedAt: time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)},
+	}
+	var buf bytes.Buffer
+	if err := (ICalCodec{}).Encode(&buf, items); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"BEGIN:VCALENDAR", "VERSION:2.0", "UID:todo-3@todo-app", "DTSTAMP:20240501T093015Z",
+		"SUMMARY:Water plants\\, weekly", "STATUS:NEEDS-ACTION", "PRIORITY:9", "DUE;VALUE=DATE:20240504",
+		"DTSTART;VALUE=DATE:20240504", "RRULE:FREQ=WEEKLY", "UID:todo-4@todo-app", "DTSTAMP:20240502T080000Z",
+		"STATUS:COMPLETED", "COMPLETED:20240502T080000Z", "X-TODO-LIST:work", "END:VCALENDAR",
+	} {
+		if !strings.Contains(out, "\r\n"+line+"\r\n") && !strings.HasPrefix(out, line+"\r\n") {
+			t.Errorf("missing %q in:\n%s", line, out)
+		}
+	}
+	for _, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(l) > 75 || !utf8.ValidString(l) {
+			t.Errorf("line too long or split inside a rune: %q", l)
+		}
+	}
+	got, err := ICalCodec{}.Decode(&buf)
+	if err != nil || got[1].Description != long {
+		t.Fatalf("folded summary did not survive: %v %q", err, got)
+	}
+}
+
+// TestTodo_ValidateRecurrence checks RRULE values are normalised and bad
+// ones rejected.
+func TestTodo_ValidateRecurrence(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                             "",
+		"freq=daily":                   "FREQ=DAILY",
+		" RRULE:FREQ=WEEKLY;BYDAY=MO ": "FREQ=WEEKLY;BYDAY=MO",
+		"INTERVAL=2;FREQ=MONTHLY":      "INTERVAL=2;FREQ=MONTHLY",
+	} {
+		if got, err := ValidateRecurrence(in); err != nil || got != want {
+			t.Errorf("ValidateRecurrence(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"weekly", "FREQ=FORTNIGHTLY", "INTERVAL=2", "FREQ=DAILY;;", "FREQ=DAILY; COUNT=2"} {
+		if _, err := ValidateRecurrence(bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ValidateRecurrence(%q) err = %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package todo
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+//
+// todo/markdown.go (package todo)
+// -------------------------------
+// Markdown checklists, as kept in README or TODO.md files and rendered by
+// GitHub: "- [ ]" is not started, "- [/]" started and "- [x]" completed.
+// A priority is written "(A)" before the text and a due date as due:DATE
+// after it, as in todo.txt. When items come from more than one list each
+// list gets a "## name" heading; decoding puts the items under such a
+// heading into that list (a "# title" goes back to the default list).
+// Other lines (text, deeper headings, plain bullets) are ignored, so a
+// checklist can live inside a longer document.
+//
+//	## home
+//	- [ ] (A) Call the plumber due:2024-05-03
+//	- [x] Buy milk
+//
+
+// Checkbox marks. Decoding also accepts "X" for completed and "~" or "-"
+// for started, which other tools use.
+const (
+	boxNotStarted = "[ ]"
+	boxStarted    = "[/]"
+	boxCompleted  = "[x]"
+)
+
+// MarkdownCodec is the Markdown checklist codec.
+type MarkdownCodec struct{}
+
+func (MarkdownCodec) Name() string        { return "markdown" }
+func (MarkdownCodec) ContentType() string { return "text/markdown; charset=utf-8" }
+
+func (MarkdownCodec) Encode(w io.Writer, items []Item) error {
+	// Group by list in order of first appearance.
+	var order []string
+	byList := map[string][]Item{}
+	for _, it := range items {
+		l := ListOf(it)
+		if _, ok := byList[l]; !ok {
+			order = append(order, l)
+		}
+		byList[l] = append(byList[l], it)
+	}
+	headings := len(order) > 1 || len(order) == 1 && order[0] != DefaultList
+
+	bw := bufio.NewWriter(w)
+	for i, l := range order {
+		if headings {
+			if i > 0 {
+				bw.WriteString("\n")
+			}
+			bw.WriteString("## " + l + "\n\n")
+		}
+		for _, it := range byList[l] {
+			bw.WriteString(FormatChecklistLine(it))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+func (MarkdownCodec) Decode(r io.Reader) ([]Item, error) {
+	items := []Item{}
+	list := ""
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		switch level := len(line) - len(strings.TrimLeft(line, "#")); {
+		case level == 2:
+			list = strings.TrimSpace(line[2:])
+			if list == DefaultList {
+				list = ""
+			}
+			continue
+		case level == 1:
+			list = "" // a document title
+			continue
+		case level > 2:
+			continue
+		}
+		it, ok, err := ParseChecklistLine(line)
+		if err != nil {
+			return nil, invalidf("markdown: line %d: %w", n, err)
+		}
+		if !ok {
+			continue
+		}
+		it.List = list
+		items = append(items, it)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FormatChecklistLine writes it as a "- [ ] ..." line (without its list).
+func FormatChecklistLine(it Item) string {
+	box := boxNotStarted
+	switch it.Status {
+	case StatusStarted:
+		box = boxStarted
+	case StatusCompleted:
+		box = boxCompleted
+	}
+	parts := []string{"-", box}
+	if it.Priority != "" {
+		parts = append(parts, "("+it.Priority+")")
+	}
+	parts = append(parts, escapeDesc(it.Description, false, isPriority))
+	parts = append(parts, metaTokens(it, false)...)
+	return strings.Join(parts, " ")
+}
+
+// ParseChecklistLine reads a checklist line; ok is false for any other
+// line. Headings (and so lists) are the caller's business.
+func ParseChecklistLine(line string) (it Item, ok bool, err error) {
+	line = strings.TrimSpace(line)
+	if len(line) < 2 || !strings.ContainsRune("-*+", rune(line[0])) || line[1] != ' ' {
+		return Item{}, false, nil
+	}
+	rest := strings.TrimSpace(line[2:])
+	if len(rest) < 3 || rest[0] != '[' || rest[2] != ']' {
+		return Item{}, false, nil
+	}
+	switch rest[1] {
+	case ' ':
+		it.Status = StatusNotStarted
+	case '/', '~', '-':
+		it.Status = StatusStarted
+	case 'x', 'X':
+		it.Status = StatusCompleted
+	default:
+		return Item{}, false, nil
+	}
+	words := strings.Fields(rest[3:])
+	if len(words) > 0 && isPriority(words[0]) {
+		it.Priority = words[0][1:2]
+		words = words[1:]
+	}
+	it.Description = strings.Join(takeMeta(&it, words, false), " ")
+	if it.Description == "" {
+		return Item{}, false, invalidf("no description")
+	}
+	return it, true, nil
+}
+
+func init() {
+	RegisterCodec(MarkdownCodec{}, ".md", ".markdown")
+}
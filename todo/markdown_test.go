@@ -0,0 +1,68 @@
+package todo
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestTodo_Markdown_Decode reads a checklist inside a longer document.
+func TestTodo_Markdown_Decode(t *testing.T) {
+	doc := `# Project notes
+
+Some prose with - [ ] not at the start.
+
+- [ ] (A) Ship it due:2024-05-10
+* [X] Tag release
+- plain bullet
+
+## home
+  - [~] Paint fence
++ [-] Fix tap
+### details
+- [x] Buy paint
+
+## default
+- [ ] Back to default
+- [?] unknown mark
+`
+	items, err := MarkdownCodec{}.Decode(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	var got []string
+	for _, it := range items {
+		got = append(got, it.Description+"|"+string(it.Status)+"|"+it.List+"|"+it.Priority+"|"+formatDate(it.Due))
+	}
+	want := []string{
+		"Ship it|not started||A|2024-05-10",
+		"Tag release|completed|||",
+		"Paint fence|started|home||",
+		"Fix tap|started|home||",
+		"Buy paint|completed|home||",
+		"Back to default|not started|||",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("items:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, err := (MarkdownCodec{}).Decode(strings.NewReader("- [ ]   \n")); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("empty item: err=%v", err)
+	}
+}
+
+// TestTodo_Markdown_Encode checks headings only appear when items span
+// lists.
+func TestTodo_Markdown_Encode(t *testing.T) {
+	var buf bytes.Buffer
+	_ = MarkdownCodec{}.Encode(&buf, []Item{{Description: "a", Status: StatusStarted}, {Description: "b", Status: StatusCompleted, Priority: "C"}})
+	if want := "- [/] a\n- [x] (C) b\n"; buf.String() != want {
+		t.Fatalf("one list:\n%q\nwant %q", buf.String(), want)
+	}
+	buf.Reset()
+	_ = MarkdownCodec{}.Encode(&buf, []Item{{Description: "a"}, {Description: "b", List: "home"}, {Description: "c"}})
+	if want := "## default\n\n- [ ] a\n- [ ] c\n\n## home\n\n- [ ] b\n"; buf.String() != want {
+		t.Fatalf("two lists:\n%q\nwant %q", buf.String(), want)
+	}
+}
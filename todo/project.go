@@ -0,0 +1,268 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//
+// todo/project.go (package todo)
+// ------------------------------
+// Projects (named lists of items) inside one data file. Every Item carries
+// the name of the project it belongs to in its List field; the project
+// metadata (description, archived flag and which project the CLI is currently
+// using) lives in a small registry stored next to the data file, e.g.
+// out/todos.json -> out/todos.projects.json.
+//
+
+// DefaultList is the list that items without an explicit list belong to.
+const DefaultList = "default"
+
+// AllLists is the filter value that matches items in every list.
+const AllLists = "*"
+
+// Project is a named list of items.
+type Project struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Archived    bool      `json:"archived"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Projects is the registry of lists plus the one currently selected.
+type Projects struct {
+	Current string    `json:"current"`
+	Lists   []Project `json:"lists"`
+}
+
+// ListOf returns the list an item belongs to (DefaultList when unset).
+func ListOf(it Item) string {
+	if it.List == "" {
+		return DefaultList
+	}
+	return it.List
+}
+
+// FilterByList returns the items in list name; AllLists or "" returns everything.
+func FilterByList(list []Item, name string) []Item {
+	if name == "" || name == AllLists {
+		return list
+	}
+	out := []Item{}
+	for _, it := range list {
+		if ListOf(it) == name {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// AddToList is Add for a named list: the new item belongs to listName.
+func AddToList(list []Item, listName, desc string, status Status, opts ...Option) ([]Item, Item, error) {
+	list, item, err := Add(list, desc, status, opts...)
+	if err != nil {
+		return list, item, err
+	}
+	if listName != "" && listName != DefaultList {
+		item.List = listName
+		list[len(list)-1].List = listName
+	}
+	return list, item, nil
+}
+
+// validateListName rejects names that would be ambiguous on the command line.
+func validateListName(name string) error {
+	if name == "" {
+		return invalidf("project name cannot be empty")
+	}
+	if name == AllLists || strings.ContainsAny(name, "\n\t") {
+		return invalidf("invalid project name %q", name)
+	}
+	return nil
+}
+
+// DefaultProjects is the registry used before any list has been created.
+func DefaultProjects() Projects {
+	return Projects{
+		Current: DefaultList,
+		Lists:   []Project{{Name: DefaultList, CreatedAt: time.Now()}},
+	}
+}
+
+// Find returns the project called name.
+func (p Projects) Find(name string) (Project, bool) {
+	for _, pr := range p.Lists {
+		if pr.Name == name {
+			return pr, true
+		}
+	}
+	return Project{}, false
+}
+
+// CreateProject adds a new, unarchived list.
+func CreateProject(p Projects, name, desc string) (Projects, Project, error) {
+	name = strings.TrimSpace(name)
+	if err := validateListName(name); err != nil {
+		return p, Project{}, err
+	}
+	if _, ok := p.Find(name); ok {
+		return p, Project{}, invalidf("project %q already exists", name)
+	}
+	pr := Project{Name: name, Description: strings.TrimSpace(desc), CreatedAt: time.Now()}
+	p.Lists = append(p.Lists, pr)
+	return p, pr, nil
+}
+
+// RenameProject renames a list and moves its items along with it. The
+// default list keeps its name: items without a list belong to it.
+func RenameProject(p Projects, items []Item, from, to string) (Projects, []Item, error) {
+	to = strings.TrimSpace(to)
+	switch DefaultList {
+	case from:
+		return p, items, invalidf("the %q project cannot be renamed", DefaultList)
+	case to:
+		return p, items, invalidf("%q is the default project's name", DefaultList)
+	}
+	if err := validateListName(to); err != nil {
+		return p, items, err
+	}
+	if _, ok := p.Find(to); ok {
+		return p, items, invalidf("project %q already exists", to)
+	}
+	found := false
+	for i := range p.Lists {
+		if p.Lists[i].Name == from {
+			p.Lists[i].Name = to
+			found = true
+		}
+	}
+	if !found {
+		return p, items, invalidf("no project named %q", from)
+	}
+	for i := range items {
+		if ListOf(items[i]) == from {
+			items[i].List = to
+		}
+	}
+	if p.Current == from {
+		p.Current = to
+	}
+	return p, items, nil
+}
+
+// SetArchived archives or restores a list. The current list cannot be archived.
+func SetArchived(p Projects, name string, archived bool) (Projects, error) {
+	if archived && p.Current == name {
+		return p, invalidf("cannot archive the current project %q; switch to another project first", name)
+	}
+	for i := range p.Lists {
+		if p.Lists[i].Name == name {
+			p.Lists[i].Archived = archived
+			return p, nil
+		}
+	}
+	return p, invalidf("no project named %q", name)
+}
+
+// SwitchProject makes name the current list. Archived lists cannot be selected.
+func SwitchProject(p Projects, name string) (Projects, error) {
+	pr, ok := p.Find(name)
+	if !ok {
+		return p, invalidf("no project named %q", name)
+	}
+	if pr.Archived {
+		return p, invalidf("project %q is archived", name)
+	}
+	p.Current = name
+	return p, nil
+}
+
+// CheckWritable returns an error unless items may be added to list name.
+func (p Projects) CheckWritable(name string) error {
+	pr, ok := p.Find(name)
+	if !ok {
+		return invalidf("no project named %q (create it with: todo projects create %q)", name, name)
+	}
+	if pr.Archived {
+		return invalidf("project %q is archived", name)
+	}
+	return nil
+}
+
+// Migrate moves items without a list into DefaultList, and registers any list
+// referenced by an item but missing from the registry. It returns the number
+// of items that were moved.
+func Migrate(p Projects, items []Item) (Projects, []Item, int) {
+	if _, ok := p.Find(DefaultList); !ok {
+		p.Lists = append([]Project{{Name: DefaultList, CreatedAt: time.Now()}}, p.Lists...)
+	}
+	if p.Current == "" {
+		p.Current = DefaultList
+	}
+	moved := 0
+	for i := range items {
+		if items[i].List == "" {
+			items[i].List = DefaultList
+			moved++
+		}
+		if _, ok := p.Find(items[i].List); !ok {
+			p.Lists = append(p.Lists, Project{Name: items[i].List, CreatedAt: time.Now()})
+		}
+	}
+	return p, items, moved
+}
+
+// ProjectsPath returns the registry path for a data file path.
+func ProjectsPath(dataPath string) string {
+	ext := filepath.Ext(dataPath)
+	return strings.TrimSuffix(dataPath, ext) + ".projects.json"
+}
+
+// LoadProjects reads the registry at path. A missing file yields DefaultProjects.
+func LoadProjects(ctx context.Context, path string) (Projects, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return DefaultProjects(), nil
+		}
+		slog.ErrorContext(ctx, "failed to read file", "error", err, "path", path)
+		return Projects{}, err
+	}
+	var p Projects
+	if err := json.Unmarshal(b, &p); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal JSON", "error", err, "path", path)
+		return Projects{}, err
+	}
+	if p.Current == "" {
+		p.Current = DefaultList
+	}
+	if _, ok := p.Find(DefaultList); !ok && p.Current == DefaultList {
+		p.Lists = append([]Project{{Name: DefaultList, CreatedAt: time.Now()}}, p.Lists...)
+	}
+	return p, nil
+}
+
+// SaveProjects writes the registry to path.
+func SaveProjects(ctx context.Context, p Projects, path string) error {
+	if err := ensureParentDir(path); err != nil {
+		slog.ErrorContext(ctx, "failed to create output directory", "error", err, "path", path)
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		slog.ErrorContext(ctx, "failed to save lists", "error", err, "path", path)
+		return err
+	}
+	slog.InfoContext(ctx, "lists saved", "path", path, "count", len(p.Lists))
+	return nil
+}
@@ -0,0 +1,96 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestTodo_Projects_CreateRenameArchiveSwitch walks a list through its lifecycle.
+func TestTodo_Projects_CreateRenameArchiveSwitch(t *testing.T) {
+	p := DefaultProjects()
+	p, _, err := CreateProject(p, "work", "day job")
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if _, _, err := CreateProject(p, "work", ""); err == nil {
+		t.Fatalf("duplicate CreateProject should fail")
+	}
+
+	items, _, _ := AddToList(nil, "work", "write report", StatusNotStarted)
+	items, _, _ = AddToList(items, DefaultList, "buy milk", StatusNotStarted)
+	if items[1].List != "" {
+		t.Fatalf("default list should be stored as empty, got %q", items[1].List)
+	}
+
+	p, err = SwitchProject(p, "work")
+	if err != nil || p.Current != "work" {
+		t.Fatalf("SwitchProject = %+v, %v", p, err)
+	}
+	p, items, err = RenameProject(p, items, "work", "office")
+	if err != nil {
+		t.Fatalf("RenameProject: %v", err)
+	}
+	if p.Current != "office" || items[0].List != "office" {
+		t.Fatalf("rename did not carry current/items: %+v %+v", p, items)
+	}
+	for _, names := range [][2]string{{DefaultList, "home"}, {"office", DefaultList}} {
+		if _, _, err := RenameProject(p, items, names[0], names[1]); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("RenameProject(%q, %q) = %v, want ErrInvalid", names[0], names[1], err)
+		}
+	}
+	if err := p.CheckWritable(DefaultList); err != nil {
+		t.Fatalf("default list after refused renames: %v", err)
+	}
+	if _, err := SetArchived(p, "office", true); err == nil {
+		t.Fatalf("archiving the current list should fail")
+	}
+	p, _ = SwitchProject(p, DefaultList)
+	p, err = SetArchived(p, "office", true)
+	if err != nil {
+		t.Fatalf("SetArchived: %v", err)
+	}
+	if err := p.CheckWritable("office"); err == nil {
+		t.Fatalf("archived list should not be writable")
+	}
+	if _, err := SwitchProject(p, "office"); err == nil {
+		t.Fatalf("switching to an archived list should fail")
+	}
+	if got := FilterByList(items, "office"); len(got) != 1 || got[0].Description != "write report" {
+		t.Fatalf("FilterByList(office) = %+v", got)
+	}
+	if got := FilterByList(items, AllLists); len(got) != 2 {
+		t.Fatalf("FilterByList(*) len=%d want 2", len(got))
+	}
+}
+
+// TestTodo_Projects_MigrateAndPersist checks the default-list migration and
+// the registry round trip.
+func TestTodo_Projects_MigrateAndPersist(t *testing.T) {
+	ctx := context.Background()
+	legacy := []Item{{ID: 1, Description: "old"}, {ID: 2, Description: "orphan", List: "ghost"}}
+	p, items, moved := Migrate(Projects{}, legacy)
+	if moved != 1 || items[0].List != DefaultList {
+		t.Fatalf("Migrate moved=%d items=%+v", moved, items)
+	}
+	if _, ok := p.Find("ghost"); !ok || p.Current != DefaultList {
+		t.Fatalf("Migrate registry=%+v", p)
+	}
+
+	path := ProjectsPath(filepath.Join(t.TempDir(), "out", "todos.json"))
+	if filepath.Base(path) != "todos.projects.json" {
+		t.Fatalf("ProjectsPath = %q", path)
+	}
+	if err := SaveProjects(ctx, p, path); err != nil {
+		t.Fatalf("SaveProjects: %v", err)
+	}
+	got, err := LoadProjects(ctx, path)
+	if err != nil || len(got.Lists) != 2 || got.Current != DefaultList {
+		t.Fatalf("LoadProjects = %+v, %v", got, err)
+	}
+	missing, err := LoadProjects(ctx, filepath.Join(t.TempDir(), "none.json"))
+	if err != nil || missing.Current != DefaultList || len(missing.Lists) != 1 {
+		t.Fatalf("LoadProjects(missing) = %+v, %v", missing, err)
+	}
+}
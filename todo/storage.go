@@ -24,6 +24,40 @@ func ensureParentDir(path string) error {
 	return os.MkdirAll(dir, 0o755)
 }
 
+// writeFileAtomic replaces path with data: temp file, fsync, rename, then a
+// best-effort fsync of the directory so the rename itself is durable.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op once renamed
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
 // Save serializes the given list to pretty-printed JSON and writes to `path`.
 // It ensures the parent directory exists (e.g., ./out/). On success, an info log
 // is emitted containing the path and the number of items.
@@ -41,8 +75,10 @@ func Save(ctx context.Context, list []Item, path string) error {
 		return err
 	}
 
-	// 3) Write with owner-readable defaults.
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	// 3) Write with owner-readable defaults. The data goes to a temp file that
+	// is fsynced and then renamed over path, so readers and crashes only ever
+	// see the old or the new contents, and a nil error means it is on disk.
+	if err := writeFileAtomic(path, data, 0o644); err != nil {
 		slog.ErrorContext(ctx, "failed to save todos", "error", err, "path", path)
 		return err
 	}
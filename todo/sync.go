@@ -0,0 +1,579 @@
+package todo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// todo/sync.go (package todo)
+// ---------------------------
+// Two-way sync between one list and a Markdown checklist (a TODO.md kept in
+// a repository). Each checklist line carries its item's id in a hidden
+// marker, which renders as nothing on GitHub:
+//
+//	- [x] Buy milk <!-- id:12 -->
+//
+// SyncChecklist is a three-way merge. The base is what both sides agreed on
+// after the previous sync; a side whose field still equals the base did not
+// change it, so the other side's value wins. When both sides changed the
+// same field differently, or one side deleted an item the other edited, the
+// item is a Conflict: both sides are left as they are (and the base too, so
+// the conflict is reported again) unless a side is preferred.
+//
+// Lines without a marker are new items (or, when no item is linked yet, an
+// item with the same description, so a first sync does not duplicate). Lines
+// the merge leaves unchanged are kept byte for byte; other text, headings
+// and blank lines are never touched. Items new in the store are appended
+// after the last checklist line.
+//
+// The base is kept in a SyncState file beside the data file, one per
+// checklist and list (see SyncStatePath).
+//
+
+// ErrConflict matches (via errors.Is) the error for a sync with conflicts.
+var ErrConflict = errors.New("conflicting changes")
+
+// SyncEntry is what a checklist line says about an item.
+type SyncEntry struct {
+	Description string    `json:"description"`
+	Status      Status    `json:"status"`
+	Priority    string    `json:"priority,omitempty"`
+	Due         time.Time `json:"due,omitzero"`
+}
+
+func entryOf(it Item) SyncEntry {
+	return SyncEntry{Description: it.Description, Status: it.Status, Priority: it.Priority, Due: it.Due}
+}
+
+func (e SyncEntry) equal(o SyncEntry) bool {
+	return e.Description == o.Description && e.Status == o.Status && e.Priority == o.Priority && e.Due.Equal(o.Due)
+}
+
+// SyncBase is the state after the last sync, by item id.
+type SyncBase map[int]SyncEntry
+
+// Sides of a sync, for SyncOptions.Prefer and Conflict.
+const (
+	SideFile  = "file"
+	SideStore = "store"
+)
+
+// SyncOptions tune SyncChecklist.
+type SyncOptions struct {
+	// List is the list the file mirrors ("" or DefaultList for the default).
+	List string
+	// Prefer resolves conflicts in favour of SideFile or SideStore; empty
+	// reports them instead.
+	Prefer string
+	// Now stamps created and completed items; zero means time.Now().
+	Now time.Time
+}
+
+// Conflict is an item both sides changed since the last sync.
+type Conflict struct {
+	ID     int
+	Fields []string   // the fields changed on both sides; empty for edit vs delete
+	File   *SyncEntry // nil: deleted from the file
+	Store  *SyncEntry // nil: deleted from the store
+}
+
+func (c Conflict) String() string {
+	switch {
+	case c.File == nil:
+		return fmt.Sprintf("item %d: deleted from the file but changed in the store", c.ID)
+	case c.Store == nil:
+		return fmt.Sprintf("item %d: deleted from the store but changed in the file", c.ID)
+	}
+	var diffs []string
+	for _, f := range c.Fields {
+		diffs = append(diffs, fmt.Sprintf("%s: file %q, store %q", f, c.File.field(f), c.Store.field(f)))
+	}
+	return fmt.Sprintf("item %d: changed on both sides (%s)", c.ID, strings.Join(diffs, "; "))
+}
+
+// SyncStats counts the changes made to one side.
+type SyncStats struct {
+	Added, Updated, Removed int
+}
+
+func (s SyncStats) String() string {
+	return fmt.Sprintf("%d added, %d updated, %d removed", s.Added, s.Updated, s.Removed)
+}
+
+// SyncResult is the outcome of SyncChecklist.
+type SyncResult struct {
+	List      []Item   // the whole store (every list) after the sync
+	Doc       string   // the new file contents
+	Base      SyncBase // the state to pass to the next sync
+	Store     SyncStats
+	File      SyncStats
+	Conflicts []Conflict
+}
+
+// Changed reports whether either side was modified.
+func (r SyncResult) Changed() bool {
+	return r.Store != (SyncStats{}) || r.File != (SyncStats{})
+}
+
+// syncMarker matches the id marker at the end of a checklist line.
+var syncMarker = regexp.MustCompile(`\s*<!--\s*id:\s*(\d+)\s*-->\s*$`)
+
+// FormatSyncLine writes it as a checklist line with its id marker.
+func FormatSyncLine(it Item) string {
+	return FormatChecklistLine(it) + " <!-- id:" + strconv.Itoa(it.ID) + " -->"
+}
+
+// syncLine is one line of the file.
+type syncLine struct {
+	raw    string
+	item   bool // a checklist line
+	indent string
+	id     int // from the marker; 0 for a new line
+	entry  SyncEntry
+}
+
+// parseSyncDoc splits doc into lines and reads the checklist lines. A marker
+// repeated on a later line (a copied line) makes that line a new item.
+func parseSyncDoc(doc string) ([]syncLine, error) {
+	text := strings.TrimSuffix(strings.ReplaceAll(doc, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	seen := map[int]bool{}
+	var lines []syncLine
+	for n, raw := range strings.Split(text, "\n") {
+		l := syncLine{raw: raw}
+		body := raw
+		if m := syncMarker.FindStringSubmatchIndex(raw); m != nil {
+			body = raw[:m[0]]
+			l.id, _ = strconv.Atoi(raw[m[2]:m[3]])
+		}
+		it, ok, err := ParseChecklistLine(body)
+		if err != nil {
+			return nil, invalidf("line %d: %w", n+1, err)
+		}
+		if ok {
+			l.item = true
+			l.indent = body[:len(body)-len(strings.TrimLeft(body, " \t"))]
+			l.entry = entryOf(it)
+			if seen[l.id] {
+				l.id = 0
+			}
+			seen[l.id] = l.id != 0
+		} else {
+			l.id = 0
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+// SyncChecklist merges the checklist doc with the items of opts.List in
+// list, given the base from the previous sync (nil for the first one).
+// Conflicts are part of the result, not an error; the error is for a file
+// that cannot be read as a checklist or changes the store would reject.
+func SyncChecklist(list []Item, doc string, base SyncBase, opts SyncOptions) (SyncResult, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	target := opts.List
+	if target == DefaultList {
+		target = ""
+	}
+	lines, err := parseSyncDoc(doc)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	// The store side: the list's items, in order.
+	store := map[int]Item{}
+	var storeOrder []int
+	for _, it := range FilterByList(list, opts.List) {
+		store[it.ID] = it
+		storeOrder = append(storeOrder, it.ID)
+	}
+	fileAt := map[int]int{} // id -> line index
+	for i, l := range lines {
+		if l.item && l.id != 0 {
+			fileAt[l.id] = i
+		}
+	}
+	// A marker for an item that is neither in the store nor the base (say,
+	// one copied from another repository) makes the line new.
+	for id, i := range fileAt {
+		_, inStore := store[id]
+		_, inBase := base[id]
+		if !inStore && !inBase {
+			lines[i].id = 0
+			delete(fileAt, id)
+		}
+	}
+	// Link unmarked lines to unlinked store items with the same description.
+	byDesc := map[string][]int{}
+	for _, id := range storeOrder {
+		_, inBase := base[id]
+		_, inFile := fileAt[id]
+		if !inBase && !inFile {
+			d := store[id].Description
+			byDesc[d] = append(byDesc[d], id)
+		}
+	}
+	for i, l := range lines {
+		if ids := byDesc[l.entry.Description]; l.item && l.id == 0 && len(ids) > 0 {
+			lines[i].id, byDesc[l.entry.Description] = ids[0], ids[1:]
+			fileAt[ids[0]] = i
+		}
+	}
+
+	ids := map[int]bool{}
+	for id := range base {
+		ids[id] = true
+	}
+	for id := range store {
+		ids[id] = true
+	}
+	for id := range fileAt {
+		ids[id] = true
+	}
+	sorted := make([]int, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Ints(sorted)
+
+	res := SyncResult{Base: SyncBase{}}
+	next := append([]Item(nil), list...)
+	remove := map[int]bool{}   // store items to delete
+	dropLine := map[int]bool{} // file lines to delete
+	appendID := map[int]bool{} // store items to add to the file
+	rewrite := map[int]bool{}  // file lines to write again, by id
+	for _, id := range sorted {
+		b, inBase := base[id]
+		it, inStore := store[id]
+		s := entryOf(it)
+		i, inFile := fileAt[id]
+		var f SyncEntry
+		if inFile {
+			f = lines[i].entry
+		}
+		switch {
+		case !inStore && !inFile:
+			// Deleted on both sides.
+		case inStore && !inFile && !inBase:
+			appendID[id] = true
+			res.Base[id] = s
+			res.File.Added++
+		case !inStore && inFile: // deleted from the store since the last sync
+			switch {
+			case f.equal(b) || opts.Prefer == SideStore:
+				dropLine[i] = true
+				res.File.Removed++
+			case opts.Prefer == SideFile:
+				lines[i].id = 0 // added again below, with a new id
+			default:
+				res.Conflicts = append(res.Conflicts, Conflict{ID: id, File: &f})
+				res.Base[id] = b
+			}
+		case inStore && !inFile: // deleted from the file since the last sync
+			switch {
+			case s.equal(b) || opts.Prefer == SideFile:
+				remove[id] = true
+				res.Store.Removed++
+			case opts.Prefer == SideStore:
+				appendID[id] = true
+				res.Base[id] = s
+				res.File.Added++
+			default:
+				res.Conflicts = append(res.Conflicts, Conflict{ID: id, Store: &s})
+				res.Base[id] = b
+			}
+		default: // on both sides
+			var merged SyncEntry
+			var fields []string
+			switch {
+			case inBase:
+				merged, fields = mergeEntry(b, s, f, opts.Prefer)
+			case f.equal(s) || opts.Prefer == SideStore:
+				merged = s
+			case opts.Prefer == SideFile:
+				merged = f
+			default:
+				// Never synced: nothing tells which side changed what.
+				fields = diffFields(f, s)
+			}
+			if len(fields) > 0 {
+				res.Conflicts = append(res.Conflicts, Conflict{ID: id, Fields: fields, File: &f, Store: &s})
+				if inBase {
+					res.Base[id] = b
+				}
+				continue
+			}
+			res.Base[id] = merged
+			if !merged.equal(s) {
+				if next, err = applyEntry(next, id, merged, now); err != nil {
+					return SyncResult{}, invalidf("line %d: %w", i+1, err)
+				}
+				res.Store.Updated++
+			}
+			if !merged.equal(f) {
+				rewrite[id] = true
+				res.File.Updated++
+			}
+		}
+	}
+
+	// Store: deletions, then the file's new lines.
+	kept := next[:0:0]
+	for _, it := range next {
+		if !remove[it.ID] {
+			kept = append(kept, it)
+		}
+	}
+	next = kept
+	for i, l := range lines {
+		if !l.item || l.id != 0 {
+			continue
+		}
+		rec := Item{Description: l.entry.Description, Status: l.entry.Status, Priority: l.entry.Priority, Due: l.entry.Due, List: target}
+		it, err := newChecks(nil).importRecord(rec, "", now)
+		if err != nil {
+			return SyncResult{}, invalidf("line %d: %w", i+1, err)
+		}
+		it.ID = getNextID(next)
+		next = append(next, it)
+		lines[i].id = it.ID
+		res.Base[it.ID] = entryOf(it)
+		res.Store.Added++
+	}
+	res.List = next
+
+	// File: rewrite changed lines and add markers, then append the store's
+	// new items after the last checklist line. Conflicting lines keep the
+	// file's text and only gain their marker.
+	current := map[int]Item{}
+	for _, it := range next {
+		current[it.ID] = it
+	}
+	conflicted := map[int]bool{}
+	for _, c := range res.Conflicts {
+		conflicted[c.ID] = true
+	}
+	var out []string
+	last := -1
+	for i, l := range lines {
+		if dropLine[i] {
+			continue
+		}
+		if l.item {
+			it, ok := current[l.id]
+			switch {
+			case !ok:
+			case conflicted[l.id]:
+				if !hasMarker(l.raw, l.id) {
+					l.raw = syncMarker.ReplaceAllString(l.raw, "") + " <!-- id:" + strconv.Itoa(l.id) + " -->"
+				}
+			case rewrite[l.id] || !hasMarker(l.raw, l.id):
+				l.raw = l.indent + FormatSyncLine(it)
+			}
+			last = len(out)
+		}
+		out = append(out, l.raw)
+	}
+	if last < 0 {
+		last = len(out) - 1
+	}
+	var tail []string
+	for _, id := range storeOrder {
+		if appendID[id] {
+			tail = append(tail, FormatSyncLine(current[id]))
+		}
+	}
+	out = append(out[:last+1], append(tail, out[last+1:]...)...)
+	if len(out) > 0 {
+		res.Doc = strings.Join(out, "\n") + "\n"
+	}
+	return res, nil
+}
+
+// mergeEntry merges the file and store versions of an item field by field.
+// fields lists those both sides changed differently (none when prefer
+// settles them).
+func mergeEntry(b, s, f SyncEntry, prefer string) (m SyncEntry, fields []string) {
+	m = s
+	take := func(name string, fromFile, conflict bool, set func()) {
+		switch {
+		case conflict:
+			fields = append(fields, name)
+		case fromFile:
+			set()
+		}
+	}
+	ff, c := side3(b.Description, s.Description, f.Description, prefer)
+	take("description", ff, c, func() { m.Description = f.Description })
+	ff, c = side3(b.Status, s.Status, f.Status, prefer)
+	take("status", ff, c, func() { m.Status = f.Status })
+	ff, c = side3(b.Priority, s.Priority, f.Priority, prefer)
+	take("priority", ff, c, func() { m.Priority = f.Priority })
+	ff, c = side3(b.Due.Unix(), s.Due.Unix(), f.Due.Unix(), prefer)
+	take("due", ff, c, func() { m.Due = f.Due })
+	return m, fields
+}
+
+// side3 decides one field of a three-way merge: fromFile when only the file
+// changed it (or prefer says so), conflict when both sides changed it
+// differently.
+func side3[T comparable](b, s, f T, prefer string) (fromFile, conflict bool) {
+	switch {
+	case s == f, f == b:
+		return false, false
+	case s == b, prefer == SideFile:
+		return true, false
+	case prefer == SideStore:
+		return false, false
+	}
+	return false, true
+}
+
+// diffFields names the fields in which a and b differ.
+func diffFields(a, b SyncEntry) []string {
+	var out []string
+	for _, name := range []string{"description", "status", "priority", "due"} {
+		if a.field(name) != b.field(name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// field returns the named field as text.
+func (e SyncEntry) field(name string) string {
+	switch name {
+	case "description":
+		return e.Description
+	case "status":
+		return string(e.Status)
+	case "priority":
+		return e.Priority
+	case "due":
+		return formatDate(e.Due)
+	}
+	return ""
+}
+
+// applyEntry sets the checklist fields of item id to e.
+func applyEntry(list []Item, id int, e SyncEntry, now time.Time) ([]Item, error) {
+	if err := newChecks(nil).checkDescription(e.Description); err != nil {
+		return nil, err
+	}
+	if err := e.Status.Validate(); err != nil {
+		return nil, err
+	}
+	p, err := ValidatePriority(e.Priority)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].ID == id {
+			list[i].Description = e.Description
+			list[i].setStatus(e.Status, now)
+			list[i].Priority = p
+			list[i].Due = e.Due
+			return list, nil
+		}
+	}
+	return nil, notFoundError(id)
+}
+
+// hasMarker reports whether raw ends in the marker for id.
+func hasMarker(raw string, id int) bool {
+	m := syncMarker.FindStringSubmatch(raw)
+	return m != nil && m[1] == strconv.Itoa(id)
+}
+
+// SyncState is the base saved after a sync, with what it belongs to.
+type SyncState struct {
+	File     string    `json:"file"`
+	List     string    `json:"list"`
+	SyncedAt time.Time `json:"synced_at"`
+	Items    SyncBase  `json:"items"`
+}
+
+// SyncStatePath is where the state of syncing file with list is kept for
+// the data file at dataPath: out/todos.json and TODO.md give
+// out/todos.sync/TODO.md-<hash>.json, the hash telling apart checklists
+// with the same name in different directories.
+func SyncStatePath(dataPath, file, list string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		abs = file
+	}
+	sum := sha256.Sum256([]byte(abs + "\x00" + list))
+	dir := strings.TrimSuffix(dataPath, filepath.Ext(dataPath)) + ".sync"
+	return filepath.Join(dir, filepath.Base(file)+"-"+hex.EncodeToString(sum[:4])+".json")
+}
+
+// LoadSyncState reads the state at path. A missing file is the zero state
+// (nothing synced yet).
+func LoadSyncState(ctx context.Context, path string) (SyncState, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return SyncState{}, nil
+	}
+	if err != nil {
+		return SyncState{}, err
+	}
+	var st SyncState
+	if err := json.Unmarshal(b, &st); err != nil {
+		slog.ErrorContext(ctx, "failed to parse sync state", "error", err, "path", path)
+		return SyncState{}, err
+	}
+	return st, nil
+}
+
+// SaveSyncState writes st to path atomically.
+func SaveSyncState(ctx context.Context, st SyncState, path string) error {
+	if err := ensureParentDir(path); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0o644); err != nil {
+		slog.ErrorContext(ctx, "failed to save sync state", "error", err, "path", path)
+		return err
+	}
+	return nil
+}
+
+// SaveChecklist replaces the checklist at path with doc atomically, keeping
+// the file's permissions.
+func SaveChecklist(ctx context.Context, path, doc string) error {
+	perm := fs.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	if err := ensureParentDir(path); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, []byte(doc), perm); err != nil {
+		slog.ErrorContext(ctx, "failed to save checklist", "error", err, "path", path)
+		return err
+	}
+	return nil
+}
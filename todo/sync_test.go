@@ -0,0 +1,176 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syncRound runs SyncChecklist for the "home" list and fails on an error.
+func syncRound(t *testing.T, list []Item, doc string, base SyncBase, prefer string) SyncResult {
+	t.Helper()
+	res, err := SyncChecklist(list, doc, base, SyncOptions{List: "home", Prefer: prefer, Now: time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("SyncChecklist: %v", err)
+	}
+	return res
+}
+
+func findItem(t *testing.T, list []Item, id int) Item {
+	t.Helper()
+	for _, it := range list {
+		if it.ID == id {
+			return it
+		}
+	}
+	t.Fatalf("no item %d in %+v", id, list)
+	return Item{}
+}
+
+// TestTodo_Sync_Rounds takes a file and a store through a first sync,
+// independent edits on both sides, deletions, and a no-op round.
+func TestTodo_Sync_Rounds(t *testing.T) {
+	list := []Item{
+		{ID: 1, Description: "Buy milk", Status: StatusNotStarted, List: "home"},
+		{ID: 2, Description: "Call mom", Status: StatusStarted, List: "home"},
+		{ID: 3, Description: "Elsewhere", Status: StatusNotStarted, List: "work"},
+	}
+	doc := "# House\n\nNotes stay put.\n\n- [ ] Buy milk\n  - [x] (B) Fix tap due:2024-05-10\n\n## Later\n"
+
+	// First sync: "Buy milk" is linked, "Fix tap" is new, "Call mom" is
+	// appended after the last checklist line; other items are left alone.
+	res := syncRound(t, list, doc, nil, "")
+	want := "# House\n\nNotes stay put.\n\n- [ ] Buy milk <!-- id:1 -->\n  - [x] (B) Fix tap due:2024-05-10 <!-- id:4 -->\n- [/] Call mom <!-- id:2 -->\n\n## Later\n"
+	if res.Doc != want {
+		t.Fatalf("first sync doc:\n%s\nwant:\n%s", res.Doc, want)
+	}
+	if len(res.List) != 4 || res.Store != (SyncStats{Added: 1}) || res.File != (SyncStats{Added: 1}) || len(res.Conflicts) != 0 {
+		t.Fatalf("first sync: store %v, file %v, conflicts %v, list %+v", res.Store, res.File, res.Conflicts, res.List)
+	}
+	tap := findItem(t, res.List, 4)
+	if tap.List != "home" || tap.Status != StatusCompleted || tap.CompletedAt.IsZero() || tap.Priority != "B" || tap.Due.IsZero() {
+		t.Fatalf("new item from the file: %+v", tap)
+	}
+
+	// Nothing changed: nothing to do, and the file is kept byte for byte.
+	again := syncRound(t, res.List, res.Doc, res.Base, "")
+	if again.Changed() || again.Doc != res.Doc {
+		t.Fatalf("no-op sync changed something: %v %v\n%s", again.Store, again.File, again.Doc)
+	}
+
+	// The file checks off Buy milk and drops Fix tap; the store renames Buy
+	// milk and completes Call mom. Both sides' edits survive.
+	doc = strings.Replace(res.Doc, "- [ ] Buy milk", "- [x] Buy milk", 1)
+	doc = strings.Replace(doc, "  - [x] (B) Fix tap due:2024-05-10 <!-- id:4 -->\n", "", 1)
+	list, _ = UpdateDescription(res.List, 1, "Buy oat milk")
+	list, _ = UpdateStatus(list, 2, StatusCompleted)
+	res = syncRound(t, list, doc, res.Base, "")
+	if len(res.Conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", res.Conflicts)
+	}
+	milk := findItem(t, res.List, 1)
+	if milk.Description != "Buy oat milk" || milk.Status != StatusCompleted || len(res.List) != 3 {
+		t.Fatalf("merged store: %+v", res.List)
+	}
+	if !strings.Contains(res.Doc, "- [x] Buy oat milk <!-- id:1 -->\n") || !strings.Contains(res.Doc, "- [x] Call mom <!-- id:2 -->\n") ||
+		strings.Contains(res.Doc, "Fix tap") {
+		t.Fatalf("merged doc:\n%s", res.Doc)
+	}
+
+	// The store deletes Call mom: its line goes.
+	list, _ = Delete(res.List, 2)
+	res = syncRound(t, list, res.Doc, res.Base, "")
+	if strings.Contains(res.Doc, "Call mom") || res.File.Removed != 1 {
+		t.Fatalf("store deletion not applied to the file:\n%s", res.Doc)
+	}
+}
+
+// TestTodo_Sync_Conflicts checks both sides changing one field, edit versus
+// delete, and resolving with a preferred side.
+func TestTodo_Sync_Conflicts(t *testing.T) {
+	list := []Item{{ID: 1, Description: "Buy milk", Status: StatusNotStarted, List: "home"}}
+	first := syncRound(t, list, "- [ ] Buy milk\n", nil, "")
+
+	doc := strings.Replace(first.Doc, "Buy milk", "Buy bread", 1)
+	edited, _ := UpdateDescription(first.List, 1, "Buy cheese")
+	res := syncRound(t, edited, doc, first.Base, "")
+	if len(res.Conflicts) != 1 || res.Conflicts[0].ID != 1 || strings.Join(res.Conflicts[0].Fields, ",") != "description" {
+		t.Fatalf("conflicts: %+v", res.Conflicts)
+	}
+	msg := res.Conflicts[0].String()
+	if !strings.Contains(msg, `file "Buy bread"`) || !strings.Contains(msg, `store "Buy cheese"`) {
+		t.Fatalf("conflict message: %s", msg)
+	}
+	if res.Doc != doc || findItem(t, res.List, 1).Description != "Buy cheese" || res.Base[1] != first.Base[1] {
+		t.Fatalf("a conflict changed a side or the base:\n%s\n%+v", res.Doc, res.List)
+	}
+	// Reported again next time, until resolved.
+	if again := syncRound(t, res.List, res.Doc, res.Base, ""); len(again.Conflicts) != 1 {
+		t.Fatalf("conflict not reported again: %+v", again.Conflicts)
+	}
+	won := syncRound(t, res.List, res.Doc, res.Base, SideFile)
+	if len(won.Conflicts) != 0 || findItem(t, won.List, 1).Description != "Buy bread" {
+		t.Fatalf("-prefer file: %+v %+v", won.Conflicts, won.List)
+	}
+	won = syncRound(t, res.List, res.Doc, res.Base, SideStore)
+	if len(won.Conflicts) != 0 || !strings.Contains(won.Doc, "Buy cheese <!-- id:1 -->") {
+		t.Fatalf("-prefer store: %+v\n%s", won.Conflicts, won.Doc)
+	}
+
+	// Deleted from the file, edited in the store.
+	res = syncRound(t, edited, "", first.Base, "")
+	if len(res.Conflicts) != 1 || res.Conflicts[0].File != nil || len(res.List) != 1 ||
+		!strings.Contains(res.Conflicts[0].String(), "deleted from the file") {
+		t.Fatalf("edit vs delete: %+v", res.Conflicts)
+	}
+	// Deleted from the store, edited in the file.
+	res = syncRound(t, nil, doc, first.Base, "")
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Store != nil || res.Doc != doc {
+		t.Fatalf("delete vs edit: %+v", res.Conflicts)
+	}
+	res = syncRound(t, nil, doc, first.Base, SideFile)
+	if len(res.List) != 1 || res.List[0].Description != "Buy bread" || !strings.Contains(res.Doc, "<!-- id:1 -->") {
+		t.Fatalf("-prefer file re-creates the item: %+v\n%s", res.List, res.Doc)
+	}
+}
+
+// TestTodo_Sync_FirstSyncConflict links a line to a store item by its
+// description on a first sync: when they disagree, the line keeps its text
+// and only gains the marker, so the conflict is reported again next time.
+func TestTodo_Sync_FirstSyncConflict(t *testing.T) {
+	list := []Item{{ID: 1, Description: "Buy milk", Status: StatusNotStarted, List: "home"}}
+	res := syncRound(t, list, "- [x] Buy milk\n", nil, "")
+	if len(res.Conflicts) != 1 || strings.Join(res.Conflicts[0].Fields, ",") != "status" {
+		t.Fatalf("conflicts: %+v", res.Conflicts)
+	}
+	if res.Doc != "- [x] Buy milk <!-- id:1 -->\n" || findItem(t, res.List, 1).Status != StatusNotStarted {
+		t.Fatalf("a conflict changed a side:\n%s\n%+v", res.Doc, res.List)
+	}
+	again := syncRound(t, res.List, res.Doc, res.Base, "")
+	if len(again.Conflicts) != 1 || again.Doc != res.Doc {
+		t.Fatalf("conflict lost on the next sync: %+v\n%s", again.Conflicts, again.Doc)
+	}
+	if won := syncRound(t, res.List, res.Doc, res.Base, SideFile); findItem(t, won.List, 1).Status != StatusCompleted {
+		t.Fatalf("-prefer file: %+v", won.List)
+	}
+}
+
+// TestTodo_Sync_Lines covers copied markers, foreign markers and lines the
+// store would reject.
+func TestTodo_Sync_Lines(t *testing.T) {
+	list := []Item{{ID: 1, Description: "Buy milk", Status: StatusNotStarted, List: "home"}}
+	first := syncRound(t, list, "- [ ] Buy milk\n", nil, "")
+
+	doc := first.Doc + "- [ ] Buy milk <!-- id:1 -->\n- [ ] From another repo <!-- id:99 -->\n"
+	res := syncRound(t, first.List, doc, first.Base, "")
+	if len(res.List) != 3 || res.Store.Added != 2 || !strings.Contains(res.Doc, "Buy milk <!-- id:2 -->") ||
+		!strings.Contains(res.Doc, "From another repo <!-- id:3 -->") {
+		t.Fatalf("copied and foreign markers: %+v\n%s", res.List, res.Doc)
+	}
+
+	_, err := SyncChecklist(first.List, "text\n- [ ] (A) due:2024-05-10\n", first.Base, SyncOptions{List: "home"})
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("bad line: %v", err)
+	}
+}
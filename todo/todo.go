@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 //
@@ -29,17 +30,155 @@ func (s Status) Validate() error {
 	case StatusNotStarted, StatusStarted, StatusCompleted:
 		return nil
 	default:
-		return fmt.Errorf("invalid status: %q (allowed: %q, %q, %q)", s, StatusNotStarted, StatusStarted, StatusCompleted)
+		return invalidf("invalid status: %q (allowed: %q, %q, %q)", s, StatusNotStarted, StatusStarted, StatusCompleted)
 	}
 }
 
+// ErrNotFound matches (via errors.Is) the error returned for an unknown id.
+var ErrNotFound = errors.New("to-do not found")
+
+// notFoundError reports a missing id while still matching ErrNotFound.
+type notFoundError int
+
+func (e notFoundError) Error() string        { return fmt.Sprintf("no to-do with id %d", int(e)) }
+func (e notFoundError) Is(target error) bool { return target == ErrNotFound }
+
+// ErrInvalid matches (via errors.Is) every validation error: a bad status,
+// description, list name or batch, or a list that cannot take items.
+var ErrInvalid = errors.New("invalid input")
+
+// invalidError is a validation failure that still matches ErrInvalid; its
+// message and wrapped errors are those of err.
+type invalidError struct{ err error }
+
+func (e invalidError) Error() string        { return e.err.Error() }
+func (e invalidError) Unwrap() error        { return e.err }
+func (e invalidError) Is(target error) bool { return target == ErrInvalid }
+
+func invalidf(format string, args ...any) error {
+	return invalidError{err: fmt.Errorf(format, args...)}
+}
+
+// MaxDescriptionLen caps item descriptions, in characters (runes). Every
+// entry point (CLI, HTTP, batch, shared lists) checks it unless it passes
+// its own MaxDescription.
+const MaxDescriptionLen = 1000
+
+// Option adjusts the checks of the functions that create or edit items.
+type Option func(*checks)
+
+// checks are the limits Add, UpdateDescription, ApplyBatch and Import apply.
+type checks struct {
+	maxDescription int
+}
+
+// MaxDescription sets the description limit to n characters; n <= 0
+// removes it.
+func MaxDescription(n int) Option {
+	return func(c *checks) { c.maxDescription = n }
+}
+
+func newChecks(opts []Option) checks {
+	c := checks{maxDescription: MaxDescriptionLen}
+	for _, o := range opts {
+		o(&c)
+	}
+	return c
+}
+
+// ErrDescriptionTooLong matches (via errors.Is) descriptions over MaxDescriptionLen.
+var ErrDescriptionTooLong = errors.New("description too long")
+
+// checkDescription rejects a trimmed description that is empty or too long.
+func (c checks) checkDescription(desc string) error {
+	if desc == "" {
+		return invalidf("description cannot be empty")
+	}
+	if n := utf8.RuneCountInString(desc); c.maxDescription > 0 && n > c.maxDescription {
+		return invalidf("%w: %d characters (the limit is %d)", ErrDescriptionTooLong, n, c.maxDescription)
+	}
+	return nil
+}
+
 // Item is the domain entity persisted in JSON.
 // ID is a simple integer; CreatedAt is stored as RFC3339 in the JSON.
+// List names the list (project) the item belongs to; empty means DefaultList.
 type Item struct {
 	ID          int       `json:"id"`
 	Description string    `json:"description"`
 	Status      Status    `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
+	List        string    `json:"list,omitempty"`
+	// Priority is "A" (highest) to "Z", or empty for none (as in todo.txt).
+	Priority string `json:"priority,omitempty"`
+	// Due is the due date, midnight UTC; zero means none.
+	Due time.Time `json:"due,omitzero"`
+	// CompletedAt is set when the item becomes completed and cleared when
+	// it is reopened.
+	CompletedAt time.Time `json:"completed_at,omitzero"`
+	// Recurrence is an iCalendar RRULE value such as "FREQ=WEEKLY;BYDAY=MO",
+	// or empty for an item that does not repeat.
+	Recurrence string `json:"recurrence,omitempty"`
+}
+
+// ValidatePriority accepts "" or a single letter A-Z (either case) and
+// returns it upper-cased.
+func ValidatePriority(p string) (string, error) {
+	p = strings.ToUpper(strings.TrimSpace(p))
+	if p == "" || len(p) == 1 && p[0] >= 'A' && p[0] <= 'Z' {
+		return p, nil
+	}
+	return "", invalidf("invalid priority %q (use a letter A-Z)", p)
+}
+
+// recurrenceFreqs are the FREQ values RFC 5545 allows.
+var recurrenceFreqs = map[string]bool{
+	"SECONDLY": true, "MINUTELY": true, "HOURLY": true, "DAILY": true,
+	"WEEKLY": true, "MONTHLY": true, "YEARLY": true,
+}
+
+// ValidateRecurrence accepts "" or an RRULE value ("FREQ=WEEKLY;BYDAY=MO",
+// optionally prefixed "RRULE:") and returns it upper-cased without the
+// prefix. Only the shape and FREQ are checked; the other parts are kept as
+// given for calendar apps to interpret.
+func ValidateRecurrence(r string) (string, error) {
+	r = strings.ToUpper(strings.TrimSpace(r))
+	r = strings.TrimPrefix(r, "RRULE:")
+	if r == "" {
+		return "", nil
+	}
+	freq := ""
+	for _, part := range strings.Split(r, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || key == "" || val == "" || strings.ContainsAny(part, " \t\r\n") {
+			return "", invalidf("invalid recurrence %q (want an RRULE such as FREQ=WEEKLY)", r)
+		}
+		if key == "FREQ" {
+			freq = val
+		}
+	}
+	if !recurrenceFreqs[freq] {
+		return "", invalidf("invalid recurrence %q: FREQ must be one of DAILY, WEEKLY, MONTHLY, YEARLY, ...", r)
+	}
+	return r, nil
+}
+
+// DueDate returns the calendar date of t as a Due value (midnight UTC).
+func DueDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// setStatus changes the item's status, stamping CompletedAt on completion and
+// clearing it on reopening.
+func (it *Item) setStatus(s Status, now time.Time) {
+	switch {
+	case s == StatusCompleted && it.Status != StatusCompleted:
+		it.CompletedAt = now
+	case s != StatusCompleted:
+		it.CompletedAt = time.Time{}
+	}
+	it.Status = s
 }
 
 // getNextID returns the next max(ID)+1 for the given list.
@@ -62,20 +201,21 @@ func getNextID(list []Item) int {
 
 // Add creates a new item and returns the updated slice plus the created item.
 // It follows the mutation pattern used by the other functions (take a slice, return a slice).
-func Add(list []Item, desc string, status Status) ([]Item, Item, error) {
+func Add(list []Item, desc string, status Status, opts ...Option) ([]Item, Item, error) {
 	desc = strings.TrimSpace(desc)
-	if desc == "" {
-		return list, Item{}, errors.New("description cannot be empty")
+	if err := newChecks(opts).checkDescription(desc); err != nil {
+		return list, Item{}, err
 	}
 	if err := status.Validate(); err != nil {
 		return list, Item{}, err
 	}
+	now := time.Now()
 	item := Item{
 		ID:          getNextID(list),
 		Description: desc,
-		Status:      Status(strings.ToLower(string(status))),
-		CreatedAt:   time.Now(),
+		CreatedAt:   now,
 	}
+	item.setStatus(Status(strings.ToLower(string(status))), now)
 	list = append(list, item)
 	return list, item, nil
 }
@@ -88,19 +228,22 @@ func UpdateStatus(list []Item, id int, s Status) ([]Item, error) {
 	}
 	for i := range list {
 		if list[i].ID == id {
-			list[i].Status = Status(strings.ToLower(string(s)))
+			list[i].setStatus(Status(strings.ToLower(string(s))), time.Now())
 			return list, nil
 		}
 	}
-	return list, fmt.Errorf("no to-do with id %d", id)
+	return list, notFoundError(id)
 }
 
 // UpdateDescription finds an item by id and replaces its Description.
 // Returns a new slice (copy-on-write style) to make the mutation explicit.
-func UpdateDescription(list []Item, id int, newDesc string) ([]Item, error) {
+func UpdateDescription(list []Item, id int, newDesc string, opts ...Option) ([]Item, error) {
 	newDesc = strings.TrimSpace(newDesc)
 	if newDesc == "" {
-		return list, errors.New("new description cannot be empty")
+		return list, invalidf("new description cannot be empty")
+	}
+	if err := newChecks(opts).checkDescription(newDesc); err != nil {
+		return list, err
 	}
 	for i := range list {
 		if list[i].ID == id {
@@ -108,7 +251,7 @@ func UpdateDescription(list []Item, id int, newDesc string) ([]Item, error) {
 			return list, nil
 		}
 	}
-	return list, fmt.Errorf("no to-do with id %d", id)
+	return list, notFoundError(id)
 }
 
 // Delete removes an item by id. If the id does not exist, returns an error.
@@ -119,5 +262,5 @@ func Delete(list []Item, id int) ([]Item, error) {
 			return append(list[:i], list[i+1:]...), nil
 		}
 	}
-	return list, fmt.Errorf("no to-do with id %d", id)
+	return list, notFoundError(id)
 }
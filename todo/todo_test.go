@@ -1,6 +1,10 @@
 package todo
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+)
 
 // Status.Validate cases
 // TestTodo_StatusValidate ensures that Status.Validate correctly
@@ -373,3 +377,102 @@ func TestTodo_Delete_missing_id_error(t *testing.T) {
 		t.Fatalf("len=%d want %d", len(out), tc.wantLen)
 	}
 }
+
+// TestTodo_MaxDescriptionLen checks that Add, UpdateDescription, ApplyBatch
+// and Import enforce the limit in runes, the default one without options,
+// and that a non-positive MaxDescription disables it.
+func TestTodo_MaxDescriptionLen(t *testing.T) {
+	five := MaxDescription(5)
+	list, _, err := Add(nil, "ééééé", StatusNotStarted, five) // 5 runes, 10 bytes
+	if err != nil {
+		t.Fatalf("Add at the limit: %v", err)
+	}
+	if _, _, err := Add(list, "toolong", StatusNotStarted, five); !errors.Is(err, ErrDescriptionTooLong) {
+		t.Fatalf("Add over the limit err=%v, want ErrDescriptionTooLong", err)
+	}
+	if _, results, _ := ApplyBatch(list, []Op{{Op: OpCreate, Description: "toolong"}}, false, five); !errors.Is(results[0].Err, ErrDescriptionTooLong) {
+		t.Fatalf("ApplyBatch over the limit: %+v", results)
+	}
+	if _, _, err := Import(list, []Item{{Description: "toolong"}}, "", five); !errors.Is(err, ErrDescriptionTooLong) {
+		t.Fatalf("Import over the limit err=%v", err)
+	}
+	if _, _, err := Add(list, strings.Repeat("x", MaxDescriptionLen+1), StatusNotStarted); !errors.Is(err, ErrDescriptionTooLong) {
+		t.Fatalf("Add over the default limit err=%v", err)
+	}
+	if _, err := UpdateDescription(list, 1, "toolong", five); !errors.Is(err, ErrDescriptionTooLong) {
+		t.Fatalf("UpdateDescription over the limit err=%v, want ErrDescriptionTooLong", err)
+	}
+	if list[0].Description != "ééééé" {
+		t.Fatalf("rejected update changed the item: %+v", list[0])
+	}
+
+	if _, _, err := Add(list, strings.Repeat("x", 5000), StatusNotStarted, MaxDescription(0)); err != nil {
+		t.Fatalf("limit disabled but Add failed: %v", err)
+	}
+}
+
+// TestTodo_ErrInvalid checks that validation errors match ErrInvalid while
+// keeping their messages and sentinels, and that a missing id does not.
+func TestTodo_ErrInvalid(t *testing.T) {
+	list, _, err := Add(nil, "ok", StatusNotStarted)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	_, _, errEmpty := Add(list, " ", StatusNotStarted)
+	_, _, errLong := Add(list, "toolong", StatusNotStarted, MaxDescription(5))
+	_, _, errName := CreateProject(DefaultProjects(), AllLists, "")
+	for name, err := range map[string]error{
+		"empty description": errEmpty,
+		"too long":          errLong,
+		"bad status":        Status("done").Validate(),
+		"bad list name":     errName,
+		"unknown list":      DefaultProjects().CheckWritable("nope"),
+	} {
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: err=%v, want ErrInvalid", name, err)
+		}
+	}
+	if !errors.Is(errLong, ErrDescriptionTooLong) || !strings.HasPrefix(errLong.Error(), "description too long: 7 characters") {
+		t.Fatalf("too long: err=%v", errLong)
+	}
+	if _, err := Delete(list, 99); errors.Is(err, ErrInvalid) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing id: err=%v", err)
+	}
+}
+
+// TestTodo_CompletedAt checks completion is stamped once and cleared when an
+// item is reopened, and that priorities are validated.
+func TestTodo_CompletedAt(t *testing.T) {
+	list, done, err := Add(nil, "done already", StatusCompleted)
+	if err != nil || done.CompletedAt.IsZero() {
+		t.Fatalf("Add completed: %+v, %v", done, err)
+	}
+	list, _, _ = Add(list, "open", StatusNotStarted)
+	if !list[1].CompletedAt.IsZero() {
+		t.Fatalf("open item has CompletedAt: %+v", list[1])
+	}
+	list, _ = UpdateStatus(list, 2, StatusCompleted)
+	stamp := list[1].CompletedAt
+	if stamp.IsZero() {
+		t.Fatal("completing did not set CompletedAt")
+	}
+	list, _ = UpdateStatus(list, 2, StatusCompleted)
+	if !list[1].CompletedAt.Equal(stamp) {
+		t.Fatal("completing again moved CompletedAt")
+	}
+	list, _ = UpdateStatus(list, 2, StatusStarted)
+	if !list[1].CompletedAt.IsZero() {
+		t.Fatal("reopening kept CompletedAt")
+	}
+
+	for in, want := range map[string]string{"": "", "a": "A", " Z ": "Z"} {
+		if got, err := ValidatePriority(in); err != nil || got != want {
+			t.Errorf("ValidatePriority(%q) = %q, %v", in, got, err)
+		}
+	}
+	for _, bad := range []string{"AA", "1", "é"} {
+		if _, err := ValidatePriority(bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ValidatePriority(%q) err = %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package todo
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// todo/todoist.go (package todo)
+// ------------------------------
+// Todoist project exports (project menu > Export as a template > CSV).
+// Rows of TYPE "task" become items; sections, notes and blank rows are
+// skipped and sub-tasks are flattened. The export has no task ids, so a
+// task's ref is its section and content (with a count for repeats), and
+// completed tasks are not in it. PRIORITY 1 (p1, the highest) to 3 become A
+// to C; 4 is none. DATE is free text: plain dates become the due date,
+// simple "every ..." rules the recurrence, and anything else is kept in
+// the description so it is not lost. @labels are already in CONTENT.
+//
+
+// TodoistSource reads Todoist CSV exports.
+type TodoistSource struct{}
+
+func (TodoistSource) Name() string { return "todoist" }
+
+// todoistPriorities maps the PRIORITY column to priorities.
+var todoistPriorities = map[string]string{"1": "A", "2": "B", "3": "C", "4": "", "": ""}
+
+// todoistDateLayouts are the DATE formats read as a due date.
+var todoistDateLayouts = []string{
+	time.DateOnly, time.RFC3339, "2006-01-02 15:04", "Jan 2 2006", "Jan 2, 2006",
+	"January 2 2006", "January 2, 2006", "2 Jan 2006", "2 January 2006",
+}
+
+// todoistRecurrences maps "every <unit>" to RRULE values.
+var todoistRecurrences = map[string]string{
+	"day": "FREQ=DAILY", "weekday": "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+	"week": "FREQ=WEEKLY", "month": "FREQ=MONTHLY", "year": "FREQ=YEARLY",
+	"monday": "FREQ=WEEKLY;BYDAY=MO", "tuesday": "FREQ=WEEKLY;BYDAY=TU",
+	"wednesday": "FREQ=WEEKLY;BYDAY=WE", "thursday": "FREQ=WEEKLY;BYDAY=TH",
+	"friday": "FREQ=WEEKLY;BYDAY=FR", "saturday": "FREQ=WEEKLY;BYDAY=SA",
+	"sunday": "FREQ=WEEKLY;BYDAY=SU",
+}
+
+func (s TodoistSource) Decode(r io.Reader) ([]ExternalRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return []ExternalRecord{}, nil
+	}
+	if err != nil {
+		return nil, invalidf("todoist: %w", err)
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, name := range []string{"TYPE", "CONTENT"} {
+		if _, ok := col[name]; !ok {
+			return nil, invalidf("todoist: no %s column in the header (is this a Todoist CSV export?)", name)
+		}
+	}
+
+	records := []ExternalRecord{}
+	seen := map[string]int{}
+	section := ""
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, invalidf("todoist: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		content := field("CONTENT")
+		switch strings.ToLower(field("TYPE")) {
+		case "section":
+			section = content
+			continue
+		case "task":
+		default:
+			continue
+		}
+
+		priority, ok := todoistPriorities[field("PRIORITY")]
+		if !ok {
+			return nil, invalidf("todoist: line %d: invalid priority %q (want 1-4)", line, field("PRIORITY"))
+		}
+		it := Item{Description: content, Priority: priority}
+		if date := field("DATE"); date != "" {
+			it.Due, it.Recurrence = todoistDate(date)
+			if it.Due.IsZero() && it.Recurrence == "" {
+				it.Description += " (date: " + date + ")"
+			}
+		}
+
+		ref := section + "/" + content
+		if seen[ref]++; seen[ref] > 1 {
+			ref += "#" + strconv.Itoa(seen[ref])
+		}
+		records = append(records, ExternalRecord{Ref: ref, Item: it})
+	}
+}
+
+// todoistDate reads a DATE value as a due date or a recurrence; neither is
+// set for text it does not understand ("tomorrow", "every 3rd friday").
+func todoistDate(s string) (time.Time, string) {
+	for _, layout := range todoistDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return DueDate(t), ""
+		}
+	}
+	unit, ok := strings.CutPrefix(strings.ToLower(s), "every ")
+	if !ok {
+		unit = ""
+		switch strings.ToLower(s) {
+		case "daily":
+			unit = "day"
+		case "weekly":
+			unit = "week"
+		case "monthly":
+			unit = "month"
+		case "yearly":
+			unit = "year"
+		}
+	}
+	return time.Time{}, todoistRecurrences[strings.TrimSpace(unit)]
+}
+
+func init() {
+	RegisterSource(TodoistSource{})
+}
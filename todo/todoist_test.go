@@ -0,0 +1,59 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const todoistSample = "\ufeffTYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+	"task,Pay rent @home,,1,1,Ann (1),,2024-06-01,en,Europe/Berlin\n" +
+	"note,Bank details in the drive,,,,Ann (1),,,,\n" +
+	",,,,,,,,,\n" +
+	"section,Errands,,,,,,,,\n" +
+	"task,Water plants,,4,1,Ann (1),,every week,en,Europe/Berlin\n" +
+	"task,Buy soil,,3,2,Ann (1),,tomorrow,en,Europe/Berlin\n" +
+	"task,Water plants,,2,1,Ann (1),,,en,Europe/Berlin\n"
+
+// TestTodo_Todoist_Decode reads a CSV export: tasks only, priorities,
+// dates, recurrences, and refs that tell repeated tasks apart.
+func TestTodo_Todoist_Decode(t *testing.T) {
+	recs, err := TodoistSource{}.Decode(strings.NewReader(todoistSample))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(recs) != 4 {
+		t.Fatalf("got %d records: %+v", len(recs), recs)
+	}
+	rent := recs[0].Item
+	if recs[0].Ref != "/Pay rent @home" || rent.Priority != "A" || !rent.Due.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Pay rent: %+v", recs[0])
+	}
+	if it := recs[1].Item; recs[1].Ref != "Errands/Water plants" || it.Priority != "" || it.Recurrence != "FREQ=WEEKLY" || !it.Due.IsZero() {
+		t.Fatalf("Water plants: %+v", recs[1])
+	}
+	if it := recs[2].Item; it.Description != "Buy soil (date: tomorrow)" || it.Priority != "C" {
+		t.Fatalf("Buy soil: %+v", it)
+	}
+	if recs[3].Ref != "Errands/Water plants#2" || recs[3].Item.Priority != "B" {
+		t.Fatalf("repeated task: %+v", recs[3])
+	}
+
+	for _, date := range []string{"monday", "every 3rd friday"} {
+		if due, rule := todoistDate(date); !due.IsZero() || rule != "" {
+			t.Fatalf("todoistDate(%q) = %v, %q", date, due, rule)
+		}
+	}
+	if _, rule := todoistDate("Every Monday"); rule != "FREQ=WEEKLY;BYDAY=MO" {
+		t.Fatalf("every monday: %q", rule)
+	}
+
+	if _, err := (TodoistSource{}).Decode(strings.NewReader("id,description\n1,x\n")); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("not a Todoist export: %v", err)
+	}
+	_, err = TodoistSource{}.Decode(strings.NewReader("TYPE,CONTENT,PRIORITY\ntask,a,1\ntask,b,7\n"))
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("bad priority: %v", err)
+	}
+}
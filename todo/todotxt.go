@@ -0,0 +1,293 @@
+package todo
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//
+// todo/todotxt.go (package todo)
+// ------------------------------
+// The todo.txt format (https://github.com/todotxt/todo.txt): one item per
+// line, "x" and a completion date for done items, "(A)" for priority, a
+// creation date, then the description with its +project and @context tags.
+// Fields todo.txt has no syntax for use its key:value extension: due:DATE
+// is common to most tools, and status:started, pri:A (on completed items,
+// where "(A)" is not allowed) and list:NAME keep the rest. Dates are whole
+// days.
+//
+//	(A) 2024-05-01 Call the plumber +house @phone due:2024-05-03
+//	x 2024-05-04 2024-05-01 Buy milk @shop list:home
+//
+
+// Contexts returns the item's @context tags, without the "@".
+func (it Item) Contexts() []string { return tags(it.Description, '@') }
+
+// Projects returns the item's todo.txt +project tags, without the "+".
+// They are part of the description and unrelated to named lists.
+func (it Item) Projects() []string { return tags(it.Description, '+') }
+
+func tags(desc string, mark byte) []string {
+	var out []string
+	for _, w := range strings.Fields(desc) {
+		if len(w) > 1 && w[0] == mark {
+			out = append(out, w[1:])
+		}
+	}
+	return out
+}
+
+// TodoTxtCodec is the todo.txt codec.
+type TodoTxtCodec struct{}
+
+func (TodoTxtCodec) Name() string        { return "todotxt" }
+func (TodoTxtCodec) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (TodoTxtCodec) Encode(w io.Writer, items []Item) error {
+	bw := bufio.NewWriter(w)
+	for _, it := range items {
+		bw.WriteString(FormatTodoTxt(it))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+func (TodoTxtCodec) Decode(r io.Reader) ([]Item, error) {
+	items := []Item{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		it, err := ParseTodoTxt(line)
+		if err != nil {
+			return nil, invalidf("todotxt: line %d: %w", n, err)
+		}
+		items = append(items, it)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FormatTodoTxt writes it as one todo.txt line.
+func FormatTodoTxt(it Item) string {
+	var parts []string
+	if it.Status == StatusCompleted {
+		done := it.CompletedAt
+		if done.IsZero() {
+			done = it.CreatedAt // todo.txt needs a completion date before the creation date
+		}
+		parts = append(parts, "x")
+		if !done.IsZero() {
+			parts = append(parts, formatDate(done))
+		}
+	} else if it.Priority != "" {
+		parts = append(parts, "("+it.Priority+")")
+	}
+	if !it.CreatedAt.IsZero() {
+		parts = append(parts, formatDate(it.CreatedAt))
+	}
+	parts = append(parts, escapeDesc(it.Description, true, todoTxtLead))
+	parts = append(parts, metaTokens(it, true)...)
+	return strings.Join(parts, " ")
+}
+
+// metaTokens are the key:value tokens for the fields a line has no other
+// syntax for. full adds the ones only todo.txt needs (status, list, and the
+// priority of completed items).
+func metaTokens(it Item, full bool) []string {
+	var out []string
+	if full && it.Status == StatusCompleted && it.Priority != "" {
+		out = append(out, "pri:"+it.Priority)
+	}
+	if !it.Due.IsZero() {
+		out = append(out, "due:"+formatDate(it.Due))
+	}
+	if full && it.Status == StatusStarted {
+		out = append(out, "status:started")
+	}
+	if full && it.List != "" && it.List != DefaultList {
+		out = append(out, "list:"+url.PathEscape(it.List))
+	}
+	return out
+}
+
+// ParseTodoTxt reads one todo.txt line.
+func ParseTodoTxt(line string) (Item, error) {
+	it := Item{Status: StatusNotStarted}
+	words := strings.Fields(line)
+	if len(words) > 0 && words[0] == "x" {
+		it.Status = StatusCompleted
+		words = words[1:]
+		if d, ok := leadingDate(&words); ok {
+			it.CompletedAt = d
+		}
+	} else if len(words) > 0 && isPriority(words[0]) {
+		it.Priority = words[0][1:2]
+		words = words[1:]
+	}
+	if d, ok := leadingDate(&words); ok {
+		it.CreatedAt = d
+	}
+	it.Description = strings.Join(takeMeta(&it, words, true), " ")
+	if it.Description == "" {
+		return Item{}, invalidf("no description")
+	}
+	return it, nil
+}
+
+// takeMeta moves the key:value tokens metaTokens writes from the end of
+// words into it and returns the description's words, unescaped. Only the
+// trailing run is read, where tags and unknown key:value extensions may sit
+// between them; a token whose value does not parse, or that repeats a key,
+// ends the run and stays in the description like every word before it.
+func takeMeta(it *Item, words []string, full bool) []string {
+	taken := map[string]bool{}
+	drop := map[int]bool{}
+	for i := len(words) - 1; i >= 0; i-- {
+		key, val, kind := metaWord(words[i], full)
+		if kind == wordText {
+			break
+		}
+		if kind == wordOther {
+			continue
+		}
+		if taken[key] || !setMeta(it, key, val, full) {
+			break
+		}
+		taken[key], drop[i] = true, true
+	}
+	rest := make([]string, 0, len(words))
+	for i, w := range words {
+		if !drop[i] {
+			rest = append(rest, strings.TrimPrefix(w, `\`))
+		}
+	}
+	return rest
+}
+
+// Kinds of word at the end of a line.
+const (
+	wordText  = iota // ends the trailing run
+	wordOther        // a tag or an unknown key:value extension
+	wordMeta         // a key takeMeta reads
+)
+
+// metaWord classifies w for takeMeta and escapeDesc.
+func metaWord(w string, full bool) (key, val string, kind int) {
+	if len(w) > 1 && (w[0] == '@' || w[0] == '+') {
+		return "", "", wordOther
+	}
+	key, val, ok := strings.Cut(w, ":")
+	if !ok || key == "" || val == "" || strings.HasPrefix(w, `\`) {
+		return "", "", wordText
+	}
+	switch key {
+	case "due":
+		return key, val, wordMeta
+	case "pri", "status", "list":
+		if full {
+			return key, val, wordMeta
+		}
+	}
+	return "", "", wordOther
+}
+
+// setMeta sets the field for key from val, reporting whether val parsed.
+func setMeta(it *Item, key, val string, full bool) bool {
+	switch key {
+	case "due":
+		d, err := time.Parse(time.DateOnly, val)
+		if err != nil {
+			return false
+		}
+		it.Due = d
+	case "pri":
+		p, err := ValidatePriority(val)
+		if err != nil || p == "" {
+			return false
+		}
+		it.Priority = p
+	case "status":
+		st := Status(strings.ReplaceAll(strings.ToLower(val), "_", " "))
+		if it.Status == StatusCompleted || st.Validate() != nil {
+			return false
+		}
+		it.Status = st
+	case "list":
+		name, err := url.PathUnescape(val)
+		if err != nil {
+			return false
+		}
+		it.List = name
+	}
+	return true
+}
+
+// escapeDesc writes desc so takeMeta reads it back unchanged: the first word
+// (when lead says a parser would take it as syntax) and the last meta token
+// of the trailing run get a backslash, as does every word already starting
+// with one.
+func escapeDesc(desc string, full bool, lead func(string) bool) string {
+	words := strings.Fields(desc)
+	for i, w := range words {
+		if strings.HasPrefix(w, `\`) {
+			words[i] = `\` + w
+		}
+	}
+	for i := len(words) - 1; i >= 0; i-- {
+		_, _, kind := metaWord(words[i], full)
+		if kind == wordText {
+			break
+		}
+		if kind == wordMeta {
+			words[i] = `\` + words[i]
+			break
+		}
+	}
+	if len(words) > 0 && lead(words[0]) {
+		words[0] = `\` + words[0]
+	}
+	return strings.Join(words, " ")
+}
+
+// todoTxtLead reports whether w at the start of a description could be
+// read as a todo.txt completion mark, priority or date.
+func todoTxtLead(w string) bool {
+	_, err := time.Parse(time.DateOnly, w)
+	return w == "x" || isPriority(w) || err == nil
+}
+
+// leadingDate removes a YYYY-MM-DD date from the front of words.
+func leadingDate(words *[]string) (time.Time, bool) {
+	if len(*words) == 0 {
+		return time.Time{}, false
+	}
+	d, err := time.Parse(time.DateOnly, (*words)[0])
+	if err != nil {
+		return time.Time{}, false
+	}
+	*words = (*words)[1:]
+	return d, true
+}
+
+// isPriority reports whether w is "(A)" .. "(Z)".
+func isPriority(w string) bool {
+	return len(w) == 3 && w[0] == '(' && w[2] == ')' && w[1] >= 'A' && w[1] <= 'Z'
+}
+
+// oneLine folds line breaks into spaces: every format here is line based.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func init() {
+	RegisterCodec(TodoTxtCodec{}, ".txt")
+}
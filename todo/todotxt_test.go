@@ -0,0 +1,98 @@
+package todo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+// TestTodo_TodoTxt_Parse reads lines written by other todo.txt tools.
+func TestTodo_TodoTxt_Parse(t *testing.T) {
+	cases := []struct {
+		line string
+		want Item
+	}{
+		{"(A) Thank Mom for the meatballs @phone", Item{Description: "Thank Mom for the meatballs @phone", Status: StatusNotStarted, Priority: "A"}},
+		{"(B) 2024-05-01 Schedule checkup +Health due:2024-06-01 rec:1y",
+			Item{Description: "Schedule checkup +Health rec:1y", Status: StatusNotStarted, Priority: "B", CreatedAt: day(2024, 5, 1), Due: day(2024, 6, 1)}},
+		{"x 2024-05-04 2024-05-01 Buy milk pri:C list:my%20home",
+			Item{Description: "Buy milk", Status: StatusCompleted, Priority: "C", CreatedAt: day(2024, 5, 1), CompletedAt: day(2024, 5, 4), List: "my home"}},
+		{"x 2024-05-04 Done on the day", Item{Description: "Done on the day", Status: StatusCompleted, CompletedAt: day(2024, 5, 4)}},
+		{"Write report status:started http://example.com", Item{Description: "Write report http://example.com", Status: StatusStarted}},
+		{"(a) lowercase is not a priority", Item{Description: "(a) lowercase is not a priority", Status: StatusNotStarted}},
+		// Only the tokens at the end are metadata, and only when they parse.
+		{"Fix due:tomorrow", Item{Description: "Fix due:tomorrow", Status: StatusNotStarted}},
+		{"x pri:AA Something", Item{Description: "pri:AA Something", Status: StatusCompleted}},
+		{"see list:foo first due:2024-06-01", Item{Description: "see list:foo first", Status: StatusNotStarted, Due: day(2024, 6, 1)}},
+		{"Ship due:soon @work", Item{Description: "Ship due:soon @work", Status: StatusNotStarted}},
+		{`Ship \due:2024-06-01`, Item{Description: "Ship due:2024-06-01", Status: StatusNotStarted}},
+	}
+	for _, c := range cases {
+		got, err := ParseTodoTxt(c.line)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ParseTodoTxt(%q) = %+v, %v\nwant %+v", c.line, got, err, c.want)
+		}
+	}
+	for _, bad := range []string{"2024-05-01", "x 2024-05-04", "(A) due:2024-06-01"} {
+		if _, err := ParseTodoTxt(bad); err == nil {
+			t.Errorf("ParseTodoTxt(%q) did not fail", bad)
+		}
+	}
+}
+
+// TestTodo_TodoTxt_Format checks the lines written, including a completed
+// item without a completion date and tags.
+func TestTodo_TodoTxt_Format(t *testing.T) {
+	created := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	got := FormatTodoTxt(Item{Description: "Buy milk\n@shop +errands", Status: StatusCompleted, CreatedAt: created, Priority: "A", List: "home"})
+	if want := "x 2024-05-01 2024-05-01 Buy milk @shop +errands pri:A list:home"; got != want {
+		t.Fatalf("FormatTodoTxt = %q, want %q", got, want)
+	}
+	it := Item{Description: "Call +house @phone @home", Status: StatusStarted, Priority: "B"}
+	if got := FormatTodoTxt(it); got != "(B) Call +house @phone @home status:started" {
+		t.Fatalf("FormatTodoTxt = %q", got)
+	}
+	if c, p := it.Contexts(), it.Projects(); strings.Join(c, ",") != "phone,home" || strings.Join(p, ",") != "house" {
+		t.Fatalf("Contexts = %q, Projects = %q", c, p)
+	}
+}
+
+// TestTodo_TodoTxt_RoundTrip writes descriptions that look like todo.txt
+// syntax and reads them back unchanged, in both formats.
+func TestTodo_TodoTxt_RoundTrip(t *testing.T) {
+	descs := []string{
+		"Ship due:soon",
+		"see list:foo",
+		"ends with due:2024-06-01",
+		"due:2024-06-01 @home +house",
+		"set status:started http://example.com",
+		"pri:A first",
+		"x marks the spot",
+		"2024-05-01 retro notes",
+		"(A) is not a priority",
+		`\path\to and \\share`,
+		"x",
+	}
+	for _, d := range descs {
+		for _, it := range []Item{
+			{Description: d, Status: StatusNotStarted},
+			{Description: d, Status: StatusStarted, Priority: "B", Due: day(2024, 7, 1), List: "work"},
+			{Description: d, Status: StatusCompleted, CreatedAt: day(2024, 5, 1), CompletedAt: day(2024, 5, 2)},
+		} {
+			line := FormatTodoTxt(it)
+			got, err := ParseTodoTxt(line)
+			if err != nil || !reflect.DeepEqual(got, it) {
+				t.Errorf("todo.txt %q -> %+v, %v\nwant %+v", line, got, err, it)
+			}
+			it.List, it.CreatedAt, it.CompletedAt = "", time.Time{}, time.Time{}
+			line = FormatChecklistLine(it)
+			got, ok, err := ParseChecklistLine(line)
+			if err != nil || !ok || !reflect.DeepEqual(got, it) {
+				t.Errorf("checklist %q -> %+v, %v\nwant %+v", line, got, err, it)
+			}
+		}
+	}
+}
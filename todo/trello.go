@@ -0,0 +1,113 @@
+package todo
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//
+// todo/trello.go (package todo)
+// -----------------------------
+// Trello board exports (Board menu > Print, export and share > Export as
+// JSON). Each open card on an open list becomes an item; archived cards and
+// lists are left out. The status comes from the card's due-date checkbox,
+// else from its list's name ("Done" -> completed, "Doing" -> started). Label
+// names become a priority or @context tags (see labelTags).
+//
+
+// TrelloSource reads Trello board exports.
+type TrelloSource struct{}
+
+func (TrelloSource) Name() string { return "trello" }
+
+// trelloBoard is the part of a board export the importer uses.
+type trelloBoard struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Closed      bool   `json:"closed"`
+		IDList      string `json:"idList"`
+		Due         string `json:"due"`
+		DueComplete bool   `json:"dueComplete"`
+		Labels      []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	} `json:"cards"`
+}
+
+// trelloListStatus maps list names to statuses; other lists are not
+// started.
+var trelloListStatus = map[string]Status{
+	"done": StatusCompleted, "complete": StatusCompleted, "completed": StatusCompleted,
+	"finished": StatusCompleted, "shipped": StatusCompleted,
+	"doing": StatusStarted, "in-progress": StatusStarted, "started": StatusStarted,
+	"wip": StatusStarted, "in-review": StatusStarted, "review": StatusStarted,
+}
+
+func (s TrelloSource) Decode(r io.Reader) ([]ExternalRecord, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, invalidf("trello: %w", err)
+	}
+	lists := map[string]Status{}
+	closed := map[string]bool{}
+	for _, l := range board.Lists {
+		lists[l.ID] = trelloListStatus[slug(l.Name)]
+		closed[l.ID] = l.Closed
+	}
+
+	records := []ExternalRecord{}
+	for _, c := range board.Cards {
+		if c.Closed || closed[c.IDList] {
+			continue
+		}
+		labels := make([]string, len(c.Labels))
+		for i, l := range c.Labels {
+			labels[i] = l.Name
+		}
+		priority, tags := labelTags(labels)
+		it := Item{
+			Description: withTags(strings.TrimSpace(c.Name), tags),
+			Status:      lists[c.IDList],
+			Priority:    priority,
+			CreatedAt:   trelloCreated(c.ID),
+		}
+		if c.DueComplete {
+			it.Status = StatusCompleted
+		}
+		if c.Due != "" {
+			due, err := time.Parse(time.RFC3339Nano, c.Due)
+			if err != nil {
+				return nil, invalidf("trello: card %s: invalid due date %q", c.ID, c.Due)
+			}
+			it.Due = DueDate(due)
+		}
+		records = append(records, ExternalRecord{Ref: c.ID, Item: it})
+	}
+	return records, nil
+}
+
+// trelloCreated reads the creation time from a card id, whose first eight
+// hex digits are a Unix time; an id that is not one gives the zero time.
+func trelloCreated(id string) time.Time {
+	if len(id) < 8 {
+		return time.Time{}
+	}
+	secs, err := strconv.ParseInt(id[:8], 16, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0).UTC()
+}
+
+func init() {
+	RegisterSource(TrelloSource{})
+}
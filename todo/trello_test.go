@@ -0,0 +1,60 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const trelloSample = `{
+  "id": "65f0", "name": "Launch",
+  "lists": [
+    {"id": "l1", "name": "To Do", "closed": false},
+    {"id": "l2", "name": "In Progress", "closed": false},
+    {"id": "l3", "name": "Done", "closed": false},
+    {"id": "l4", "name": "Old ideas", "closed": true}
+  ],
+  "cards": [
+    {"id": "65f1a2b3c4d5e6f708091a2b", "name": "Write the press release", "idList": "l1",
+     "due": "2024-06-01T16:00:00.000Z", "dueComplete": false,
+     "labels": [{"name": "marketing", "color": "green"}, {"name": "Urgent", "color": "red"}, {"name": "", "color": "blue"}]},
+    {"id": "65f1a2b3c4d5e6f708091a2c", "name": "Record the demo", "idList": "l2", "due": null, "labels": []},
+    {"id": "65f1a2b3c4d5e6f708091a2d", "name": "Book the venue", "idList": "l3", "labels": []},
+    {"id": "65f1a2b3c4d5e6f708091a2e", "name": "Order shirts", "idList": "l1", "due": "2024-05-20T12:00:00.000Z", "dueComplete": true},
+    {"id": "65f1a2b3c4d5e6f708091a2f", "name": "Archived card", "idList": "l1", "closed": true},
+    {"id": "65f1a2b3c4d5e6f708091a30", "name": "On a closed list", "idList": "l4"}
+  ]
+}`
+
+// TestTodo_Trello_Decode reads a board export: statuses from lists and due
+// checkboxes, labels, due dates, creation times, archived cards left out.
+func TestTodo_Trello_Decode(t *testing.T) {
+	recs, err := TrelloSource{}.Decode(strings.NewReader(trelloSample))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(recs) != 4 {
+		t.Fatalf("got %d records: %+v", len(recs), recs)
+	}
+	first := recs[0]
+	if first.Ref != "65f1a2b3c4d5e6f708091a2b" || first.Item.Description != "Write the press release @marketing" ||
+		first.Item.Priority != "A" || first.Item.Status != "" || !first.Item.Due.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("first card: %+v", first)
+	}
+	if got := first.Item.CreatedAt; !got.Equal(time.Unix(0x65f1a2b3, 0)) {
+		t.Fatalf("created from the card id: %v", got)
+	}
+	for i, want := range []Status{"", StatusStarted, StatusCompleted, StatusCompleted} {
+		if recs[i].Item.Status != want {
+			t.Fatalf("card %d status %q, want %q", i, recs[i].Item.Status, want)
+		}
+	}
+
+	if _, err := (TrelloSource{}).Decode(strings.NewReader(`[1, 2]`)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("not a board: %v", err)
+	}
+	if _, err := (TrelloSource{}).Decode(strings.NewReader(`{"cards": [{"id": "x", "name": "a", "due": "soon"}]}`)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("bad due date: %v", err)
+	}
+}
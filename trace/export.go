@@ -0,0 +1,216 @@
+package trace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+//
+// trace/export.go (package trace)
+// -------------------------------
+// Span exporters. A single process-wide exporter (like slog's default logger)
+// receives every span when it ends. FileExporter writes OTLP/JSON lines that
+// tools understanding the OpenTelemetry file format can read directly.
+//
+
+// Exporter receives finished spans.
+type Exporter interface {
+	Export(s *Span) error
+	Close() error
+}
+
+var (
+	exporterMu sync.RWMutex
+	exporter   Exporter
+)
+
+// SetExporter installs e as the process-wide exporter and returns the previous
+// one (nil if none). Passing nil disables exporting.
+func SetExporter(e Exporter) Exporter {
+	exporterMu.Lock()
+	defer exporterMu.Unlock()
+	prev := exporter
+	exporter = e
+	return prev
+}
+
+// export forwards a finished span to the active exporter, if any.
+func export(s *Span) {
+	exporterMu.RLock()
+	e := exporter
+	exporterMu.RUnlock()
+	if e != nil {
+		_ = e.Export(s)
+	}
+}
+
+// FileExporter appends one OTLP/JSON "ExportTraceServiceRequest" per line.
+// It is safe for concurrent use.
+type FileExporter struct {
+	mu      sync.Mutex
+	f       *os.File
+	service string
+}
+
+// NewFileExporter opens (or creates) path for appending, creating the parent
+// directory if needed. service is reported as the resource's service.name.
+func NewFileExporter(path, service string) (*FileExporter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{f: f, service: service}, nil
+}
+
+// Export writes s as a single JSON line.
+func (e *FileExporter) Export(s *Span) error {
+	b, err := json.Marshal(otlpRequest(e.service, s))
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.f == nil {
+		return os.ErrClosed
+	}
+	_, err = e.f.Write(b)
+	return err
+}
+
+// Close flushes and closes the underlying file.
+func (e *FileExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.f == nil {
+		return nil
+	}
+	err := e.f.Close()
+	e.f = nil
+	return err
+}
+
+// --- OTLP/JSON wire shapes (only the fields we populate) ---
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"` // int64 is a string in OTLP/JSON
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpExport struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// otlpRequest wraps a single span in the OTLP resource/scope envelope.
+func otlpRequest(service string, s *Span) otlpExport {
+	code, msg := s.Status()
+	attrs := s.Attrs()
+	traceID := otlpTraceID(s.TraceID)
+	if traceID != s.TraceID {
+		// Keep the caller-supplied id searchable when it had to be rewritten.
+		attrs = append(attrs, Attr{Key: "todo.trace_id", Value: s.TraceID})
+	}
+
+	span := otlpSpan{
+		TraceID:           traceID,
+		SpanID:            s.SpanID,
+		ParentSpanID:      s.ParentID,
+		Name:              s.Name,
+		Kind:              1, // SPAN_KIND_INTERNAL
+		StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+		Status:            otlpStatus{Code: code, Message: msg},
+	}
+	for _, a := range attrs {
+		span.Attributes = append(span.Attributes, otlpKeyValue{Key: a.Key, Value: otlpValue(a.Value)})
+	}
+
+	var scope otlpScopeSpans
+	scope.Scope.Name = "todo-app/trace"
+	scope.Spans = []otlpSpan{span}
+
+	var rs otlpResourceSpans
+	rs.Resource.Attributes = []otlpKeyValue{{Key: "service.name", Value: otlpValue(service)}}
+	rs.ScopeSpans = []otlpScopeSpans{scope}
+	return otlpExport{ResourceSpans: []otlpResourceSpans{rs}}
+}
+
+// otlpValue converts a Go value into the OTLP AnyValue union.
+func otlpValue(v any) otlpAnyValue {
+	switch x := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &x}
+	case bool:
+		return otlpAnyValue{BoolValue: &x}
+	case int:
+		s := strconv.FormatInt(int64(x), 10)
+		return otlpAnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(x, 10)
+		return otlpAnyValue{IntValue: &s}
+	case float64:
+		return otlpAnyValue{DoubleValue: &x}
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+// otlpTraceID returns id unchanged when it is already 32 hex chars; otherwise
+// (e.g. a user-supplied -traceid) it derives a stable 16-byte id from it.
+func otlpTraceID(id string) string {
+	if len(id) == 32 {
+		if _, err := hex.DecodeString(id); err == nil {
+			return id
+		}
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,160 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+//
+// trace/span.go (package trace)
+// -----------------------------
+// Spans: timed units of work that belong to a trace. A span remembers its
+// parent (the span already in the context when it was started), carries a
+// small set of attributes, and is handed to the active Exporter on End().
+//
+
+// spanKeyType is an unexported type for the current-span context key.
+type spanKeyType struct{}
+
+// spanKey is the package-private context key for the active *Span.
+var spanKey spanKeyType
+
+// Status codes mirror the OTLP StatusCode enum.
+const (
+	StatusUnset = 0
+	StatusOK    = 1
+	StatusError = 2
+)
+
+// Attr is a single key/value attribute recorded on a span.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// Span records the timing and attributes of one operation.
+// Fields are only read by exporters after End(), so they are exported for
+// convenience; use the methods while the span is still running.
+type Span struct {
+	TraceID   string
+	SpanID    string
+	ParentID  string
+	Name      string
+	StartTime time.Time
+	EndTime   time.Time
+
+	mu            sync.Mutex
+	attrs         []Attr
+	statusCode    int
+	statusMessage string
+	ended         bool
+}
+
+// Start begins a new span named name as a child of the span in ctx (if any).
+// The trace id is taken from ctx; if ctx has none a new one is generated.
+// attrs are alternating key/value pairs, like slog.
+func Start(ctx context.Context, name string, attrs ...any) (context.Context, *Span) {
+	tid, ok := From(ctx)
+	if !ok {
+		ctx, tid = New(ctx)
+	}
+	s := &Span{
+		TraceID:   tid,
+		SpanID:    generateSpanID(),
+		Name:      name,
+		StartTime: time.Now(),
+	}
+	if parent, ok := SpanFrom(ctx); ok {
+		s.ParentID = parent.SpanID
+	}
+	s.SetAttrs(attrs...)
+	return context.WithValue(ctx, spanKey, s), s
+}
+
+// SpanFrom returns the span currently stored in ctx, if any.
+func SpanFrom(ctx context.Context) (*Span, bool) {
+	s, ok := ctx.Value(spanKey).(*Span)
+	return s, ok && s != nil
+}
+
+// SetAttr records (or overwrites) a single attribute on the span.
+func (s *Span) SetAttr(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.attrs {
+		if s.attrs[i].Key == key {
+			s.attrs[i].Value = value
+			return
+		}
+	}
+	s.attrs = append(s.attrs, Attr{Key: key, Value: value})
+}
+
+// SetAttrs records alternating key/value pairs. A trailing key without a
+// value, or a non-string key, is ignored.
+func (s *Span) SetAttrs(kv ...any) {
+	for i := 0; i+1 < len(kv); i += 2 {
+		if k, ok := kv[i].(string); ok {
+			s.SetAttr(k, kv[i+1])
+		}
+	}
+}
+
+// SetError marks the span as failed. A nil err is a no-op.
+func (s *Span) SetError(err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusCode = StatusError
+	s.statusMessage = err.Error()
+}
+
+// Attrs returns a copy of the span's attributes in insertion order.
+func (s *Span) Attrs() []Attr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Attr(nil), s.attrs...)
+}
+
+// Status returns the span's status code and message.
+func (s *Span) Status() (int, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.statusCode, s.statusMessage
+}
+
+// Duration is the time between Start and End (or now, if still running).
+func (s *Span) Duration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return s.EndTime.Sub(s.StartTime)
+	}
+	return time.Since(s.StartTime)
+}
+
+// End stops the span's clock and hands it to the active exporter.
+// Calling End more than once has no further effect.
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	export(s)
+}
+
+// generateSpanID returns a random 8-byte hex string (16 hex chars).
+func generateSpanID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,126 @@
+package trace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// collector is an in-memory Exporter used by tests.
+type collector struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (c *collector) Export(s *Span) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spans = append(c.spans, s)
+	return nil
+}
+func (c *collector) Close() error { return nil }
+
+// TestTrace_StartNestsUnderParent verifies that a child span inherits the
+// trace id and records its parent's span id.
+func TestTrace_StartNestsUnderParent(t *testing.T) {
+	c := &collector{}
+	prev := SetExporter(c)
+	t.Cleanup(func() { SetExporter(prev) })
+
+	ctx, tid := NewWithID(context.Background(), "0123456789abcdef0123456789abcdef")
+	ctx, parent := Start(ctx, "parent", "k", "v")
+	_, child := Start(ctx, "child")
+	child.End()
+	parent.End()
+
+	if parent.TraceID != tid || child.TraceID != tid {
+		t.Fatalf("trace ids = %q,%q want %q", parent.TraceID, child.TraceID, tid)
+	}
+	if parent.ParentID != "" {
+		t.Fatalf("root span has parent %q", parent.ParentID)
+	}
+	if child.ParentID != parent.SpanID {
+		t.Fatalf("child.ParentID=%q want %q", child.ParentID, parent.SpanID)
+	}
+	if len(c.spans) != 2 || c.spans[0] != child || c.spans[1] != parent {
+		t.Fatalf("exported %d spans in unexpected order", len(c.spans))
+	}
+	if attrs := parent.Attrs(); len(attrs) != 1 || attrs[0].Key != "k" || attrs[0].Value != "v" {
+		t.Fatalf("attrs=%+v", attrs)
+	}
+}
+
+// TestTrace_EndIsIdempotent checks a span is exported only once.
+func TestTrace_EndIsIdempotent(t *testing.T) {
+	c := &collector{}
+	prev := SetExporter(c)
+	t.Cleanup(func() { SetExporter(prev) })
+
+	_, s := Start(context.Background(), "once")
+	s.End()
+	s.End()
+	if len(c.spans) != 1 {
+		t.Fatalf("exported %d times, want 1", len(c.spans))
+	}
+}
+
+// TestTrace_FileExporterWritesOTLPLines verifies the JSON line shape produced
+// by FileExporter, including status and the rewritten non-hex trace id.
+func TestTrace_FileExporterWritesOTLPLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "spans.jsonl")
+	exp, err := NewFileExporter(path, "todo-test")
+	if err != nil {
+		t.Fatalf("NewFileExporter: %v", err)
+	}
+	prev := SetExporter(exp)
+	t.Cleanup(func() { SetExporter(prev) })
+
+	ctx, _ := NewWithID(context.Background(), "my-trace-id")
+	_, s := Start(ctx, "op", "count", 3)
+	s.SetError(errors.New("boom"))
+	s.End()
+	if err := exp.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	if !sc.Scan() {
+		t.Fatalf("no lines written")
+	}
+	var got otlpExport
+	if err := json.Unmarshal(sc.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v; line=%s", err, sc.Text())
+	}
+	sp := got.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if sp.Name != "op" || len(sp.TraceID) != 32 || len(sp.SpanID) != 16 {
+		t.Fatalf("unexpected span: %+v", sp)
+	}
+	if sp.Status.Code != StatusError || sp.Status.Message != "boom" {
+		t.Fatalf("status=%+v", sp.Status)
+	}
+	var sawCount, sawOrig bool
+	for _, kv := range sp.Attributes {
+		switch kv.Key {
+		case "count":
+			sawCount = kv.Value.IntValue != nil && *kv.Value.IntValue == "3"
+		case "todo.trace_id":
+			sawOrig = kv.Value.StringValue != nil && *kv.Value.StringValue == "my-trace-id"
+		}
+	}
+	if !sawCount || !sawOrig {
+		t.Fatalf("attributes=%+v", sp.Attributes)
+	}
+	if sc.Scan() {
+		t.Fatalf("expected exactly one line, got another: %s", sc.Text())
+	}
+}
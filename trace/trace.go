@@ -19,6 +19,27 @@ type keyType struct{}
 // key is the package-private context key for the trace id value.
 var key keyType
 
+// Header carries a trace id between processes: clients send it so the
+// server's spans join their trace, and the server echoes the id it used.
+const Header = "X-Trace-ID"
+
+// maxIDLen bounds trace ids accepted from other processes.
+const maxIDLen = 128
+
+// ValidID reports whether id is acceptable from another process: 1-128
+// visible ASCII characters (user-supplied -traceid values need not be hex).
+func ValidID(id string) bool {
+	if id == "" || len(id) > maxIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
 // New creates a new context with a generated TraceID and returns (ctx, id).
 func New(parent context.Context) (context.Context, string) {
 	id := GenerateID()
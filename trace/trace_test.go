@@ -56,3 +56,22 @@ func TestTrace_NewWithID(t *testing.T) {
 		t.Fatalf("From(ctx2)=%q ok=%v want %q", got2, ok, id2)
 	}
 }
+
+// TestTrace_ValidID accepts ids a CLI user might pass and rejects empty,
+// oversized or header-unsafe ones.
+func TestTrace_ValidID(t *testing.T) {
+	for _, id := range []string{GenerateID(), "release-42", "abc.DEF_9"} {
+		if !ValidID(id) {
+			t.Fatalf("ValidID(%q) = false", id)
+		}
+	}
+	long := make([]byte, maxIDLen+1)
+	for i := range long {
+		long[i] = 'a'
+	}
+	for _, id := range []string{"", "has space", "tab\there", "é", string(long)} {
+		if ValidID(id) {
+			t.Fatalf("ValidID(%q) = true", id)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"todo-app/todo"
+)
+
+//
+// tui/board.go (package tui)
+// --------------------------
+// The board model: items grouped into one column per status, the selection,
+// and the current mode (browsing, editing, filtering, help). Handle turns a
+// key into state changes and, for keys that change data, an Op that the run
+// loop applies to the store. The model never touches the store itself, so
+// it can be driven key by key in tests.
+//
+
+// columns are the board columns, left to right.
+var columns = []todo.Status{todo.StatusNotStarted, todo.StatusStarted, todo.StatusCompleted}
+
+// columnTitles are the headings for columns.
+var columnTitles = []string{"Not started", "Started", "Completed"}
+
+// mode is what keys currently do.
+type mode int
+
+const (
+	modeBrowse  mode = iota
+	modeEdit         // editing the selected item's description
+	modeAdd          // typing a new item's description
+	modeFilter       // typing the filter
+	modeHelp         // help overlay; any key closes it
+	modeConfirm      // y/n before deleting
+)
+
+// Op is a change to apply to the stored items, as for service.Update.
+type Op func([]todo.Item) ([]todo.Item, error)
+
+// Board is the state of the kanban view.
+type Board struct {
+	items  []todo.Item
+	col    int    // selected column
+	rows   [3]int // selected row in each column (index into the filtered column)
+	mode   mode
+	filter string
+	input  input
+	msg    string // one-line status shown in the footer
+	follow int    // item id to select after the next SetItems
+}
+
+// NewBoard returns an empty board.
+func NewBoard() *Board { return &Board{} }
+
+// SetItems replaces the items, keeping the selection on the same item when
+// it still exists.
+func (b *Board) SetItems(items []todo.Item) {
+	id := b.follow
+	if id == 0 {
+		if it, ok := b.Selected(); ok {
+			id = it.ID
+		}
+	}
+	b.follow = 0
+	b.items = items
+	if id != 0 && b.focus(id) {
+		return
+	}
+	b.clamp()
+}
+
+// Column returns the items shown in column c, after the filter.
+func (b *Board) Column(c int) []todo.Item {
+	var out []todo.Item
+	needle := strings.ToLower(b.filter)
+	for _, it := range b.items {
+		if it.Status != columns[c] {
+			continue
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(it.Description), needle) &&
+			!strings.HasPrefix("#"+strconv.Itoa(it.ID), needle) {
+			continue
+		}
+		out = append(out, it)
+	}
+	return out
+}
+
+// Selected returns the selected item, if its column is not empty.
+func (b *Board) Selected() (todo.Item, bool) {
+	col := b.Column(b.col)
+	if r := b.rows[b.col]; r < len(col) {
+		return col[r], true
+	}
+	return todo.Item{}, false
+}
+
+// focus selects the item with id, reporting whether it is visible.
+func (b *Board) focus(id int) bool {
+	for c := range columns {
+		for r, it := range b.Column(c) {
+			if it.ID == id {
+				b.col, b.rows[c] = c, r
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clamp keeps every column's selected row inside the column.
+func (b *Board) clamp() {
+	for c := range columns {
+		n := len(b.Column(c))
+		if b.rows[c] >= n {
+			b.rows[c] = n - 1
+		}
+		if b.rows[c] < 0 {
+			b.rows[c] = 0
+		}
+	}
+}
+
+// Handle applies k. It returns the change to store, if any, and whether the
+// user asked to quit.
+func (b *Board) Handle(k Key) (op Op, quit bool) {
+	if k.Code == KeyCtrlC {
+		return nil, true
+	}
+	switch b.mode {
+	case modeHelp:
+		b.mode = modeBrowse
+		return nil, false
+	case modeConfirm:
+		b.mode = modeBrowse
+		if k.Code == KeyRune && (k.Rune == 'y' || k.Rune == 'Y') {
+			if it, ok := b.Selected(); ok {
+				b.msg = fmt.Sprintf("deleted #%d", it.ID)
+				return func(list []todo.Item) ([]todo.Item, error) { return todo.Delete(list, it.ID) }, false
+			}
+		}
+		b.msg = ""
+		return nil, false
+	case modeEdit, modeAdd:
+		return b.handleInput(k), false
+	case modeFilter:
+		b.handleFilter(k)
+		return nil, false
+	}
+	return b.handleBrowse(k)
+}
+
+// handleBrowse handles keys while moving around the board.
+func (b *Board) handleBrowse(k Key) (Op, bool) {
+	b.msg = ""
+	switch k.Code {
+	case KeyLeft:
+		b.col = (b.col + len(columns) - 1) % len(columns)
+	case KeyRight, KeyTab:
+		b.col = (b.col + 1) % len(columns)
+	case KeyUp:
+		b.moveRow(-1)
+	case KeyDown:
+		b.moveRow(1)
+	case KeyPageUp:
+		b.moveRow(-10)
+	case KeyPageDown:
+		b.moveRow(10)
+	case KeyHome:
+		b.rows[b.col] = 0
+	case KeyEnd:
+		b.rows[b.col] = len(b.Column(b.col)) - 1
+		b.clamp()
+	case KeyShiftLeft:
+		return b.shift(-1), false
+	case KeyShiftRight:
+		return b.shift(1), false
+	case KeyEnter:
+		b.startEdit()
+	case KeyDelete:
+		b.confirmDelete()
+	case KeyEsc:
+		b.filter = ""
+		b.clamp()
+	case KeyRune:
+		return b.handleRune(k.Rune)
+	}
+	return nil, false
+}
+
+// handleRune handles letter shortcuts while browsing.
+func (b *Board) handleRune(r rune) (Op, bool) {
+	switch r {
+	case 'q':
+		return nil, true
+	case 'h':
+		return b.handleBrowse(Key{Code: KeyLeft})
+	case 'l':
+		return b.handleBrowse(Key{Code: KeyRight})
+	case 'k':
+		b.moveRow(-1)
+	case 'j':
+		b.moveRow(1)
+	case '<', 'H':
+		return b.shift(-1), false
+	case '>', 'L':
+		return b.shift(1), false
+	case 'e':
+		b.startEdit()
+	case 'a':
+		b.mode = modeAdd
+		b.input.set("")
+	case 'd', 'x':
+		b.confirmDelete()
+	case '/':
+		b.mode = modeFilter
+		b.input.set(b.filter)
+	case '?':
+		b.mode = modeHelp
+	}
+	return nil, false
+}
+
+// moveRow moves the selection in the current column by delta rows.
+func (b *Board) moveRow(delta int) {
+	b.rows[b.col] += delta
+	b.clamp()
+}
+
+// shift moves the selected item one column left (dir -1) or right (+1),
+// changing its status; the selection follows it.
+func (b *Board) shift(dir int) Op {
+	it, ok := b.Selected()
+	to := b.col + dir
+	if !ok || to < 0 || to >= len(columns) {
+		return nil
+	}
+	st := columns[to]
+	b.follow = it.ID
+	b.msg = fmt.Sprintf("#%d is %s", it.ID, st)
+	return func(list []todo.Item) ([]todo.Item, error) { return todo.UpdateStatus(list, it.ID, st) }
+}
+
+func (b *Board) startEdit() {
+	if it, ok := b.Selected(); ok {
+		b.mode = modeEdit
+		b.input.set(it.Description)
+	}
+}
+
+func (b *Board) confirmDelete() {
+	if it, ok := b.Selected(); ok {
+		b.mode = modeConfirm
+		b.msg = fmt.Sprintf("delete #%d %q? (y/n)", it.ID, it.Description)
+	}
+}
+
+// handleInput edits the description being typed; Enter saves, Esc cancels.
+func (b *Board) handleInput(k Key) Op {
+	switch k.Code {
+	case KeyEsc:
+		b.mode = modeBrowse
+		return nil
+	case KeyEnter:
+		desc := strings.TrimSpace(b.input.String())
+		adding := b.mode == modeAdd
+		b.mode = modeBrowse
+		if desc == "" {
+			b.msg = "description cannot be empty"
+			return nil
+		}
+		if adding {
+			b.filter = "" // make sure the new item is visible
+			return func(list []todo.Item) ([]todo.Item, error) {
+				list, added, err := todo.Add(list, desc, todo.StatusNotStarted)
+				if err == nil {
+					b.follow = added.ID
+					b.msg = fmt.Sprintf("added #%d", added.ID)
+				}
+				return list, err
+			}
+		}
+		it, ok := b.Selected()
+		if !ok || it.Description == desc {
+			return nil
+		}
+		b.follow = it.ID
+		b.msg = fmt.Sprintf("#%d updated", it.ID)
+		return func(list []todo.Item) ([]todo.Item, error) { return todo.UpdateDescription(list, it.ID, desc) }
+	}
+	b.input.handle(k)
+	return nil
+}
+
+// handleFilter edits the filter; the board narrows with every key. Enter
+// keeps the filter, Esc clears it.
+func (b *Board) handleFilter(k Key) {
+	id := 0
+	if it, ok := b.Selected(); ok {
+		id = it.ID
+	}
+	switch k.Code {
+	case KeyEnter:
+		b.mode = modeBrowse
+	case KeyEsc:
+		b.mode = modeBrowse
+		b.input.set("")
+	default:
+		b.input.handle(k)
+	}
+	b.filter = b.input.String()
+	if id == 0 || !b.focus(id) {
+		b.clamp()
+	}
+}
+
+// input is a one-line text buffer with a cursor, for inline editing.
+type input struct {
+	buf []rune
+	pos int
+}
+
+func (in *input) set(s string)   { in.buf, in.pos = []rune(s), len([]rune(s)) }
+func (in *input) String() string { return string(in.buf) }
+
+// handle applies an editing key; other keys are ignored.
+func (in *input) handle(k Key) {
+	switch k.Code {
+	case KeyRune:
+		in.buf = append(in.buf[:in.pos], append([]rune{k.Rune}, in.buf[in.pos:]...)...)
+		in.pos++
+	case KeyBackspace:
+		if in.pos > 0 {
+			in.buf = append(in.buf[:in.pos-1], in.buf[in.pos:]...)
+			in.pos--
+		}
+	case KeyDelete:
+		if in.pos < len(in.buf) {
+			in.buf = append(in.buf[:in.pos], in.buf[in.pos+1:]...)
+		}
+	case KeyLeft:
+		if in.pos > 0 {
+			in.pos--
+		}
+	case KeyRight:
+		if in.pos < len(in.buf) {
+			in.pos++
+		}
+	case KeyHome:
+		in.pos = 0
+	case KeyEnd:
+		in.pos = len(in.buf)
+	case KeyCtrlU:
+		in.buf, in.pos = in.buf[in.pos:], 0
+	}
+}
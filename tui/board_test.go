@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"todo-app/todo"
+)
+
+// boardWith returns a board over items a (not started), b (not started),
+// c (started) and the store list it stands for.
+func boardWith(t *testing.T) (*Board, *[]todo.Item) {
+	t.Helper()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	list := []todo.Item{
+		{ID: 1, Description: "Buy milk", Status: todo.StatusNotStarted, CreatedAt: now},
+		{ID: 2, Description: "Write report", Status: todo.StatusNotStarted, CreatedAt: now},
+		{ID: 3, Description: "Fix bike", Status: todo.StatusStarted, CreatedAt: now},
+	}
+	b := NewBoard()
+	b.SetItems(list)
+	return b, &list
+}
+
+// press sends keys to b, applying any change to list and reloading as the
+// run loop does.
+func press(t *testing.T, b *Board, list *[]todo.Item, keys ...Key) (quit bool) {
+	t.Helper()
+	for _, k := range keys {
+		op, q := b.Handle(k)
+		if q {
+			return true
+		}
+		if op != nil {
+			next, err := op(append([]todo.Item(nil), *list...))
+			if err != nil {
+				t.Fatalf("op for %v: %v", k, err)
+			}
+			*list = next
+			b.SetItems(next)
+		}
+	}
+	return false
+}
+
+func runes(s string) []Key {
+	var ks []Key
+	for _, r := range s {
+		ks = append(ks, Key{Code: KeyRune, Rune: r})
+	}
+	return ks
+}
+
+func key(c KeyCode) Key { return Key{Code: c} }
+
+// TestTUI_Board_MoveBetweenStatuses moves items across columns with
+// Shift+arrows and </>, and checks the selection follows the item.
+func TestTUI_Board_MoveBetweenStatuses(t *testing.T) {
+	b, list := boardWith(t)
+	press(t, b, list, key(KeyDown), key(KeyShiftRight))
+	if it, _ := b.Selected(); it.ID != 2 || it.Status != todo.StatusStarted || b.col != 1 {
+		t.Fatalf("after Shift+Right: col=%d selected=%+v", b.col, it)
+	}
+	press(t, b, list, runes(">")...)
+	if (*list)[1].Status != todo.StatusCompleted || b.col != 2 {
+		t.Fatalf("after >: %+v col=%d", (*list)[1], b.col)
+	}
+	// The last column has nowhere further right to go.
+	if op, _ := b.Handle(key(KeyShiftRight)); op != nil {
+		t.Fatalf("Shift+Right in the last column returned an op")
+	}
+	press(t, b, list, key(KeyShiftLeft), key(KeyShiftLeft))
+	if (*list)[1].Status != todo.StatusNotStarted || b.col != 0 {
+		t.Fatalf("after two Shift+Left: %+v col=%d", (*list)[1], b.col)
+	}
+	// Plain arrows only move the selection.
+	press(t, b, list, key(KeyRight), key(KeyDown), key(KeyDown))
+	if it, _ := b.Selected(); it.ID != 3 {
+		t.Fatalf("Right should select the Started column, got %+v", it)
+	}
+}
+
+// TestTUI_Board_EditAddDelete covers inline editing, adding and deleting
+// with confirmation.
+func TestTUI_Board_EditAddDelete(t *testing.T) {
+	b, list := boardWith(t)
+
+	press(t, b, list, key(KeyEnter), key(KeyCtrlU))
+	press(t, b, list, runes("Oat milk")...)
+	press(t, b, list, key(KeyHome))
+	press(t, b, list, runes("Buy ")...)
+	press(t, b, list, key(KeyEnter))
+	if (*list)[0].Description != "Buy Oat milk" || b.mode != modeBrowse {
+		t.Fatalf("after edit: %+v", (*list)[0])
+	}
+	press(t, b, list, runes("e")...)
+	press(t, b, list, runes("!!!")...)
+	press(t, b, list, key(KeyEsc))
+	if (*list)[0].Description != "Buy Oat milk" {
+		t.Fatalf("Esc should cancel the edit: %+v", (*list)[0])
+	}
+
+	press(t, b, list, key(KeyRight))
+	press(t, b, list, runes("aNew thing")...)
+	press(t, b, list, key(KeyEnter))
+	if len(*list) != 4 || (*list)[3].Description != "New thing" {
+		t.Fatalf("after add: %+v", *list)
+	}
+	if it, _ := b.Selected(); it.ID != 4 || b.col != 0 {
+		t.Fatalf("new item should be selected, got col=%d %+v", b.col, it)
+	}
+	press(t, b, list, runes("a")...)
+	press(t, b, list, key(KeyEnter))
+	if len(*list) != 4 || b.msg == "" {
+		t.Fatalf("an empty description should be refused with a message")
+	}
+
+	press(t, b, list, runes("dn")...)
+	if len(*list) != 4 {
+		t.Fatalf("n should cancel the delete")
+	}
+	press(t, b, list, runes("dy")...)
+	if len(*list) != 3 {
+		t.Fatalf("y should delete: %+v", *list)
+	}
+	if it, ok := b.Selected(); !ok || it.ID == 4 {
+		t.Fatalf("selection after delete = %+v, %v", it, ok)
+	}
+}
+
+// TestTUI_Board_FilterHelpQuit covers filter-as-you-type, the help overlay
+// and quitting.
+func TestTUI_Board_FilterHelpQuit(t *testing.T) {
+	b, list := boardWith(t)
+	press(t, b, list, runes("/rep")...)
+	if n := len(b.Column(0)); n != 1 || b.filter != "rep" {
+		t.Fatalf("filter while typing: %d items, filter %q", n, b.filter)
+	}
+	if it, _ := b.Selected(); it.ID != 2 {
+		t.Fatalf("selection should move to the match, got %+v", it)
+	}
+	press(t, b, list, key(KeyEnter))
+	if b.mode != modeBrowse || b.filter != "rep" {
+		t.Fatalf("Enter should keep the filter")
+	}
+	press(t, b, list, runes("/")...)
+	press(t, b, list, key(KeyBackspace), key(KeyBackspace), key(KeyBackspace))
+	press(t, b, list, runes("#3")...)
+	if len(b.Column(0)) != 0 || len(b.Column(1)) != 1 {
+		t.Fatalf("#id filter should match item 3 only")
+	}
+	press(t, b, list, key(KeyEsc))
+	if b.filter != "" || len(b.Column(0)) != 2 {
+		t.Fatalf("Esc should clear the filter")
+	}
+
+	press(t, b, list, runes("?")...)
+	if b.mode != modeHelp {
+		t.Fatalf("? should open help")
+	}
+	if press(t, b, list, runes("q")...) || b.mode != modeBrowse {
+		t.Fatalf("a key in help should only close it")
+	}
+	if !press(t, b, list, runes("q")...) {
+		t.Fatalf("q should quit")
+	}
+	if !press(t, b, list, key(KeyCtrlC)) {
+		t.Fatalf("Ctrl+C should quit")
+	}
+}
+
+// TestTUI_Board_KeepsSelectionOnReload keeps the selected item across
+// outside changes that move it.
+func TestTUI_Board_KeepsSelectionOnReload(t *testing.T) {
+	b, list := boardWith(t)
+	press(t, b, list, key(KeyDown))
+	next := append([]todo.Item{{ID: 9, Description: "Inserted", Status: todo.StatusNotStarted}}, *list...)
+	b.SetItems(next)
+	if it, _ := b.Selected(); it.ID != 2 {
+		t.Fatalf("selection after reload = %+v, want #2", it)
+	}
+	b.SetItems(next[:1])
+	if it, ok := b.Selected(); !ok || it.ID != 9 {
+		t.Fatalf("selection after #2 vanished = %+v, %v", it, ok)
+	}
+}
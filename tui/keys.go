@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"bufio"
+	"unicode/utf8"
+)
+
+//
+// tui/keys.go (package tui)
+// -------------------------
+// Decodes raw terminal input into keys. Terminals send a special key as one
+// escape sequence in a single write, so an ESC with nothing buffered after
+// it is the Esc key itself rather than the start of a sequence.
+//
+
+// KeyCode identifies a key; printable input is KeyRune with Key.Rune set.
+type KeyCode int
+
+const (
+	KeyUnknown KeyCode = iota
+	KeyRune
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyDelete
+	KeyTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyShiftLeft
+	KeyShiftRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyCtrlC
+	KeyCtrlU
+)
+
+// Key is one decoded key press.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// readKey reads the next key from r.
+func readKey(r *bufio.Reader) (Key, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return Key{}, err
+	}
+	switch c {
+	case '\r', '\n':
+		return Key{Code: KeyEnter}, nil
+	case '\t':
+		return Key{Code: KeyTab}, nil
+	case 0x7f, 0x08:
+		return Key{Code: KeyBackspace}, nil
+	case 0x01:
+		return Key{Code: KeyHome}, nil
+	case 0x03:
+		return Key{Code: KeyCtrlC}, nil
+	case 0x05:
+		return Key{Code: KeyEnd}, nil
+	case 0x15:
+		return Key{Code: KeyCtrlU}, nil
+	case 0x1b:
+		if r.Buffered() == 0 {
+			return Key{Code: KeyEsc}, nil
+		}
+		return readEscape(r)
+	}
+	if c < ' ' {
+		return Key{Code: KeyUnknown}, nil
+	}
+	if c < utf8.RuneSelf {
+		return Key{Code: KeyRune, Rune: rune(c)}, nil
+	}
+	if err := r.UnreadByte(); err != nil {
+		return Key{}, err
+	}
+	ch, _, err := r.ReadRune()
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{Code: KeyRune, Rune: ch}, nil
+}
+
+// csiFinal maps the final byte of "ESC [ ..." and "ESC O ..." sequences.
+var csiFinal = map[byte]KeyCode{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft, 'H': KeyHome, 'F': KeyEnd,
+}
+
+// tildeKeys maps "ESC [ n ~" sequences.
+var tildeKeys = map[string]KeyCode{
+	"1": KeyHome, "7": KeyHome, "4": KeyEnd, "8": KeyEnd,
+	"3": KeyDelete, "5": KeyPageUp, "6": KeyPageDown,
+}
+
+// readEscape decodes the rest of an escape sequence after ESC.
+func readEscape(r *bufio.Reader) (Key, error) {
+	intro, err := r.ReadByte()
+	if err != nil {
+		return Key{}, err
+	}
+	if intro != '[' && intro != 'O' {
+		return Key{Code: KeyUnknown}, nil // Alt+key
+	}
+	var params []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return Key{}, err
+		}
+		if b >= 0x40 && b <= 0x7e { // final byte
+			if b == '~' {
+				return Key{Code: tildeKeys[string(params)]}, nil
+			}
+			code := csiFinal[b]
+			// "1;2C" is Shift+Right; other modifiers read as the plain key.
+			if string(params) == "1;2" {
+				switch code {
+				case KeyLeft:
+					code = KeyShiftLeft
+				case KeyRight:
+					code = KeyShiftRight
+				}
+			}
+			return Key{Code: code}, nil
+		}
+		params = append(params, b)
+	}
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestTUI_ReadKey decodes control bytes, UTF-8 and escape sequences.
+func TestTUI_ReadKey(t *testing.T) {
+	in := "a\r\x7f\x03é" +
+		"\x1b[A\x1b[B\x1b[C\x1b[D\x1bOH\x1b[F" +
+		"\x1b[1;2C\x1b[1;2D\x1b[1;5C\x1b[3~\x1b[5~\x1b[6~\x1b[7~\x1b[4~\x1bx"
+	want := []Key{
+		{Code: KeyRune, Rune: 'a'}, {Code: KeyEnter}, {Code: KeyBackspace}, {Code: KeyCtrlC}, {Code: KeyRune, Rune: 'é'},
+		{Code: KeyUp}, {Code: KeyDown}, {Code: KeyRight}, {Code: KeyLeft}, {Code: KeyHome}, {Code: KeyEnd},
+		{Code: KeyShiftRight}, {Code: KeyShiftLeft}, {Code: KeyRight}, {Code: KeyDelete}, {Code: KeyPageUp}, {Code: KeyPageDown},
+		{Code: KeyHome}, {Code: KeyEnd}, {Code: KeyUnknown},
+	}
+	br := bufio.NewReader(strings.NewReader(in))
+	var got []Key
+	for {
+		k, err := readKey(br)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("readKey: %v", err)
+		}
+		got = append(got, k)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys:\n got %v\nwant %v", got, want)
+	}
+
+	// A lone ESC (nothing buffered after it) is the Esc key.
+	r, w := io.Pipe()
+	go func() { _, _ = w.Write([]byte{0x1b}); _, _ = w.Write([]byte("q")); _ = w.Close() }()
+	br = bufio.NewReader(r)
+	if k, _ := readKey(br); k.Code != KeyEsc {
+		t.Fatalf("lone ESC = %v, want KeyEsc", k)
+	}
+	if k, _ := readKey(br); k.Rune != 'q' {
+		t.Fatalf("after ESC = %v, want q", k)
+	}
+}
@@ -0,0 +1,266 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//
+// tui/render.go (package tui)
+// ---------------------------
+// Draws the board as a full screen of text: a title bar, three status
+// columns, a detail/input line and a key hint bar. Every line is cut or
+// padded to the terminal width, so a frame overwrites the previous one in
+// place without clearing the screen (no flicker). Designed for 80x24 and
+// up; characters are assumed to be one cell wide.
+//
+
+// Minimum size the board can be drawn at.
+const (
+	minWidth  = 40
+	minHeight = 10
+)
+
+// ANSI styles.
+const (
+	styleReverse = "\x1b[7m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReset   = "\x1b[0m"
+)
+
+const hints = "←→↑↓ move  ⇧←→ status  enter edit  a add  d del  / filter  ? help  q quit"
+
+var helpLines = []string{
+	"Keys",
+	"",
+	"←/→ h/l, ↑/↓ k/j    select column / item",
+	"Home/End PgUp/PgDn  jump within a column",
+	"Shift+←/→  < >  H L move the item to the previous/next status",
+	"Enter or e          edit the description inline",
+	"                    (Enter saves, Esc cancels)",
+	"a                   add an item to Not started",
+	"d, x or Delete      delete the item (asks y/n)",
+	"/                   filter as you type (Enter keeps, Esc clears)",
+	"Esc                 clear the filter",
+	"?                   this help",
+	"q or Ctrl+C         quit",
+	"",
+	"The board reloads when the data file changes.",
+	"Press any key to close.",
+}
+
+// frame is one rendered screen: its lines and where to put the cursor
+// (row < 0 hides it).
+type frame struct {
+	lines    []string
+	row, col int
+}
+
+// cell cuts or pads s to exactly w cells, marking a cut with "…".
+func cell(s string, w int) string {
+	if w <= 0 {
+		return ""
+	}
+	n := utf8.RuneCountInString(s)
+	if n > w {
+		r := []rune(s)
+		return string(r[:w-1]) + "…"
+	}
+	return s + strings.Repeat(" ", w-n)
+}
+
+// render draws the board at width x height.
+func (b *Board) render(width, height int) frame {
+	f := frame{row: -1}
+	if width < minWidth || height < minHeight {
+		f.lines = []string{cell(fmt.Sprintf("terminal too small (%dx%d); need %dx%d", width, height, minWidth, minHeight), width)}
+		for len(f.lines) < height {
+			f.lines = append(f.lines, cell("", width))
+		}
+		return f
+	}
+
+	// Title bar.
+	title := " todo board"
+	right := fmt.Sprintf("%d items ", len(b.items))
+	if b.filter != "" || b.mode == modeFilter {
+		right = fmt.Sprintf("filter: %s ", b.filter)
+	}
+	pad := width - utf8.RuneCountInString(title) - utf8.RuneCountInString(right)
+	if pad < 1 {
+		pad = 1
+	}
+	f.lines = append(f.lines, styleReverse+cell(title+strings.Repeat(" ", pad)+right, width)+styleReset)
+
+	// Column geometry: two one-cell separators; the last column takes the rest.
+	cw := (width - 2) / len(columns)
+	widths := []int{cw, cw, width - 2 - 2*cw}
+	cols := make([][]string, len(columns))
+	heads := make([]string, len(columns))
+	bodyH := height - 5 // title, heads, rule, detail, hints
+
+	for c := range columns {
+		items := b.Column(c)
+		head := cell(fmt.Sprintf(" %s (%d)", columnTitles[c], len(items)), widths[c])
+		if c == b.col {
+			head = styleBold + head + styleReset
+		}
+		heads[c] = head
+
+		rows := make([]string, 0, bodyH)
+		sel := b.rows[c]
+		n := len(items)
+		if b.mode == modeAdd && c == 0 {
+			n++ // the new item is typed on an extra row at the end
+			sel = n - 1
+		}
+		top := 0
+		if sel >= bodyH {
+			top = sel - bodyH + 1
+		}
+		for r := top; r < n && len(rows) < bodyH; r++ {
+			selected := c == b.col && r == b.rows[c] && b.mode != modeAdd
+			if r == len(items) { // add row
+				text, cur := b.inputCell(" + ", widths[c])
+				rows = append(rows, text)
+				f.row, f.col = 3+len(rows)-1, colStart(widths, c)+cur
+				continue
+			}
+			it := items[r]
+			label := fmt.Sprintf(" #%d ", it.ID)
+			switch {
+			case selected && b.mode == modeEdit:
+				text, cur := b.inputCell(label, widths[c])
+				rows = append(rows, styleReverse+text+styleReset)
+				f.row, f.col = 3+len(rows)-1, colStart(widths, c)+cur
+			case selected:
+				rows = append(rows, styleReverse+cell(label+it.Description, widths[c])+styleReset)
+			default:
+				rows = append(rows, cell(label+it.Description, widths[c]))
+			}
+		}
+		if n == 0 && b.filter != "" {
+			rows = append(rows, styleDim+cell("  (no matches)", widths[c])+styleReset)
+		}
+		for len(rows) < bodyH {
+			rows = append(rows, cell("", widths[c]))
+		}
+		cols[c] = rows
+	}
+	f.lines = append(f.lines, strings.Join(heads, "│"))
+	var rule []string
+	for _, w := range widths {
+		rule = append(rule, strings.Repeat("─", w))
+	}
+	f.lines = append(f.lines, strings.Join(rule, "┼"))
+	for r := 0; r < bodyH; r++ {
+		f.lines = append(f.lines, cols[0][r]+"│"+cols[1][r]+"│"+cols[2][r])
+	}
+	if b.mode == modeHelp {
+		b.overlayHelp(f.lines[3:3+bodyH], width)
+	}
+
+	// Detail / prompt line.
+	var detail string
+	switch {
+	case b.mode == modeFilter:
+		detail = " filter: " + b.input.String()
+		f.row, f.col = height-2, utf8.RuneCountInString(detail)-len(b.input.buf)+b.input.pos
+	case b.mode == modeEdit:
+		detail = " editing – Enter saves, Esc cancels"
+	case b.mode == modeAdd:
+		detail = " new item – Enter adds, Esc cancels"
+	case b.msg != "":
+		detail = " " + b.msg
+	default:
+		if it, ok := b.Selected(); ok {
+			detail = fmt.Sprintf(" #%d %s · %s · created %s", it.ID, it.Description, it.Status, it.CreatedAt.Local().Format(time.DateTime))
+		}
+	}
+	f.lines = append(f.lines, cell(detail, width))
+	// The last cell of the screen is left alone so the terminal never scrolls.
+	f.lines = append(f.lines, styleReverse+cell(" "+hints, width-1)+styleReset)
+	return f
+}
+
+// colStart is the screen column where column c begins.
+func colStart(widths []int, c int) int {
+	x := 0
+	for i := 0; i < c; i++ {
+		x += widths[i] + 1
+	}
+	return x
+}
+
+// inputCell draws label followed by the input buffer in w cells, scrolled
+// so the cursor is visible. It returns the text and the cursor offset.
+func (b *Board) inputCell(label string, w int) (string, int) {
+	room := w - utf8.RuneCountInString(label) - 1
+	if room < 1 {
+		room = 1
+	}
+	start := 0
+	if b.input.pos > room {
+		start = b.input.pos - room
+	}
+	end := start + room
+	if end > len(b.input.buf) {
+		end = len(b.input.buf)
+	}
+	text := label + string(b.input.buf[start:end])
+	return cell(text, w), utf8.RuneCountInString(label) + b.input.pos - start
+}
+
+// overlayHelp draws the help box centred over the body lines.
+func (b *Board) overlayHelp(body []string, width int) {
+	boxW := 0
+	for _, l := range helpLines {
+		if n := utf8.RuneCountInString(l); n > boxW {
+			boxW = n
+		}
+	}
+	boxW += 4
+	if boxW > width {
+		boxW = width
+	}
+	boxH := len(helpLines) + 2
+	top := (len(body) - boxH) / 2
+	if top < 0 {
+		top = 0
+	}
+	left := (width - boxW) / 2
+	margin := strings.Repeat(" ", left)
+	for i := 0; i < boxH && top+i < len(body); i++ {
+		var line string
+		switch i {
+		case 0:
+			line = "┌" + strings.Repeat("─", boxW-2) + "┐"
+		case boxH - 1:
+			line = "└" + strings.Repeat("─", boxW-2) + "┘"
+		default:
+			line = "│ " + cell(helpLines[i-1], boxW-4) + " │"
+		}
+		body[top+i] = cell(margin+line, width)
+	}
+}
+
+// write draws f at the top-left of the screen and places the cursor.
+func (f frame) write(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("\x1b[?25l\x1b[H")
+	for i, l := range f.lines {
+		if i > 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(l)
+	}
+	if f.row >= 0 {
+		fmt.Fprintf(&sb, "\x1b[%d;%dH\x1b[?25h", f.row+1, f.col+1)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
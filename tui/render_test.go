@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var ansi = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+
+// plain returns f's lines without styling.
+func plain(f frame) []string {
+	out := make([]string, len(f.lines))
+	for i, l := range f.lines {
+		out[i] = ansi.ReplaceAllString(l, "")
+	}
+	return out
+}
+
+// TestTUI_Render_80x24 checks that every frame fills an 80x24 screen
+// exactly (the last line one short, so the terminal never scrolls) in each
+// mode, with long descriptions cut to their column.
+func TestTUI_Render_80x24(t *testing.T) {
+	b, list := boardWith(t)
+	press(t, b, list, runes("aA description that is far too long to fit in one column of the board")...)
+	press(t, b, list, key(KeyEnter))
+	steps := map[string][]Key{
+		"browse": nil,
+		"edit":   {key(KeyEnter)},
+		"add":    runes("a"),
+		"filter": runes("/milk"),
+		"help":   runes("?"),
+		"delete": runes("d"),
+	}
+	for name, keys := range steps {
+		b, list := boardWith(t)
+		press(t, b, list, runes("aA description that is far too long to fit in one column of the board")...)
+		press(t, b, list, key(KeyEnter))
+		press(t, b, list, keys...)
+		lines := plain(b.render(80, 24))
+		if len(lines) != 24 {
+			t.Fatalf("%s: %d lines", name, len(lines))
+		}
+		for i, l := range lines {
+			want := 80
+			if i == 23 {
+				want = 79
+			}
+			if n := utf8.RuneCountInString(l); n != want {
+				t.Fatalf("%s: line %d is %d cells, want %d: %q", name, i, n, want, l)
+			}
+		}
+	}
+
+	if !strings.Contains(plain(b.render(80, 24))[22], "added #4") {
+		t.Fatalf("the add should be confirmed in the footer")
+	}
+	press(t, b, list, key(KeyUp), key(KeyDown)) // clears the message
+	lines := plain(b.render(80, 24))
+	if !strings.HasPrefix(lines[1], " Not started (3)") || !strings.Contains(lines[1], "│ Started (1)") {
+		t.Fatalf("column heads: %q", lines[1])
+	}
+	if !strings.Contains(lines[3], " #1 Buy milk") || !strings.Contains(lines[3], " #3 Fix bike") {
+		t.Fatalf("first row: %q", lines[3])
+	}
+	if !strings.Contains(lines[5], " #4 A description that is…") {
+		t.Fatalf("long description not cut: %q", lines[5])
+	}
+	if !strings.Contains(lines[22], "#4 A description that is far too long") {
+		t.Fatalf("detail line should show the selected item in full: %q", lines[22])
+	}
+}
+
+// TestTUI_Render_CursorAndHelp places the cursor while editing and shows
+// the help box.
+func TestTUI_Render_CursorAndHelp(t *testing.T) {
+	b, list := boardWith(t)
+	if f := b.render(80, 24); f.row >= 0 {
+		t.Fatalf("cursor shown while browsing")
+	}
+	press(t, b, list, key(KeyRight), key(KeyEnter), key(KeyLeft))
+	f := b.render(80, 24)
+	// Column 2 starts at 27; " #3 " is 4 cells; the cursor is before the last rune.
+	if f.row != 3 || f.col != 27+4+len("Fix bike")-1 {
+		t.Fatalf("edit cursor at %d,%d", f.row, f.col)
+	}
+	press(t, b, list, key(KeyEsc))
+
+	press(t, b, list, runes("?")...)
+	screen := strings.Join(plain(b.render(80, 24)), "\n")
+	if !strings.Contains(screen, "filter as you type") || !strings.Contains(screen, "┌") {
+		t.Fatalf("help overlay missing:\n%s", screen)
+	}
+
+	small := plain(b.render(30, 8))
+	if len(small) != 8 || !strings.Contains(small[0], "too small") {
+		t.Fatalf("small terminal: %q", small)
+	}
+}
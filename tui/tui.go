@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"todo-app/service"
+	"todo-app/term"
+)
+
+//
+// tui/tui.go (package tui)
+// ------------------------
+// The full-screen loop: raw mode plus the terminal's alternate screen, keys
+// read on a separate goroutine, and a ticker that polls the data file and
+// the window size. When the file's modification time or size changes (an
+// edit from the CLI, the API or another board) the items are reloaded, so
+// the store must read from disk on Load (service.FileStore does; an
+// ActorStore only sees its own writes).
+//
+
+// pollInterval is how often the data file and window size are checked.
+const pollInterval = 500 * time.Millisecond
+
+// Run shows the board for store until the user quits or ctx is done. path
+// is the store's data file, watched for outside changes. in must be a
+// terminal.
+func Run(ctx context.Context, store service.Store, path string, in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	restore, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("the board needs a terminal: %w", err)
+	}
+	defer restore()
+	// Alternate screen, so the shell's scrollback is back intact on exit.
+	fmt.Fprint(out, "\x1b[?1049h\x1b[2J")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan Key)
+	readErr := make(chan error, 1)
+	go func() {
+		// Blocks in Read until the next key; it is left behind on return,
+		// which is fine because the process exits after the board.
+		br := bufio.NewReader(in)
+		for {
+			k, err := readKey(br)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case keys <- k:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	size := func() (int, int) {
+		w, h, err := term.Size(fd)
+		if err != nil || w <= 0 || h <= 0 {
+			return 80, 24
+		}
+		return w, h
+	}
+	err = loop(ctx, store, path, keys, ticker.C, size, out)
+	select {
+	case rerr := <-readErr:
+		if err == nil && !errors.Is(rerr, io.EOF) {
+			err = rerr
+		}
+	default:
+	}
+	return err
+}
+
+// fileStamp identifies a version of the data file.
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+func stat(path string) fileStamp {
+	st, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{mod: st.ModTime(), size: st.Size()}
+}
+
+// loop redraws after every key, applies the board's changes to store, and
+// reloads when the file at path changes. It returns when the user quits,
+// ctx is done or keys is closed.
+func loop(ctx context.Context, store service.Store, path string, keys <-chan Key, tick <-chan time.Time, size func() (int, int), out io.Writer) error {
+	b := NewBoard()
+	stamp := stat(path)
+	reload := func() {
+		items, err := store.Load(ctx)
+		if err != nil {
+			b.msg = "reload failed: " + err.Error()
+			return
+		}
+		b.SetItems(items)
+	}
+	reload()
+	w, h := size()
+	redraw := true
+
+	for {
+		if redraw {
+			if err := b.render(w, h).write(out); err != nil {
+				return err
+			}
+		}
+		redraw = true
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			op, quit := b.Handle(k)
+			if quit {
+				return nil
+			}
+			if op != nil {
+				if err := service.Update(ctx, store, op); err != nil {
+					b.follow = 0
+					b.msg = "error: " + err.Error()
+				}
+				stamp = stat(path)
+				reload()
+			}
+		case <-tick:
+			redraw = false
+			if nw, nh := size(); nw != w || nh != h {
+				w, h = nw, nh
+				fmt.Fprint(out, "\x1b[2J")
+				redraw = true
+			}
+			if s := stat(path); s != stamp {
+				stamp = s
+				reload()
+				redraw = true
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while the loop writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+// TestTUI_Loop_AppliesKeysAndReloads drives the loop with keys and a fake
+// ticker: changes reach the file, and a write from another process shows
+// up after the next tick.
+func TestTUI_Loop_AppliesKeysAndReloads(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{})))
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "todos.json")
+	store := service.NewFileStore(path)
+	if err := store.Save(ctx, []todo.Item{{ID: 1, Description: "Buy milk", Status: todo.StatusNotStarted}}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	keys := make(chan Key)
+	tick := make(chan time.Time)
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- loop(ctx, store, path, keys, tick, func() (int, int) { return 80, 24 }, out)
+	}()
+
+	keys <- Key{Code: KeyShiftRight}
+	keys <- Key{Code: KeyRune, Rune: 'a'} // a sync point: the shift has been applied
+	keys <- Key{Code: KeyEsc}
+	list, _ := todo.Load(ctx, path)
+	if len(list) != 1 || list[0].Status != todo.StatusStarted {
+		t.Fatalf("after Shift+Right: %+v", list)
+	}
+
+	// Another process adds an item; make sure the stamp differs even on
+	// filesystems with coarse timestamps.
+	other := service.NewFileStore(path)
+	list = append(list, todo.Item{ID: 2, Description: "Outside edit", Status: todo.StatusCompleted})
+	if err := other.Save(ctx, list); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	_ = os.Chtimes(path, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	tick <- time.Now()
+	keys <- Key{Code: KeyRight} // sync point after the tick
+	if s := out.String(); !strings.Contains(s, "Outside edit") || !strings.Contains(s, "Completed (1)") {
+		t.Fatalf("outside change not shown:\n%q", s[len(s)-min(len(s), 400):])
+	}
+
+	keys <- Key{Code: KeyRune, Rune: 'q'}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("loop: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("loop did not stop on q")
+	}
+}
+
+// TestTUI_Run_NeedsTerminal refuses to start on a pipe.
+func TestTUI_Run_NeedsTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	err = Run(context.Background(), service.NewFileStore(filepath.Join(t.TempDir(), "x.json")), "", r, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "terminal") {
+		t.Fatalf("Run on a pipe = %v", err)
+	}
+}
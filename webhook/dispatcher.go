@@ -0,0 +1,417 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"todo-app/service"
+	"todo-app/trace"
+)
+
+// Config tunes a Dispatcher. Zero values pick the defaults noted below.
+type Config struct {
+	HooksPath   string        // subscriptions, written 0600 because they hold secrets
+	QueuePath   string        // pending deliveries plus the recent delivery log
+	Client      *http.Client  // default: 10s timeout, refusing private addresses unless AllowPrivate
+	MaxAttempts int           // default 8
+	BaseBackoff time.Duration // default 2s
+	MaxBackoff  time.Duration // default 10m
+	KeepDone    int           // finished deliveries kept for the log, default 500
+	// AllowPrivate lets hooks target loopback, private and link-local
+	// addresses, e.g. a receiver on the same host; see ErrPrivateTarget.
+	AllowPrivate bool
+}
+
+// Dispatcher owns the hooks and the delivery queue. The store factory hands
+// it every committed change before the write is acknowledged, so deliveries
+// are queued on disk even when a batch produces thousands of events; they are
+// sent in the background once Start has been called.
+type Dispatcher struct {
+	cfg Config
+
+	mu    sync.Mutex
+	hooks []Hook
+	queue []Delivery
+
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewDispatcher loads hooks and queued deliveries from disk. Unreadable files
+// are logged and treated as empty, like the other stores.
+func NewDispatcher(cfg Config) *Dispatcher {
+	if cfg.Client == nil {
+		dialer := &net.Dialer{Timeout: 10 * time.Second}
+		if !cfg.AllowPrivate {
+			dialer.Control = dialControl
+		}
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.DialContext = dialer.DialContext
+		// The dial check must see the receiver's address, not a proxy's.
+		tr.Proxy = nil
+		cfg.Client = &http.Client{Timeout: 10 * time.Second, Transport: tr}
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 2 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Minute
+	}
+	if cfg.KeepDone <= 0 {
+		cfg.KeepDone = 500
+	}
+	d := &Dispatcher{cfg: cfg, wake: make(chan struct{}, 1)}
+	ctx := context.Background()
+	if err := loadJSON(ctx, cfg.HooksPath, &d.hooks); err != nil {
+		slog.Warn("webhooks: initial load failed; starting empty", "error", err, "path", cfg.HooksPath)
+		d.hooks = nil
+	}
+	if err := loadJSON(ctx, cfg.QueuePath, &d.queue); err != nil {
+		slog.Warn("webhooks: queue load failed; starting empty", "error", err, "path", cfg.QueuePath)
+		d.queue = nil
+	}
+	return d
+}
+
+// Start begins delivering queued events and, when stores is a
+// service.CommitNotifier, queuing its commits. Delivery stops when ctx is
+// cancelled (Wait blocks until it has); commits are still queued after that
+// and sent by the next Start.
+func (d *Dispatcher) Start(ctx context.Context, stores service.StoreFactory) {
+	if n, ok := stores.(service.CommitNotifier); ok {
+		n.OnCommit(d.committed)
+	} else {
+		slog.WarnContext(ctx, "webhooks: store does not report commits; no events will be queued")
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.run(ctx)
+	}()
+}
+
+// Wait blocks until the goroutines started by Start have exited.
+func (d *Dispatcher) Wait() { d.wg.Wait() }
+
+// Create stores a new hook. Its URL must pass CheckTarget unless
+// AllowPrivate is set.
+func (d *Dispatcher) Create(ctx context.Context, h Hook) error {
+	if !d.cfg.AllowPrivate {
+		if err := CheckTarget(h.URL); err != nil {
+			return err
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	next := append(append([]Hook(nil), d.hooks...), h)
+	if err := saveJSON(ctx, d.cfg.HooksPath, next, 0o600); err != nil {
+		return err
+	}
+	d.hooks = next
+	slog.InfoContext(ctx, "webhook created", "id", h.ID, "user", h.User, "url", h.URL)
+	return nil
+}
+
+// List returns user's hooks without their secrets.
+func (d *Dispatcher) List(user string) []Hook {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := []Hook{}
+	for _, h := range d.hooks {
+		if h.User == user {
+			out = append(out, h.Redacted())
+		}
+	}
+	return out
+}
+
+// Delete removes one of user's hooks and drops its pending deliveries.
+func (d *Dispatcher) Delete(ctx context.Context, user, id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	next := make([]Hook, 0, len(d.hooks))
+	found := false
+	for _, h := range d.hooks {
+		if h.ID == id && h.User == user {
+			found = true
+			continue
+		}
+		next = append(next, h)
+	}
+	if !found {
+		return ErrNotFound
+	}
+	if err := saveJSON(ctx, d.cfg.HooksPath, next, 0o600); err != nil {
+		return err
+	}
+	d.hooks = next
+	queue := d.queue[:0:0]
+	for _, del := range d.queue {
+		if del.HookID == id && del.Status == StatusPending {
+			continue
+		}
+		queue = append(queue, del)
+	}
+	d.queue = queue
+	if err := saveJSON(ctx, d.cfg.QueuePath, d.queue, 0o644); err != nil {
+		slog.ErrorContext(ctx, "webhooks: failed to save queue", "error", err)
+	}
+	slog.InfoContext(ctx, "webhook deleted", "id", id, "user", user)
+	return nil
+}
+
+// Deliveries returns user's delivery log, newest first. A non-empty hookID
+// restricts it to that hook.
+func (d *Dispatcher) Deliveries(user, hookID string) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := []Delivery{}
+	for i := len(d.queue) - 1; i >= 0; i-- {
+		del := d.queue[i]
+		if del.User == user && (hookID == "" || del.HookID == hookID) {
+			out = append(out, del)
+		}
+	}
+	return out
+}
+
+// payload is the JSON body POSTed to receivers.
+type payload struct {
+	service.Event
+	User   string `json:"user"`
+	HookID string `json:"hook_id"`
+}
+
+// Enqueue queues events for every hook of user that wants them and saves
+// the queue once.
+func (d *Dispatcher) Enqueue(ctx context.Context, user string, events ...service.Event) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	now := time.Now()
+	for _, ev := range events {
+		for _, h := range d.hooks {
+			if h.User != user || !h.Matches(ev.Type) {
+				continue
+			}
+			body, err := json.Marshal(payload{Event: ev, User: user, HookID: h.ID})
+			if err != nil {
+				return err
+			}
+			id, err := randomHex(8)
+			if err != nil {
+				return err
+			}
+			d.queue = append(d.queue, Delivery{
+				ID: "dl_" + id, HookID: h.ID, User: user,
+				EventID: ev.ID, EventType: string(ev.Type), Body: string(body),
+				Status: StatusPending, NextAttempt: now, CreatedAt: now,
+			})
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	if err := saveJSON(ctx, d.cfg.QueuePath, d.queue, 0o644); err != nil {
+		return err
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// committed is the service.CommitFunc that queues a store's changes. The
+// change is already on disk, so a queue that cannot be saved is only logged;
+// its deliveries stay queued in memory and are saved with the next change.
+func (d *Dispatcher) committed(ctx context.Context, user string, events []service.Event) {
+	if err := d.Enqueue(ctx, user, events...); err != nil {
+		slog.ErrorContext(ctx, "webhooks: enqueue failed", "error", err, "user", user, "events", len(events))
+	}
+}
+
+// run delivers due deliveries until ctx is cancelled.
+func (d *Dispatcher) run(ctx context.Context) {
+	for {
+		d.deliverDue(ctx)
+		timer := time.NewTimer(d.nextWait())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-d.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// nextWait is the time until the earliest pending delivery is due.
+func (d *Dispatcher) nextWait() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	wait := time.Minute
+	now := time.Now()
+	for _, del := range d.queue {
+		if del.Status == StatusPending {
+			if w := del.NextAttempt.Sub(now); w < wait {
+				wait = w
+			}
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// deliverDue attempts every pending delivery whose time has come, one at a time.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	d.mu.Lock()
+	now := time.Now()
+	var due []Delivery
+	for _, del := range d.queue {
+		if del.Status == StatusPending && !del.NextAttempt.After(now) {
+			due = append(due, del)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, del := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		hook, ok := d.hook(del.HookID)
+		var code int
+		var err error
+		if ok {
+			code, err = d.attempt(ctx, hook, del)
+		} else {
+			err = ErrNotFound
+		}
+		if err != nil && ctx.Err() != nil {
+			return // shutting down; retry after restart without counting this attempt
+		}
+		d.finish(ctx, del.ID, code, err, !ok)
+	}
+}
+
+func (d *Dispatcher) hook(id string) (Hook, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, h := range d.hooks {
+		if h.ID == id {
+			return h, true
+		}
+	}
+	return Hook{}, false
+}
+
+// attempt POSTs one delivery and returns the receiver's status code.
+func (d *Dispatcher) attempt(ctx context.Context, h Hook, del Delivery) (int, error) {
+	ctx, span := trace.Start(ctx, "webhook.deliver", "webhook.id", h.ID, "webhook.delivery_id", del.ID)
+	defer span.End()
+
+	body := []byte(del.Body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, strings.NewReader(del.Body))
+	if err != nil {
+		span.SetError(err)
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-app-webhooks/1")
+	req.Header.Set("X-Todo-Event", del.EventType)
+	req.Header.Set("X-Todo-Delivery", del.ID)
+	req.Header.Set("X-Todo-Timestamp", fmt.Sprint(now.Unix()))
+	req.Header.Set("X-Todo-Signature", Sign(h.Secret, now, body))
+
+	resp, err := d.cfg.Client.Do(req)
+	if err != nil {
+		span.SetError(err)
+		return 0, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	span.SetAttr("http.response.status_code", resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = fmt.Errorf("receiver answered %s", resp.Status)
+		span.SetError(err)
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
+
+// finish records the outcome of an attempt and schedules the next one.
+func (d *Dispatcher) finish(ctx context.Context, id string, code int, err error, giveUp bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range d.queue {
+		del := &d.queue[i]
+		if del.ID != id || del.Status != StatusPending {
+			continue
+		}
+		now := time.Now()
+		del.Attempts++
+		del.LastStatus = code
+		del.LastError = ""
+		switch {
+		case err == nil:
+			del.Status = StatusDelivered
+			del.DoneAt = &now
+		case giveUp || del.Attempts >= d.cfg.MaxAttempts:
+			del.Status = StatusFailed
+			del.LastError = err.Error()
+			del.DoneAt = &now
+			slog.WarnContext(ctx, "webhook delivery failed permanently", "delivery", id, "hook", del.HookID, "attempts", del.Attempts, "error", err)
+		default:
+			del.LastError = err.Error()
+			del.NextAttempt = now.Add(Backoff(del.Attempts, d.cfg.BaseBackoff, d.cfg.MaxBackoff))
+			slog.InfoContext(ctx, "webhook delivery will retry", "delivery", id, "hook", del.HookID, "attempts", del.Attempts, "next", del.NextAttempt, "error", err)
+		}
+		break
+	}
+	d.trimLocked()
+	if err := saveJSON(ctx, d.cfg.QueuePath, d.queue, 0o644); err != nil {
+		slog.ErrorContext(ctx, "webhooks: failed to save queue", "error", err)
+	}
+}
+
+// trimLocked drops the oldest finished deliveries beyond KeepDone.
+func (d *Dispatcher) trimLocked() {
+	var done []int
+	for i, del := range d.queue {
+		if del.Status != StatusPending {
+			done = append(done, i)
+		}
+	}
+	extra := len(done) - d.cfg.KeepDone
+	if extra <= 0 {
+		return
+	}
+	drop := map[int]bool{}
+	for _, i := range done[:extra] {
+		drop[i] = true
+	}
+	kept := d.queue[:0]
+	for i, del := range d.queue {
+		if !drop[i] {
+			kept = append(kept, del)
+		}
+	}
+	d.queue = kept
+}
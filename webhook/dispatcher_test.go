@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"todo-app/service"
+	"todo-app/todo"
+)
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func testConfig(dir string) Config {
+	return Config{
+		HooksPath:   filepath.Join(dir, "webhooks.json"),
+		QueuePath:   filepath.Join(dir, "webhook_queue.json"),
+		MaxAttempts: 3,
+		BaseBackoff: 10 * time.Millisecond,
+		MaxBackoff:  50 * time.Millisecond,
+		// The receivers are httptest servers on 127.0.0.1.
+		AllowPrivate: true,
+	}
+}
+
+// TestWebhook_Dispatcher_DeliversWithRetries drives a real store change
+// through to a receiver that fails twice, and checks signing and the log.
+func TestWebhook_Dispatcher_DeliversWithRetries(t *testing.T) {
+	dir := t.TempDir()
+	var calls atomic.Int32
+	var mu sync.Mutex
+	var got payload
+	var secret string
+	recv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		ok := Verify(secret, r.Header.Get("X-Todo-Timestamp"), r.Header.Get("X-Todo-Signature"), body)
+		mu.Unlock()
+		if !ok {
+			t.Errorf("bad signature on attempt %d", calls.Load()+1)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		mu.Lock()
+		_ = json.Unmarshal(body, &got)
+		mu.Unlock()
+	}))
+	defer recv.Close()
+
+	stores := service.NewActorStoreFactory(filepath.Join(dir, "todos.json"))
+	defer stores.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	d := NewDispatcher(testConfig(dir))
+	d.Start(ctx, stores)
+	defer func() { cancel(); d.Wait() }()
+
+	h, err := NewHook("alice", recv.URL, []string{"created"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	secret = h.Secret
+	mu.Unlock()
+	if err := d.Create(ctx, h); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	st, _ := stores.For(ctx, "alice")
+	items, _, _ := todo.Add(nil, "ship it", todo.StatusNotStarted)
+	if err := st.Save(ctx, items); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, "delivery", func() bool {
+		log := d.Deliveries("alice", h.ID)
+		return len(log) == 1 && log[0].Status == StatusDelivered
+	})
+	del := d.Deliveries("alice", h.ID)[0]
+	if del.Attempts != 3 || del.LastStatus != http.StatusOK {
+		t.Fatalf("delivery = %+v", del)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got.Type != service.EventCreated || got.Item.Description != "ship it" || got.User != "alice" {
+		t.Fatalf("payload = %+v", got)
+	}
+	if len(d.Deliveries("bob", "")) != 0 {
+		t.Fatalf("another user's log is visible")
+	}
+}
+
+// TestWebhook_Dispatcher_QueuesEveryCommit saves a batch larger than the
+// change stream's buffers (64 per subscriber, 256 for replay) and checks that
+// every event is queued before the Save returns and then delivered.
+func TestWebhook_Dispatcher_QueuesEveryCommit(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	seen := map[uint64]bool{}
+	recv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p payload
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		mu.Lock()
+		seen[p.ID] = true
+		mu.Unlock()
+	}))
+	defer recv.Close()
+
+	stores := service.NewActorStoreFactory(filepath.Join(dir, "todos.json"))
+	defer stores.Close()
+	cfg := testConfig(dir)
+	cfg.KeepDone = 2000
+	d := NewDispatcher(cfg)
+	ctx := context.Background()
+	h, _ := NewHook("alice", recv.URL, nil, "")
+	if err := d.Create(ctx, h); err != nil {
+		t.Fatal(err)
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	d.Start(runCtx, stores)
+	defer func() { cancel(); d.Wait() }()
+
+	const n = 300
+	st, _ := stores.For(ctx, "alice")
+	var items []todo.Item
+	for i := 0; i < n; i++ {
+		items, _, _ = todo.Add(items, fmt.Sprintf("task %d", i), todo.StatusNotStarted)
+	}
+	if err := st.Save(ctx, items); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(d.Deliveries("alice", h.ID)); got != n {
+		t.Fatalf("queued %d deliveries when the save returned, want %d", got, n)
+	}
+	// Every delivery rewrites the queue file, so allow more than waitFor does.
+	for deadline := time.Now().Add(30 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		mu.Lock()
+		got := len(seen)
+		mu.Unlock()
+		if got == n {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("receiver got %d of %d events", got, n)
+		}
+	}
+}
+
+// TestWebhook_Dispatcher_QueueSurvivesRestart checks that pending deliveries
+// are persisted, resumed by a new dispatcher, and eventually marked failed.
+func TestWebhook_Dispatcher_QueueSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	var calls atomic.Int32
+	recv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer recv.Close()
+
+	ctx := context.Background()
+	first := NewDispatcher(testConfig(dir))
+	h, _ := NewHook("alice", recv.URL, nil, "k")
+	if err := first.Create(ctx, h); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Enqueue(ctx, "alice", service.Event{ID: 7, Type: service.EventDeleted}); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("delivered before Start")
+	}
+
+	// A fresh dispatcher picks the queued delivery up from disk.
+	second := NewDispatcher(testConfig(dir))
+	runCtx, cancel := context.WithCancel(ctx)
+	second.Start(runCtx, service.SharedStore(nil))
+	defer func() { cancel(); second.Wait() }()
+
+	waitFor(t, "give up", func() bool {
+		log := second.Deliveries("alice", "")
+		return len(log) == 1 && log[0].Status == StatusFailed
+	})
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("receiver called %d times, want MaxAttempts=3", n)
+	}
+	if log := second.Deliveries("alice", ""); log[0].EventID != 7 || log[0].LastError == "" {
+		t.Fatalf("log entry = %+v", log[0])
+	}
+}
+
+// TestWebhook_Dispatcher_DeleteDropsPending ensures deleting a hook stops its
+// queued deliveries and that hooks are scoped to their owner.
+func TestWebhook_Dispatcher_DeleteDropsPending(t *testing.T) {
+	ctx := context.Background()
+	d := NewDispatcher(testConfig(t.TempDir()))
+	h, _ := NewHook("alice", "http://127.0.0.1:1/", nil, "")
+	_ = d.Create(ctx, h)
+	_ = d.Enqueue(ctx, "alice", service.Event{ID: 1, Type: service.EventCreated})
+
+	if err := d.Delete(ctx, "bob", h.ID); err != ErrNotFound {
+		t.Fatalf("bob deleted alice's hook: %v", err)
+	}
+	if err := d.Delete(ctx, "alice", h.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if len(d.List("alice")) != 0 || len(d.Deliveries("alice", "")) != 0 {
+		t.Fatalf("hook or pending delivery left behind")
+	}
+}
+
+// TestWebhook_Dispatcher_RefusesPrivateTargets checks both guards: Create
+// rejects private URLs, and delivery refuses a private address even for a
+// hook that got past Create (e.g. a name later rebound to 127.0.0.1).
+func TestWebhook_Dispatcher_RefusesPrivateTargets(t *testing.T) {
+	var calls atomic.Int32
+	recv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer recv.Close()
+
+	ctx := context.Background()
+	cfg := testConfig(t.TempDir())
+	cfg.AllowPrivate = false
+	d := NewDispatcher(cfg)
+	h, _ := NewHook("alice", recv.URL, nil, "")
+	if err := d.Create(ctx, h); !errors.Is(err, ErrPrivateTarget) {
+		t.Fatalf("Create(%s) = %v, want ErrPrivateTarget", recv.URL, err)
+	}
+
+	d.mu.Lock()
+	d.hooks = append(d.hooks, h)
+	d.mu.Unlock()
+	if err := d.Enqueue(ctx, "alice", service.Event{ID: 1, Type: service.EventCreated}); err != nil {
+		t.Fatal(err)
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	d.Start(runCtx, service.SharedStore(nil))
+	defer func() { cancel(); d.Wait() }()
+	waitFor(t, "give up", func() bool {
+		log := d.Deliveries("alice", "")
+		return len(log) == 1 && log[0].Status == StatusFailed
+	})
+	if log := d.Deliveries("alice", ""); calls.Load() != 0 || !strings.Contains(log[0].LastError, "private") {
+		t.Fatalf("receiver called %d times; log entry = %+v", calls.Load(), log[0])
+	}
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+//
+// webhook/storage.go (package webhook)
+// ------------------------------------
+// JSON persistence for hooks and the delivery queue, mirroring
+// lists/storage.go. Files are replaced via rename so a crash mid-write never
+// leaves a truncated queue behind.
+//
+
+// loadJSON reads path into v; a missing or empty file leaves v untouched.
+func loadJSON(ctx context.Context, path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		slog.ErrorContext(ctx, "failed to read file", "error", err, "path", path)
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal JSON", "error", err, "path", path)
+		return err
+	}
+	return nil
+}
+
+// saveJSON writes v to path as pretty-printed JSON with mode perm.
+func saveJSON(ctx context.Context, path string, v any, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		slog.ErrorContext(ctx, "failed to create output directory", "error", err, "path", path)
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		slog.ErrorContext(ctx, "failed to save file", "error", err, "path", path)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		slog.ErrorContext(ctx, "failed to save file", "error", err, "path", path)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"todo-app/service"
+)
+
+//
+// webhook/webhook.go (package webhook)
+// ------------------------------------
+// Outbound webhooks. A Hook subscribes one user's item changes to a URL; every
+// matching change becomes a Delivery in a persistent queue which the
+// Dispatcher POSTs with retries and exponential backoff. Each request carries
+//
+//	X-Todo-Event:     created|updated|deleted
+//	X-Todo-Delivery:  delivery id (stable across retries)
+//	X-Todo-Timestamp: unix seconds of this attempt
+//	X-Todo-Signature: sha256=<hex HMAC-SHA256(secret, timestamp + "." + body)>
+//
+// so receivers can verify the sender and reject replays. Hooks may not target
+// loopback, private or link-local addresses (cloud metadata services live at
+// 169.254.169.254) unless Config.AllowPrivate is set: the URL is checked when
+// the hook is created and every resolved address again when dialling.
+//
+
+// ErrNotFound is returned for unknown hooks (or hooks owned by someone else).
+var ErrNotFound = errors.New("webhook not found")
+
+// ErrPrivateTarget is returned for hook URLs on loopback, private,
+// link-local or metadata addresses unless Config.AllowPrivate is set.
+var ErrPrivateTarget = errors.New("webhook url must not point to a loopback, private or link-local address")
+
+// Status is the state of a delivery.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusDelivered Status = "delivered"
+	StatusFailed    Status = "failed" // gave up after MaxAttempts
+)
+
+// Hook is one webhook subscription.
+type Hook struct {
+	ID        string    `json:"id"`
+	User      string    `json:"user"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"` // empty means every event type
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches reports whether the hook wants events of type t.
+func (h Hook) Matches(t service.EventType) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == string(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redacted returns h without its secret, for listings.
+func (h Hook) Redacted() Hook {
+	h.Secret = ""
+	return h
+}
+
+// Delivery is one event queued for one hook.
+type Delivery struct {
+	ID          string     `json:"id"`
+	HookID      string     `json:"hook_id"`
+	User        string     `json:"user"`
+	EventID     uint64     `json:"event_id"`
+	EventType   string     `json:"event_type"`
+	Body        string     `json:"body"`
+	Status      Status     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	NextAttempt time.Time  `json:"next_attempt"`
+	LastStatus  int        `json:"last_status,omitempty"` // HTTP status of the last attempt
+	LastError   string     `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DoneAt      *time.Time `json:"done_at,omitempty"`
+}
+
+// NewHook validates the subscription and fills in id, secret and timestamps.
+// A random secret is generated when secret is empty.
+func NewHook(user, rawURL string, events []string, secret string) (Hook, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Hook{}, fmt.Errorf("webhook url must be an absolute http(s) URL, got %q", rawURL)
+	}
+	var evs []string
+	for _, e := range events {
+		switch t := service.EventType(strings.TrimSpace(e)); t {
+		case service.EventCreated, service.EventUpdated, service.EventDeleted:
+			evs = append(evs, string(t))
+		default:
+			return Hook{}, fmt.Errorf("unknown event type %q (use created, updated or deleted)", e)
+		}
+	}
+	if secret == "" {
+		if secret, err = randomHex(32); err != nil {
+			return Hook{}, err
+		}
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return Hook{}, err
+	}
+	return Hook{ID: "wh_" + id, User: user, URL: u.String(), Events: evs, Secret: secret, CreatedAt: time.Now()}, nil
+}
+
+// blockedPrefixes are ranges hooks may not reach beyond those privateAddr
+// tests for by kind.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+}
+
+// privateAddr reports whether a is loopback, private, link-local (which
+// includes the 169.254.169.254 metadata service), unspecified or multicast.
+func privateAddr(a netip.Addr) bool {
+	a = a.Unmap()
+	if a.IsLoopback() || a.IsPrivate() || a.IsLinkLocalUnicast() || a.IsLinkLocalMulticast() ||
+		a.IsInterfaceLocalMulticast() || a.IsMulticast() || a.IsUnspecified() {
+		return true
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTarget rejects a hook URL whose host is a private address or a
+// localhost name. Other names are not resolved here: what they resolve to
+// can change, so the delivery client checks each address it dials.
+func CheckTarget(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrPrivateTarget, host)
+	}
+	if a, err := netip.ParseAddr(host); err == nil && privateAddr(a) {
+		return fmt.Errorf("%w: %s", ErrPrivateTarget, host)
+	}
+	return nil
+}
+
+// dialControl is a net.Dialer Control function that refuses private
+// addresses. It sees the address after DNS resolution, so it also covers
+// names that resolve (or are rebound) to one, and redirects.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	a, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if privateAddr(a) {
+		return fmt.Errorf("%w: %s", ErrPrivateTarget, host)
+	}
+	return nil
+}
+
+// Sign returns the X-Todo-Signature value for body sent at ts.
+func Sign(secret string, ts time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature produced by Sign; receivers written in Go can use
+// it directly. timestamp is the X-Todo-Timestamp header value.
+func Verify(secret, timestamp, signature string, body []byte) bool {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	want := Sign(secret, time.Unix(sec, 0), body)
+	return hmac.Equal([]byte(want), []byte(signature))
+}
+
+// Backoff returns the wait before retry number attempt (1-based):
+// base, 2*base, 4*base, ... capped at max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
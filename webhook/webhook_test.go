@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"todo-app/service"
+)
+
+// TestWebhook_SignVerify checks signatures round-trip and bind the timestamp.
+func TestWebhook_SignVerify(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"created"}`)
+	sig := Sign("s3cret", ts, body)
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+	if !Verify("s3cret", stamp, sig, body) {
+		t.Fatalf("valid signature rejected")
+	}
+	if Verify("other", stamp, sig, body) || Verify("s3cret", "1700000001", sig, body) || Verify("s3cret", stamp, sig, []byte("{}")) {
+		t.Fatalf("tampered signature accepted")
+	}
+}
+
+// TestWebhook_Backoff verifies exponential growth and the cap.
+func TestWebhook_Backoff(t *testing.T) {
+	base, max := time.Second, 5*time.Second
+	want := []time.Duration{1, 2, 4, 5, 5}
+	for i, w := range want {
+		if got := Backoff(i+1, base, max); got != w*time.Second {
+			t.Fatalf("Backoff(%d)=%v want %v", i+1, got, w*time.Second)
+		}
+	}
+}
+
+// TestWebhook_NewHook validates urls and event types and generates secrets.
+func TestWebhook_NewHook(t *testing.T) {
+	if _, err := NewHook("u", "ftp://x", nil, ""); err == nil {
+		t.Fatalf("non-http url accepted")
+	}
+	if _, err := NewHook("u", "https://example.com", []string{"exploded"}, ""); err == nil {
+		t.Fatalf("unknown event accepted")
+	}
+	h, err := NewHook("u", "https://example.com/hook", []string{"deleted"}, "")
+	if err != nil {
+		t.Fatalf("NewHook: %v", err)
+	}
+	if len(h.Secret) != 64 || h.ID == "" {
+		t.Fatalf("hook not filled in: %+v", h)
+	}
+	if !h.Matches(service.EventDeleted) || h.Matches(service.EventCreated) {
+		t.Fatalf("event filter wrong: %+v", h.Events)
+	}
+	if h.Redacted().Secret != "" {
+		t.Fatalf("Redacted kept the secret")
+	}
+}
+
+// TestWebhook_CheckTarget rejects loopback, private, link-local and metadata
+// hosts, including IPv4-mapped IPv6 forms, and leaves names to dial time.
+func TestWebhook_CheckTarget(t *testing.T) {
+	for _, u := range []string{
+		"http://127.0.0.1:8080/", "http://localhost/hook", "http://api.localhost./",
+		"http://10.1.2.3/", "http://172.16.0.1/", "http://192.168.1.1/",
+		"http://169.254.169.254/latest/meta-data/", "http://[::1]/", "http://[fd00:ec2::254]/",
+		"http://[::ffff:127.0.0.1]/", "http://0.0.0.0/", "http://100.100.100.200/",
+	} {
+		if err := CheckTarget(u); !errors.Is(err, ErrPrivateTarget) {
+			t.Errorf("CheckTarget(%q) = %v, want ErrPrivateTarget", u, err)
+		}
+	}
+	for _, u := range []string{"https://example.com/hook", "http://93.184.215.14/", "https://[2606:4700::1111]/"} {
+		if err := CheckTarget(u); err != nil {
+			t.Errorf("CheckTarget(%q) = %v", u, err)
+		}
+	}
+}